@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf/parsers/toml/v2"
 	"github.com/knadh/koanf/providers/env/v2"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/fs"
 	"github.com/knadh/koanf/v2"
+
+	"github.com/hoppermq/hopper/internal/ratelimit"
 )
 
 const (
@@ -128,7 +131,104 @@ type Configuration struct {
 		AdminPort   uint16 `koanf:"admin_port"`
 		MetricsPort uint16 `koanf:"metrics_port"`
 		HealthPort  uint16 `koanf:"health_port"`
+
+		MaxConnections      int `koanf:"max_connections"`
+		MaxConnectionsPerIP int `koanf:"max_connections_per_ip"`
 	} `koanf:"server"`
+
+	Transport struct {
+		TCP struct {
+			KeepAlive       bool          `koanf:"keep_alive"`
+			KeepAlivePeriod time.Duration `koanf:"keep_alive_period"`
+			NoDelay         bool          `koanf:"no_delay"`
+			LingerTimeout   time.Duration `koanf:"linger_timeout"`
+			ReadBufferSize  int           `koanf:"read_buffer_size"`
+			WriteBufferSize int           `koanf:"write_buffer_size"`
+			MaxMessageSize  int           `koanf:"max_message_size"`
+			Listeners       []Listener    `koanf:"listeners"`
+		} `koanf:"tcp"`
+
+		WebSocket struct {
+			Enabled        bool     `koanf:"enabled"`
+			Address        string   `koanf:"address"`
+			Path           string   `koanf:"path"`
+			AllowedOrigins []string `koanf:"allowed_origins"`
+		} `koanf:"websocket"`
+
+		MQTT struct {
+			Enabled        bool          `koanf:"enabled"`
+			Address        string        `koanf:"address"`
+			TLS            bool          `koanf:"tls"`
+			MaxPacketSize  int           `koanf:"max_packet_size"`
+			ConnectTimeout time.Duration `koanf:"connect_timeout"`
+		} `koanf:"mqtt"`
+
+		AMQP struct {
+			Enabled        bool          `koanf:"enabled"`
+			Address        string        `koanf:"address"`
+			TLS            bool          `koanf:"tls"`
+			MaxFrameSize   uint32        `koanf:"max_frame_size"`
+			MaxMessageSize int           `koanf:"max_message_size"`
+			IdleTimeout    time.Duration `koanf:"idle_timeout"`
+			ConnectTimeout time.Duration `koanf:"connect_timeout"`
+		} `koanf:"amqp"`
+	} `koanf:"transport"`
+
+	Gateway struct {
+		Enabled     bool          `koanf:"enabled"`
+		MaxBodySize int64         `koanf:"max_body_size"`
+		MaxPullWait time.Duration `koanf:"max_pull_wait"`
+		AckTimeout  time.Duration `koanf:"ack_timeout"`
+		MaxPending  int           `koanf:"max_pending"`
+
+		StreamKeepAlive  time.Duration `koanf:"stream_keep_alive"`
+		RetainedMessages int           `koanf:"retained_messages"`
+	} `koanf:"gateway"`
+
+	API struct {
+		// EnableClientManagement serve the management routes listing the topics, clients and containers.
+		EnableClientManagement bool `koanf:"enable_client_management"`
+	} `koanf:"api"`
+
+	Clients struct {
+		EnableAuth      bool   `koanf:"enable_auth"`
+		MaxAuthAttempts int    `koanf:"max_auth_attempts"`
+		UsersFile       string `koanf:"users_file"`
+
+		MaxMessagesPerSecond float64                    `koanf:"max_messages_per_second"`
+		MaxBytesPerSecond    float64                    `koanf:"max_bytes_per_second"`
+		BurstLimit           int                        `koanf:"burst_limit"`
+		RateLimitMode        string                     `koanf:"rate_limit_mode"`
+		RateLimitOverrides   []ratelimit.IdentityLimits `koanf:"rate_limit_overrides"`
+		TopicRateLimits      []ratelimit.TopicLimits    `koanf:"topic_rate_limits"`
+	} `koanf:"clients"`
+
+	Security struct {
+		TLSEnabled         bool          `koanf:"tls_enabled"`
+		CertFile           string        `koanf:"cert_file"`
+		KeyFile            string        `koanf:"key_file"`
+		CAFile             string        `koanf:"ca_file"`
+		MinTLSVersion      string        `koanf:"min_tls_version"`
+		ClientAuth         string        `koanf:"client_auth"`
+		CertReloadInterval time.Duration `koanf:"cert_reload_interval"`
+		EnableACL          bool          `koanf:"enable_acl"`
+		DefaultPermissions string        `koanf:"default_permissions"`
+		ACLFile            string        `koanf:"acl_file"`
+		AuditLogFile       string        `koanf:"audit_log_file"`
+		EnableIPWhitelist  bool          `koanf:"enable_ip_whitelist"`
+		AllowedIPs         []string      `koanf:"allowed_ips"`
+		BlockedIPs         []string      `koanf:"blocked_ips"`
+	} `koanf:"security"`
+}
+
+// Listener represent an address the broker accept connections on.
+type Listener struct {
+	// Network is tcp or unix.
+	Network string `koanf:"network"`
+	// Address is a host:port for tcp and a socket path for unix.
+	Address string `koanf:"address"`
+	// TLS terminate TLS on the listener with the [security] certificates.
+	TLS bool `koanf:"tls"`
 }
 
 // New create a new configuration from files and env.
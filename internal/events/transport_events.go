@@ -2,6 +2,8 @@
 package events
 
 import (
+	"time"
+
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
@@ -18,6 +20,7 @@ func (evt *BaseEvent) GetType() domain.EventType {
 // NewConnectionEvent represent the event for a new connection.
 type NewConnectionEvent struct {
 	Conn      domain.Connection
+	Identity  string // subject of the client certificate, empty without mutual TLS.
 	Transport domain.TransportType
 
 	BaseEvent
@@ -50,6 +53,7 @@ func (evt *ClientDisconnectEvent) GetTransport() domain.TransportType {
 
 // MessageReceivedEvent represent a new message received event.
 type MessageReceivedEvent struct {
+	Conn      domain.Connection
 	Message   []byte
 	Transport domain.TransportType
 
@@ -66,6 +70,44 @@ func (evt *MessageReceivedEvent) GetTransport() domain.TransportType {
 	return evt.Transport
 }
 
+// InvalidFrameEvent represent a frame rejected by the transport before reaching the broker.
+type InvalidFrameEvent struct {
+	Conn      domain.Connection
+	Err       error
+	Transport domain.TransportType
+
+	BaseEvent
+}
+
+// GetType return the eventType.
+func (evt *InvalidFrameEvent) GetType() domain.EventType {
+	return evt.EventType
+}
+
+// GetTransport return the transport used.
+func (evt *InvalidFrameEvent) GetTransport() domain.TransportType {
+	return evt.Transport
+}
+
+// ThrottleConnectionEvent ask the transport to pause the reads of a connection.
+type ThrottleConnectionEvent struct {
+	Conn      domain.Connection
+	Delay     time.Duration
+	Transport domain.TransportType
+
+	BaseEvent
+}
+
+// GetType return the eventType.
+func (evt *ThrottleConnectionEvent) GetType() domain.EventType {
+	return evt.EventType
+}
+
+// GetTransport return the transport used.
+func (evt *ThrottleConnectionEvent) GetTransport() domain.TransportType {
+	return evt.Transport
+}
+
 // SendMessageEvent represent a new message sent evet.
 type SendMessageEvent struct {
 	ClientID  domain.ID
@@ -73,6 +115,9 @@ type SendMessageEvent struct {
 	Message   []byte
 	Transport domain.TransportType
 
+	// OnSent is called by the transport once the message has been written, or failed to.
+	OnSent func(err error)
+
 	BaseEvent
 }
 
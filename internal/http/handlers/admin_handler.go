@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// TopicsHandler list the topic patterns the containers are subscribed to.
+func TopicsHandler(inspector domain.BrokerInspector) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"topics": inspector.Topics()})
+	}
+}
+
+// ClientsHandler list the clients connected to the broker.
+func ClientsHandler(inspector domain.BrokerInspector) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"clients": inspector.Clients()})
+	}
+}
+
+// ContainersHandler list the containers of the broker.
+func ContainersHandler(inspector domain.BrokerInspector) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"containers": inspector.Containers()})
+	}
+}
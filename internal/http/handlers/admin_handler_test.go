@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestAdminHandlers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		handler  func(inspector domain.BrokerInspector) gin.HandlerFunc
+		setup    func(inspector *mocks.MockBrokerInspector)
+		wantBody string
+	}{
+		{
+			name:    "Topics",
+			handler: TopicsHandler,
+			setup: func(inspector *mocks.MockBrokerInspector) {
+				inspector.EXPECT().Topics().Return([]domain.TopicInfo{{Topic: "orders.*", Subscribers: 2}})
+			},
+			wantBody: `{"topics":[{"topic":"orders.*","subscribers":2}]}`,
+		},
+		{
+			name:    "Clients",
+			handler: ClientsHandler,
+			setup: func(inspector *mocks.MockBrokerInspector) {
+				inspector.EXPECT().Clients().Return([]domain.ClientInfo{{ID: "c1", ContainerID: "ctr1", Transport: "tcp"}})
+			},
+			wantBody: `{"clients":[{"id":"c1","container_id":"ctr1","transport":"tcp"}]}`,
+		},
+		{
+			name:    "Containers",
+			handler: ContainersHandler,
+			setup: func(inspector *mocks.MockBrokerInspector) {
+				inspector.EXPECT().Containers().Return([]domain.ContainerInfo{{
+					ID:              "ctr1",
+					ClientID:        "c1",
+					State:           domain.ContainerConnected,
+					ProtocolVersion: domain.ProtocolVersion1,
+					Capabilities:    "confirms",
+					Topics:          []string{"orders.*"},
+				}})
+			},
+			wantBody: `{"containers":[{"id":"ctr1","client_id":"c1","state":"CLIENT_CON","protocol_version":1,` +
+				`"capabilities":"confirms","topics":["orders.*"]}]}`,
+		},
+		{
+			name:    "Empty",
+			handler: TopicsHandler,
+			setup: func(inspector *mocks.MockBrokerInspector) {
+				inspector.EXPECT().Topics().Return([]domain.TopicInfo{})
+			},
+			wantBody: `{"topics":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inspector := mocks.NewMockBrokerInspector(t)
+			tt.setup(inspector)
+
+			rec := serve(tt.handler(inspector), http.MethodGet, "/admin", "/admin", "", nil)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package handlers represent the http handlers.
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoppermq/hopper/internal/http/middlewares"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// HeaderPrefix mark the request headers copied into the message headers, without the prefix.
+const HeaderPrefix = "Hopper-"
+
+const (
+	defaultPullMax = 10
+	maxPullMax     = 1000
+)
+
+// PublishHandler publish the request body on the topic of the path.
+// The Content-Type header and the headers prefixed with HeaderPrefix are copied into the message headers.
+func PublishHandler(gateway domain.MessageGateway, maxBodySize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body := ctx.Request.Body
+		if maxBodySize > 0 {
+			body = http.MaxBytesReader(ctx.Writer, body, maxBodySize)
+		}
+
+		content, err := io.ReadAll(body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrFrameTooLarge.Error()})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		messageID, err := gateway.Publish(
+			ctx.Request.Context(),
+			middlewares.Identity(ctx),
+			ctx.Param("topic"),
+			content,
+			MessageHeaders(ctx.Request.Header),
+		)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"message_id": messageID})
+	}
+}
+
+// CreateSubscriptionHandler bind the subscription of the path to the topic of the body.
+func CreateSubscriptionHandler(gateway domain.MessageGateway) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req struct {
+			Topic string `json:"topic" binding:"required"`
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := ctx.Param("name")
+		if err := gateway.CreateSubscription(middlewares.Identity(ctx), name, req.Topic); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"name": name, "topic": req.Topic})
+	}
+}
+
+// DeleteSubscriptionHandler remove the subscription of the path.
+func DeleteSubscriptionHandler(gateway domain.MessageGateway) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := gateway.DeleteSubscription(middlewares.Identity(ctx), ctx.Param("name")); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// PullHandler return the messages of the subscription of the path.
+// The max query parameter bound the number of messages and wait, a Go duration capped by maxWait,
+// long-poll until the first message is available.
+func PullHandler(gateway domain.MessageGateway, maxWait time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limit := defaultPullMax
+		if raw := ctx.Query("max"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid max: " + raw})
+				return
+			}
+			limit = min(n, maxPullMax)
+		}
+
+		var wait time.Duration
+		if raw := ctx.Query("wait"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid wait: " + raw})
+				return
+			}
+			wait = min(d, maxWait)
+		}
+
+		deliveries, err := gateway.Pull(ctx.Request.Context(), middlewares.Identity(ctx), ctx.Param("name"), limit, wait)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		if deliveries == nil {
+			deliveries = []domain.Delivery{}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"messages": deliveries})
+	}
+}
+
+// AckHandler settle the deliveries of the body on the subscription of the path.
+func AckHandler(gateway domain.MessageGateway) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req struct {
+			DeliveryIDs []domain.ID `json:"delivery_ids" binding:"required"`
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := gateway.Ack(middlewares.Identity(ctx), ctx.Param("name"), req.DeliveryIDs); err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// MessageHeaders extract the message headers from the request headers, the keys are lower cased.
+func MessageHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for key, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(key, "Content-Type"):
+			headers["content-type"] = values[0]
+		case len(key) > len(HeaderPrefix) && strings.EqualFold(key[:len(HeaderPrefix)], HeaderPrefix):
+			headers[strings.ToLower(key[len(HeaderPrefix):])] = strings.Join(values, ",")
+		}
+	}
+
+	return headers
+}
+
+// errorStatuses map the gateway errors to their http status.
+var errorStatuses = []struct {
+	err    error
+	status int
+}{
+	{domain.ErrUnauthorized, http.StatusUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden},
+	{domain.ErrSubscriptionNotFound, http.StatusNotFound},
+	{domain.ErrUnknownDelivery, http.StatusNotFound},
+	{domain.ErrSubscriptionConflict, http.StatusConflict},
+	{domain.ErrRateLimited, http.StatusTooManyRequests},
+	{domain.ErrFrameTooLarge, http.StatusRequestEntityTooLarge},
+	{domain.ErrInvalidPayload, http.StatusBadRequest},
+}
+
+func abortWithError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	for _, entry := range errorStatuses {
+		if errors.Is(err, entry.err) {
+			status = entry.status
+			break
+		}
+	}
+
+	ctx.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
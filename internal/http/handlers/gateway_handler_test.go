@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func serve(handler gin.HandlerFunc, method, route, target, body string, header http.Header) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.Handle(method, route, handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestPublishHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		maxBodySize int64
+		setup       func(gateway *mocks.MockMessageGateway)
+		wantStatus  int
+	}{
+		{
+			name: "Publish_With_Mapped_Headers",
+			body: `{"id":1}`,
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Publish(mock.Anything, "", "orders.created", []byte(`{"id":1}`), map[string]string{
+						"content-type":   "application/json",
+						"correlation-id": "abc",
+					}).
+					Return("m1", nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "Forbidden_Topic",
+			body: "x",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Publish(mock.Anything, "", "orders.created", mock.Anything, mock.Anything).
+					Return("", domain.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Rate_Limited",
+			body: "x",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Publish(mock.Anything, "", "orders.created", mock.Anything, mock.Anything).
+					Return("", domain.ErrRateLimited)
+			},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:        "Body_Too_Large",
+			body:        "0123456789",
+			maxBodySize: 4,
+			setup:       func(*mocks.MockMessageGateway) {},
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gateway := mocks.NewMockMessageGateway(t)
+			tt.setup(gateway)
+
+			rec := serve(
+				PublishHandler(gateway, tt.maxBodySize),
+				http.MethodPost, "/topics/:topic/messages", "/topics/orders.created/messages",
+				tt.body,
+				http.Header{
+					"Content-Type":          {"application/json"},
+					"Hopper-Correlation-Id": {"abc"},
+					"User-Agent":            {"curl"},
+				},
+			)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestPullHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		setup      func(gateway *mocks.MockMessageGateway)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "Long_Poll_Capped",
+			target: "/subscriptions/billing/messages?max=5&wait=1m",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Pull(mock.Anything, "", "billing", 5, 10*time.Second).
+					Return([]domain.Delivery{{ID: "d1", MessageID: "m1", Topic: "orders", Content: []byte("hi"), Attempt: 1}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"messages":[{"delivery_id":"d1","message_id":"m1","topic":"orders","content":"aGk=","attempt":1}]}`,
+		},
+		{
+			name:   "Empty",
+			target: "/subscriptions/billing/messages",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().Pull(mock.Anything, "", "billing", defaultPullMax, time.Duration(0)).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"messages":[]}`,
+		},
+		{
+			name:   "Unknown_Subscription",
+			target: "/subscriptions/billing/messages",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Pull(mock.Anything, "", "billing", mock.Anything, mock.Anything).
+					Return(nil, domain.ErrSubscriptionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid_Wait",
+			target:     "/subscriptions/billing/messages?wait=soon",
+			setup:      func(*mocks.MockMessageGateway) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gateway := mocks.NewMockMessageGateway(t)
+			tt.setup(gateway)
+
+			rec := serve(
+				PullHandler(gateway, 10*time.Second),
+				http.MethodGet, "/subscriptions/:name/messages", tt.target, "", nil,
+			)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAckHandler(t *testing.T) {
+	t.Parallel()
+
+	gateway := mocks.NewMockMessageGateway(t)
+	gateway.EXPECT().Ack("", "billing", []domain.ID{"d1", "d2"}).Return(nil)
+
+	rec := serve(
+		AckHandler(gateway),
+		http.MethodPost, "/subscriptions/:name/ack", "/subscriptions/billing/ack",
+		`{"delivery_ids":["d1","d2"]}`, http.Header{"Content-Type": {"application/json"}},
+	)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoppermq/hopper/internal/http/middlewares"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// LastEventIDHeader is the header sent by the event sources when they reconnect.
+const LastEventIDHeader = "Last-Event-ID"
+
+// streamEvent is the data of a message event.
+type streamEvent struct {
+	MessageID domain.ID         `json:"message_id"`
+	Topic     string            `json:"topic"`
+	Content   []byte            `json:"content"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// StreamHandler stream the messages published on the topic query parameters as Server-Sent Events.
+// The event id is the message offset when a message log is registered, the stream then resume
+// after the Last-Event-ID header, or the last_event_id query parameter, on reconnection.
+// A comment is sent every keepAlive to keep the idle connections open.
+func StreamHandler(gateway domain.MessageGateway, keepAlive time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		topics := ctx.QueryArray("topic")
+		if len(topics) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "missing topic"})
+			return
+		}
+
+		lastEventID := ctx.GetHeader(LastEventIDHeader)
+		if lastEventID == "" {
+			lastEventID = ctx.Query("last_event_id")
+		}
+		// ids which are not offsets, e.g. message ids sent without message log, can not be resumed from.
+		lastOffset, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+		messages, err := gateway.Stream(ctx.Request.Context(), middlewares.Identity(ctx), topics, lastOffset)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Status(http.StatusOK)
+		flush(ctx)
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Request.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(ctx.Writer, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				if err := writeEvent(ctx, msg); err != nil {
+					return
+				}
+			}
+			flush(ctx)
+		}
+	}
+}
+
+func writeEvent(ctx *gin.Context, msg domain.Delivery) error {
+	data, err := json.Marshal(streamEvent{
+		MessageID: msg.MessageID,
+		Topic:     msg.Topic,
+		Content:   msg.Content,
+		Headers:   msg.Headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	id := string(msg.MessageID)
+	if msg.Offset != 0 {
+		id = strconv.FormatUint(msg.Offset, 10)
+	}
+
+	_, err = fmt.Fprintf(ctx.Writer, "id: %s\nevent: message\ndata: %s\n\n", id, data)
+
+	return err
+}
+
+func flush(ctx *gin.Context) {
+	if f, ok := ctx.Writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestStreamHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		header     http.Header
+		setup      func(gateway *mocks.MockMessageGateway)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "Resume_After_Last_Event_ID",
+			target: "/stream?topic=orders.%23&topic=payments.*",
+			header: http.Header{LastEventIDHeader: {"41"}},
+			setup: func(gateway *mocks.MockMessageGateway) {
+				messages := make(chan domain.Delivery, 2)
+				messages <- domain.Delivery{MessageID: "m1", Topic: "orders.created", Content: []byte("hi"), Offset: 42}
+				messages <- domain.Delivery{MessageID: "m2", Topic: "payments.done", Content: []byte("ok")}
+				close(messages)
+
+				gateway.EXPECT().
+					Stream(mock.Anything, "", []string{"orders.#", "payments.*"}, uint64(41)).
+					Return(messages, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: "id: 42\nevent: message\ndata: {\"message_id\":\"m1\",\"topic\":\"orders.created\",\"content\":\"aGk=\"}\n\n" +
+				"id: m2\nevent: message\ndata: {\"message_id\":\"m2\",\"topic\":\"payments.done\",\"content\":\"b2s=\"}\n\n",
+		},
+		{
+			name:   "Forbidden_Topic",
+			target: "/stream?topic=secret",
+			setup: func(gateway *mocks.MockMessageGateway) {
+				gateway.EXPECT().
+					Stream(mock.Anything, "", []string{"secret"}, uint64(0)).
+					Return(nil, domain.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Missing_Topic",
+			target:     "/stream",
+			setup:      func(*mocks.MockMessageGateway) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gateway := mocks.NewMockMessageGateway(t)
+			tt.setup(gateway)
+
+			rec := serve(StreamHandler(gateway, time.Minute), http.MethodGet, "/stream", tt.target, "", tt.header)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package middlewares represent the http middlewares.
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// IdentityKey is the context key holding the authenticated identity.
+const IdentityKey = "identity"
+
+// Authenticate resolve the identity of the caller from the Authorization header.
+// Basic credentials are checked with the PLAIN mechanism and Bearer tokens with the TOKEN mechanism.
+// Every caller is anonymous when no authenticator is given.
+func Authenticate(authenticator domain.Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if authenticator == nil {
+			ctx.Set(IdentityKey, "")
+			ctx.Next()
+			return
+		}
+
+		mechanism, response, ok := credentials(ctx.Request)
+		if !ok {
+			ctx.Header("WWW-Authenticate", `Basic realm="hoppermq"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": domain.ErrUnauthorized.Error()})
+			return
+		}
+
+		identity, err := authenticator.Authenticate(ctx.Request.Context(), mechanism, response)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": domain.ErrUnauthorized.Error()})
+			return
+		}
+
+		ctx.Set(IdentityKey, identity)
+		ctx.Next()
+	}
+}
+
+// Authorize refuse the callers whose identity, resolved by Authenticate, does not hold the permission on the topic.
+// Every caller is allowed when no authorizer is given.
+func Authorize(authorizer domain.Authorizer, topic string, permission domain.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if authorizer == nil {
+			ctx.Next()
+			return
+		}
+
+		if err := authorizer.Authorize(Identity(ctx), topic, permission); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// Identity return the identity resolved by Authenticate.
+func Identity(ctx *gin.Context) string {
+	return ctx.GetString(IdentityKey)
+}
+
+// credentials map the Authorization header to a SASL mechanism and response.
+func credentials(r *http.Request) (domain.AuthMechanism, []byte, bool) {
+	if username, password, ok := r.BasicAuth(); ok {
+		return domain.AuthMechanismPlain, []byte("\x00" + username + "\x00" + password), true
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && token != "" {
+		return domain.AuthMechanismToken, []byte(token), true
+	}
+
+	return "", nil, false
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		authorizer func(t *testing.T) domain.Authorizer
+		wantStatus int
+	}{
+		{
+			name:       "No_Authorizer",
+			authorizer: func(*testing.T) domain.Authorizer { return nil },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Allowed",
+			authorizer: func(t *testing.T) domain.Authorizer {
+				authorizer := mocks.NewMockAuthorizer(t)
+				authorizer.EXPECT().Authorize("alice", "#", domain.PermissionManage).Return(nil)
+				return authorizer
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Forbidden",
+			authorizer: func(t *testing.T) domain.Authorizer {
+				authorizer := mocks.NewMockAuthorizer(t)
+				authorizer.EXPECT().Authorize("alice", "#", domain.PermissionManage).Return(domain.ErrForbidden)
+				return authorizer
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gin.SetMode(gin.TestMode)
+			e := gin.New()
+			e.GET("/admin",
+				func(ctx *gin.Context) { ctx.Set(IdentityKey, "alice") },
+				Authorize(tt.authorizer(t), "#", domain.PermissionManage),
+				func(ctx *gin.Context) { ctx.Status(http.StatusOK) },
+			)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
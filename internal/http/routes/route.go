@@ -2,9 +2,15 @@
 package routes
 
 import (
+	"expvar"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/hoppermq/hopper/internal/http/handlers"
+	"github.com/hoppermq/hopper/internal/http/middlewares"
+	"github.com/hoppermq/hopper/pkg/domain"
 )
 
 // RegisterBaseRoutes register http routes
@@ -13,3 +19,77 @@ func RegisterBaseRoutes(e *gin.Engine) {
 		ctx.JSON(http.StatusOK, "hello world")
 	})
 }
+
+// RegisterMetricsRoutes register the metrics route, exposing the published expvar counters.
+func RegisterMetricsRoutes(e *gin.Engine) {
+	e.GET("/metrics", gin.WrapH(expvar.Handler()))
+}
+
+// RegisterWebSocketRoutes register the route upgrading the requests to websocket connections.
+func RegisterWebSocketRoutes(e *gin.Engine, path string, handler http.Handler) {
+	e.GET(path, gin.WrapH(handler))
+}
+
+const (
+	// defaultMaxPullWait cap the long-poll duration when GatewayOptions does not set it.
+	defaultMaxPullWait = 30 * time.Second
+	// defaultStreamKeepAlive is the keep-alive interval of the streams when GatewayOptions does not set it.
+	defaultStreamKeepAlive = 15 * time.Second
+)
+
+// GatewayOptions hold the limits of the gateway routes.
+type GatewayOptions struct {
+	// MaxBodySize bound the published message size, 0 disable the bound.
+	MaxBodySize int64
+	// MaxPullWait cap the long-poll duration requested by the consumers, 30s when unset.
+	MaxPullWait time.Duration
+	// StreamKeepAlive is the interval of the comments keeping the idle streams open, 15s when unset.
+	StreamKeepAlive time.Duration
+}
+
+// RegisterGatewayRoutes register the routes publishing and consuming messages under /api/v1.
+func RegisterGatewayRoutes(
+	e *gin.Engine,
+	gateway domain.MessageGateway,
+	authenticator domain.Authenticator,
+	opts GatewayOptions,
+) {
+	if opts.MaxPullWait <= 0 {
+		opts.MaxPullWait = defaultMaxPullWait
+	}
+	if opts.StreamKeepAlive <= 0 {
+		opts.StreamKeepAlive = defaultStreamKeepAlive
+	}
+
+	api := e.Group("/api/v1", middlewares.Authenticate(authenticator))
+
+	api.POST("/topics/:topic/messages", handlers.PublishHandler(gateway, opts.MaxBodySize))
+
+	api.PUT("/subscriptions/:name", handlers.CreateSubscriptionHandler(gateway))
+	api.DELETE("/subscriptions/:name", handlers.DeleteSubscriptionHandler(gateway))
+	api.GET("/subscriptions/:name/messages", handlers.PullHandler(gateway, opts.MaxPullWait))
+	api.POST("/subscriptions/:name/ack", handlers.AckHandler(gateway))
+
+	api.GET("/stream", handlers.StreamHandler(gateway, opts.StreamKeepAlive))
+}
+
+// adminTopic is the pattern the admin callers must manage, the management routes inspect every topic.
+const adminTopic = "#"
+
+// RegisterAdminRoutes register the management routes listing the broker topics, clients and containers under /api/v1/admin.
+// The callers must hold the manage permission on every topic.
+func RegisterAdminRoutes(
+	e *gin.Engine,
+	inspector domain.BrokerInspector,
+	authenticator domain.Authenticator,
+	authorizer domain.Authorizer,
+) {
+	admin := e.Group("/api/v1/admin",
+		middlewares.Authenticate(authenticator),
+		middlewares.Authorize(authorizer, adminTopic, domain.PermissionManage),
+	)
+
+	admin.GET("/topics", handlers.TopicsHandler(inspector))
+	admin.GET("/clients", handlers.ClientsHandler(inspector))
+	admin.GET("/containers", handlers.ContainersHandler(inspector))
+}
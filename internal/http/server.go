@@ -23,6 +23,16 @@ type HTTP struct {
 	// should be a domain interface here
 	engine *gin.Engine
 	server *http.Server
+
+	websocketPath    string
+	websocketHandler http.Handler
+
+	gateway        domain.MessageGateway
+	authenticator  domain.Authenticator
+	gatewayOptions routes.GatewayOptions
+
+	inspector  domain.BrokerInspector
+	authorizer domain.Authorizer
 }
 
 // Option is the type that represent the function to configure the server.
@@ -56,6 +66,34 @@ func WithEngine(engine *gin.Engine) Option {
 	}
 }
 
+// WithWebSocket serve the websocket transport handler on the given path.
+func WithWebSocket(path string, handler http.Handler) Option {
+	return func(h *HTTP) {
+		h.websocketPath = path
+		h.websocketHandler = handler
+	}
+}
+
+// WithGateway serve the publish and consume routes of the gateway, authenticating the callers
+// with the authenticator when it is not nil.
+func WithGateway(gateway domain.MessageGateway, authenticator domain.Authenticator, opts routes.GatewayOptions) Option {
+	return func(h *HTTP) {
+		h.gateway = gateway
+		h.authenticator = authenticator
+		h.gatewayOptions = opts
+	}
+}
+
+// WithAdmin serve the management routes inspecting the broker, authenticating the callers
+// with the authenticator and requiring the manage permission from the authorizer when they are not nil.
+func WithAdmin(inspector domain.BrokerInspector, authenticator domain.Authenticator, authorizer domain.Authorizer) Option {
+	return func(h *HTTP) {
+		h.inspector = inspector
+		h.authenticator = authenticator
+		h.authorizer = authorizer
+	}
+}
+
 // NewHTTPServer return a new HTTP.
 func NewHTTPServer(opts ...Option) *HTTP {
 	httpServer := &HTTP{}
@@ -77,10 +115,18 @@ func NewHTTPServer(opts ...Option) *HTTP {
 // Run start the services.
 func (h *HTTP) Run(ctx context.Context) error {
 	h.logger.Info("starting the http server component", "name", h.Name())
-	h.eb.Subscribe(string(domain.EventTypeNewConnection))
-	h.eb.Subscribe(string(domain.EventTypeSendMessage))
 
 	routes.RegisterBaseRoutes(h.engine)
+	routes.RegisterMetricsRoutes(h.engine)
+	if h.websocketHandler != nil {
+		routes.RegisterWebSocketRoutes(h.engine, h.websocketPath, h.websocketHandler)
+	}
+	if h.gateway != nil {
+		routes.RegisterGatewayRoutes(h.engine, h.gateway, h.authenticator, h.gatewayOptions)
+	}
+	if h.inspector != nil {
+		routes.RegisterAdminRoutes(h.engine, h.inspector, h.authenticator, h.authorizer)
+	}
 	if err := h.engine.Run(h.server.Addr); err != nil {
 		h.logger.Warn("http server stopped", "error", err)
 	}
@@ -7,12 +7,13 @@ import (
 	"log/slog"
 	"sync"
 
-	"github.com/hoppermq/hopper/internal/common"
 	"github.com/hoppermq/hopper/internal/mq/core/client"
 	"github.com/hoppermq/hopper/internal/mq/core/protocol/container"
-	"github.com/hoppermq/hopper/internal/mq/core/protocol/frames"
-	"github.com/hoppermq/hopper/internal/mq/core/protocol/serializer"
+	"github.com/hoppermq/hopper/internal/mq/core/subscription"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
 )
 
 // Broker is the core component of the HopperMQ system, responsible for managing message queues and handling client connections.
@@ -28,6 +29,14 @@ type Broker struct {
 
 	clientManager    *client.Manager
 	containerManager *container.Manager
+	subscriptions    *subscription.Manager
+	messageLog       domain.MessageLog
+
+	authenticator   domain.Authenticator
+	maxAuthAttempts int
+	authorizer      domain.Authorizer
+	audit           *slog.Logger
+	rateLimiter     domain.RateLimiter
 
 	wg     sync.WaitGroup
 	cancel context.CancelFunc
@@ -47,6 +56,7 @@ func NewBroker(
 
 	broker := &Broker{
 		Logger:     logger,
+		audit:      logger.With("component", "audit"),
 		Serializer: newSerializer,
 		eb:         eb,
 		fm:         &frames.FrameManager{},
@@ -54,6 +64,7 @@ func NewBroker(
 
 	broker.clientManager = client.NewManager(common.GenerateIdentifier) // should be created from the main
 	broker.containerManager = container.NewContainerManager()
+	broker.subscriptions = subscription.NewManager()
 	broker.transports = append(broker.transports, transports...)
 
 	return broker
@@ -76,6 +87,7 @@ func (b *Broker) Run(ctx context.Context) error {
 	rcvdFrameCh := b.eb.Subscribe(string(domain.EventTypeReceiveMessage))
 	newConnCh := b.eb.Subscribe(string(domain.EventTypeNewConnection))
 	closedConnCh := b.eb.Subscribe(string(domain.EventTypeConnectionClosed))
+	invalidFrameCh := b.eb.Subscribe(string(domain.EventTypeInvalidFrame))
 
 	b.spawnHandler(ctx, func(ctx context.Context) {
 		b.onReceivingMessage(ctx, rcvdFrameCh)
@@ -89,6 +101,10 @@ func (b *Broker) Run(ctx context.Context) error {
 		b.onClientDisconnect(ctx, closedConnCh)
 	})
 
+	b.spawnHandler(ctx, func(ctx context.Context) {
+		b.onInvalidFrame(ctx, invalidFrameCh)
+	})
+
 	for _, transport := range b.transports {
 		go func(t domain.Service) {
 			if err := t.Run(ctx); err != nil {
@@ -105,11 +121,9 @@ func (b *Broker) Run(ctx context.Context) error {
 func (b *Broker) Stop(ctx context.Context) error {
 	b.Logger.Info("Stopping Broker Component")
 
-	if b.cancel != nil {
-		b.cancel()
-	}
-
 	if b.clientManager != nil {
+		b.closeClients(ctx, domain.CloseCodeShutdown, "broker shutting down")
+
 		if err := b.clientManager.Shutdown(ctx); err != nil {
 			b.Logger.Error("failed to shutdown client manager", "error", err)
 		} else {
@@ -117,6 +131,10 @@ func (b *Broker) Stop(ctx context.Context) error {
 		}
 	}
 
+	if b.cancel != nil {
+		b.cancel()
+	}
+
 	b.wg.Wait()
 
 	for _, service := range b.services {
@@ -134,6 +152,41 @@ func (b *Broker) Name() string {
 	return "hopper-broker"
 }
 
+// RegisterAuthenticator require clients to authenticate with an Auth frame before being connected.
+// Clients are closed after maxAttempts failed attempts, 0 allow unlimited attempts.
+func (b *Broker) RegisterAuthenticator(authenticator domain.Authenticator, maxAttempts int) {
+	b.authenticator = authenticator
+	b.maxAuthAttempts = maxAttempts
+	b.Logger.Info("Authenticator registered with", "service", b.Name(), "mechanisms", authenticator.Mechanisms())
+}
+
+// RegisterAuthorizer check the topic permissions of clients before subscribing and publishing.
+func (b *Broker) RegisterAuthorizer(authorizer domain.Authorizer) {
+	b.authorizer = authorizer
+	b.Logger.Info("Authorizer registered with", "service", b.Name())
+}
+
+// RegisterAuditLog record the authentication and authorization decisions to the given logger.
+func (b *Broker) RegisterAuditLog(audit *slog.Logger) {
+	b.audit = audit
+}
+
+// RegisterRateLimiter meter the messages published by clients.
+func (b *Broker) RegisterRateLimiter(rateLimiter domain.RateLimiter) {
+	b.rateLimiter = rateLimiter
+	b.Logger.Info("RateLimiter registered with", "service", b.Name())
+}
+
+// RegisterSubscriptionManager replace the manager of the named subscriptions consumed through the gateway.
+func (b *Broker) RegisterSubscriptionManager(subscriptions *subscription.Manager) {
+	b.subscriptions = subscriptions
+}
+
+// RegisterMessageLog retain the routed messages so stream consumers can resume from their last offset.
+func (b *Broker) RegisterMessageLog(messageLog domain.MessageLog) {
+	b.messageLog = messageLog
+}
+
 func (b *Broker) RegisterEventBus(eb domain.IEventBus) {
 	b.eb = eb
 	b.Logger.Info("EventBus registered with", "service", b.Name())
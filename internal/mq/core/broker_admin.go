@@ -0,0 +1,73 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Topics return the topic patterns the containers are subscribed to, with their subscribers count.
+func (b *Broker) Topics() []domain.TopicInfo {
+	snapshot := b.containerManager.Registry.Snapshot()
+
+	topics := make([]domain.TopicInfo, 0, len(snapshot))
+	for topic, containers := range snapshot {
+		topics = append(topics, domain.TopicInfo{Topic: topic, Subscribers: len(containers)})
+	}
+	slices.SortFunc(topics, func(a, b domain.TopicInfo) int {
+		return cmp.Compare(a.Topic, b.Topic)
+	})
+
+	return topics
+}
+
+// Clients return the clients connected to the broker.
+func (b *Broker) Clients() []domain.ClientInfo {
+	clients := b.clientManager.GetClients()
+
+	infos := make([]domain.ClientInfo, 0, len(clients))
+	for _, c := range clients {
+		infos = append(infos, domain.ClientInfo{
+			ID:          c.ID,
+			ContainerID: c.GetContainer(),
+			Identity:    c.GetIdentity(),
+			Transport:   c.GetTransport(),
+		})
+	}
+	slices.SortFunc(infos, func(a, b domain.ClientInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return infos
+}
+
+// Containers return the containers of the broker with the topic patterns they are subscribed to.
+func (b *Broker) Containers() []domain.ContainerInfo {
+	topics := make(map[domain.ID][]string)
+	for topic, containers := range b.containerManager.Registry.Snapshot() {
+		for _, id := range containers {
+			topics[id] = append(topics[id], topic)
+		}
+	}
+
+	containers := b.containerManager.FindContainers()
+	infos := make([]domain.ContainerInfo, 0, len(containers))
+	for _, ctr := range containers {
+		ctrTopics := topics[ctr.GetID()]
+		slices.Sort(ctrTopics)
+		infos = append(infos, domain.ContainerInfo{
+			ID:              ctr.GetID(),
+			ClientID:        ctr.GetClientID(),
+			State:           ctr.GetState(),
+			ProtocolVersion: ctr.ProtocolVersion,
+			Capabilities:    ctr.Capabilities.String(),
+			Topics:          append([]string{}, ctrTopics...),
+		})
+	}
+	slices.SortFunc(infos, func(a, b domain.ContainerInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return infos
+}
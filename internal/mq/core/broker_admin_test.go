@@ -0,0 +1,55 @@
+package core
+
+import (
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func TestBroker_Inspect(t *testing.T) {
+	t.Parallel()
+
+	broker := NewBroker(slog.New(slog.DiscardHandler), events.NewEventBus(10))
+
+	conn, peer := net.Pipe()
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = peer.Close()
+	})
+
+	c := broker.clientManager.HandleNewClient(conn)
+	c.SetIdentity("app")
+	c.SetTransport(domain.TransportType("tcp"))
+	ctr := broker.containerManager.CreateNewContainer(func() domain.ID { return "container-1" }, c.ID)
+	c.AttachContainer(ctr.GetID())
+	ctr.Capabilities = domain.CapabilityConfirms
+	broker.containerManager.UpdateContainerState(ctr.GetID(), domain.ContainerConnected)
+
+	broker.containerManager.RegisterContainerToTopic("orders.*", ctr.GetID())
+	broker.containerManager.RegisterContainerToTopic("orders.*", "container-2")
+	broker.containerManager.RegisterContainerToTopic("audit.#", ctr.GetID())
+
+	assert.Equal(t, []domain.TopicInfo{
+		{Topic: "audit.#", Subscribers: 1},
+		{Topic: "orders.*", Subscribers: 2},
+	}, broker.Topics())
+
+	assert.Equal(t, []domain.ClientInfo{
+		{ID: c.ID, ContainerID: "container-1", Identity: "app", Transport: "tcp"},
+	}, broker.Clients())
+
+	assert.Equal(t, []domain.ContainerInfo{
+		{
+			ID:           "container-1",
+			ClientID:     c.ID,
+			State:        domain.ContainerConnected,
+			Capabilities: "confirms",
+			Topics:       []string{"audit.#", "orders.*"},
+		},
+	}, broker.Containers())
+}
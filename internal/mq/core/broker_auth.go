@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hoppermq/hopper/internal/mq/core/protocol/container"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// isAuthenticated return true when the client behind the connection is allowed to connect.
+// Every client is allowed when no authenticator is registered.
+func (b *Broker) isAuthenticated(conn domain.Connection) bool {
+	if b.authenticator == nil {
+		return true
+	}
+
+	client := b.clientManager.GetClientByConnection(conn)
+	return client != nil && client.GetIdentity() != ""
+}
+
+// authenticate verify the credentials of an Auth frame and attach the resulting identity to the client.
+// The Auth frame is only accepted before the container is connected.
+func (b *Broker) authenticate(ctx context.Context, conn domain.Connection, ctr *container.Container, frame domain.Frame) {
+	client := b.clientManager.GetClientByConnection(conn)
+	if client == nil {
+		b.rejectFrame(ctx, conn, domain.ErrContainerNotFound, frame)
+		return
+	}
+
+	if state := ctr.GetState(); state != domain.ContainerOpenSent && state != domain.ContainerReserved {
+		b.rejectFrame(ctx, conn, fmt.Errorf("%w for Auth frame: got %s", domain.ErrInvalidContainerState, state), frame)
+		return
+	}
+
+	payload, ok := frame.GetPayload().(domain.AuthFramePayload)
+	if !ok {
+		b.rejectFrame(ctx, conn, fmt.Errorf("%w: expected Auth payload", domain.ErrInvalidPayload), frame)
+		return
+	}
+
+	if b.authenticator == nil {
+		b.rejectFrame(ctx, conn, fmt.Errorf("%w: authentication is not enabled", domain.ErrUnsupportedFrameType), frame)
+		return
+	}
+
+	identity, err := b.authenticator.Authenticate(ctx, payload.GetMechanism(), payload.GetResponse())
+	if err != nil {
+		failures := client.RecordAuthFailure()
+		b.audit.Warn("authentication failed",
+			"client_id", client.ID,
+			"mechanism", payload.GetMechanism(),
+			"attempt", failures,
+			"error", err)
+		b.rejectFrame(ctx, conn, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err), frame)
+
+		if b.maxAuthAttempts > 0 && failures >= b.maxAuthAttempts {
+			b.closeClient(ctx, client, domain.CloseCodeAuthFailed, "too many authentication attempts")
+			b.spawnHandler(ctx, func(ctx context.Context) {
+				b.teardownContainer(ctx, ctr)
+			})
+		}
+		return
+	}
+
+	client.SetIdentity(identity)
+	b.audit.Info("client authenticated",
+		"client_id", client.ID,
+		"mechanism", payload.GetMechanism(),
+		"identity", identity)
+}
+
+// authorize check the permission of the client behind the connection on the topic.
+func (b *Broker) authorize(conn domain.Connection, topic string, permission domain.Permission) error {
+	if b.authorizer == nil {
+		return nil
+	}
+
+	identity := ""
+	if client := b.clientManager.GetClientByConnection(conn); client != nil {
+		identity = client.GetIdentity()
+	}
+
+	return b.authorizeIdentity(identity, topic, permission)
+}
+
+// authorizeIdentity check the permission of the identity on the topic.
+// Denied operations are recorded in the audit log.
+func (b *Broker) authorizeIdentity(identity string, topic string, permission domain.Permission) error {
+	if b.authorizer == nil {
+		return nil
+	}
+
+	if err := b.authorizer.Authorize(identity, topic, permission); err != nil {
+		b.audit.Warn("operation denied",
+			"identity", identity,
+			"operation", permission,
+			"topic", topic,
+			"error", err)
+		return err
+	}
+
+	return nil
+}
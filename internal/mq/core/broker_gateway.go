@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/mq/core/subscription"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+// streamBuffer bound the messages buffered for a stream consumer before it is considered too slow.
+const streamBuffer = 256
+
+// gatewayClientID return the id the rate limits of a gateway identity are accounted under.
+func gatewayClientID(identity string) domain.ID {
+	return domain.ID("gateway:" + identity)
+}
+
+// Publish route a message published through the gateway, with the permission and rate limits checks of protocol clients.
+// In throttle mode the call wait until the rate limits allow the message.
+func (b *Broker) Publish(
+	ctx context.Context,
+	identity string,
+	topic string,
+	content []byte,
+	headers map[string]string,
+) (domain.ID, error) {
+	if err := b.authorizeIdentity(identity, topic, domain.PermissionPublish); err != nil {
+		return "", err
+	}
+
+	if b.rateLimiter != nil {
+		delay, err := b.rateLimiter.Reserve(gatewayClientID(identity), identity, topic, len(content))
+		if err != nil {
+			b.Logger.Warn("message rate limited", "identity", identity, "topic", topic, "error", err)
+			return "", err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return "", err
+		}
+	}
+
+	messageID := common.GenerateIdentifier()
+	frame, err := frames.CreateMessageFrame(domain.DOFF4, topic, messageID, content, headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to create message frame: %w", err)
+	}
+
+	b.RouteMessageFrames(ctx, frame)
+
+	return messageID, nil
+}
+
+// CreateSubscription bind a named subscription owned by the identity to the topic pattern
+// once the identity is allowed to subscribe to it.
+func (b *Broker) CreateSubscription(identity string, name string, topic string) error {
+	if err := b.authorizeIdentity(identity, topic, domain.PermissionSubscribe); err != nil {
+		return err
+	}
+
+	_, err := b.subscriptions.Create(name, topic, identity)
+
+	return err
+}
+
+// DeleteSubscription remove the named subscription of the identity.
+func (b *Broker) DeleteSubscription(identity string, name string) error {
+	if _, err := b.ownedSubscription(identity, name); err != nil {
+		return err
+	}
+
+	return b.subscriptions.Delete(name)
+}
+
+// ownedSubscription return the named subscription when the identity own it and is still allowed to subscribe to its topic.
+func (b *Broker) ownedSubscription(identity string, name string) (*subscription.Subscription, error) {
+	sub, err := b.subscriptions.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Owner != identity {
+		return nil, fmt.Errorf("%w: subscription %q is owned by another identity", domain.ErrForbidden, name)
+	}
+
+	if err := b.authorizeIdentity(identity, sub.Topic, domain.PermissionSubscribe); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Pull return up to max messages of the named subscription of the identity, waiting up to wait for the first one.
+func (b *Broker) Pull(
+	ctx context.Context,
+	identity string,
+	name string,
+	max int,
+	wait time.Duration,
+) ([]domain.Delivery, error) {
+	sub, err := b.ownedSubscription(identity, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub.Pull(ctx, max, wait), nil
+}
+
+// Ack settle the deliveries of the named subscription of the identity.
+func (b *Broker) Ack(identity string, name string, deliveryIDs []domain.ID) error {
+	sub, err := b.ownedSubscription(identity, name)
+	if err != nil {
+		return err
+	}
+
+	return sub.Ack(deliveryIDs...)
+}
+
+// Stream push the messages published on the topic patterns the identity is allowed to subscribe to.
+// The retained messages above lastOffset are replayed first when a message log is registered.
+func (b *Broker) Stream(
+	ctx context.Context,
+	identity string,
+	topics []string,
+	lastOffset uint64,
+) (<-chan domain.Delivery, error) {
+	for _, topic := range topics {
+		if err := b.authorizeIdentity(identity, topic, domain.PermissionSubscribe); err != nil {
+			return nil, err
+		}
+	}
+
+	// the stream is opened before the replay, so no message is lost between the two.
+	stream := b.subscriptions.OpenStream(topics, streamBuffer)
+
+	var replay []domain.Delivery
+	if b.messageLog != nil && lastOffset > 0 {
+		for _, msg := range b.messageLog.ReadAfter(lastOffset) {
+			if stream.Match(msg.Topic) {
+				replay = append(replay, msg)
+			}
+		}
+	}
+
+	out := make(chan domain.Delivery)
+	go func() {
+		defer close(out)
+		defer stream.Close()
+
+		// the live messages already replayed are skipped, an offset unknown to the log replay nothing.
+		var replayed uint64
+		for _, msg := range replay {
+			if !forward(ctx, out, msg) {
+				return
+			}
+			replayed = msg.Offset
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-stream.Messages():
+				if !ok {
+					return
+				}
+				if msg.Offset != 0 && msg.Offset <= replayed {
+					continue
+				}
+				if !forward(ctx, out, msg) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// forward send the message unless the context end first.
+func forward(ctx context.Context, out chan<- domain.Delivery, msg domain.Delivery) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- msg:
+		return true
+	}
+}
+
+// sleep pause for the delay, returning early with the context error when it end.
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core/retention"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func receive(t *testing.T, messages <-chan domain.Delivery) domain.Delivery {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return domain.Delivery{}
+	}
+}
+
+func TestBroker_Stream(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := NewBroker(slog.New(slog.DiscardHandler), events.NewEventBus(10))
+	broker.RegisterMessageLog(retention.NewLog(10))
+
+	for _, topic := range []string{"orders.created", "payments.done", "orders.paid"} {
+		_, err := broker.Publish(ctx, "", topic, []byte(topic), nil)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name       string
+		lastOffset uint64
+		want       []string
+	}{
+		{name: "Replay_After_Offset", lastOffset: 1, want: []string{"orders.paid", "orders.shipped"}},
+		{name: "Live_Only_Without_Offset", lastOffset: 0, want: []string{"orders.shipped"}},
+		{name: "Unknown_Offset_Live_Only", lastOffset: 99, want: []string{"orders.shipped"}},
+	}
+
+	streams := make([]<-chan domain.Delivery, len(tests))
+	for i, tt := range tests {
+		messages, err := broker.Stream(ctx, "", []string{"orders.*"}, tt.lastOffset)
+		require.NoError(t, err)
+		streams[i] = messages
+	}
+
+	_, err := broker.Publish(ctx, "", "orders.shipped", []byte("orders.shipped"), nil)
+	require.NoError(t, err)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, topic := range tt.want {
+				assert.Equal(t, topic, receive(t, streams[i]).Topic)
+			}
+		})
+	}
+}
+
+func TestBroker_Subscription_Owner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := NewBroker(slog.New(slog.DiscardHandler), events.NewEventBus(10))
+
+	require.NoError(t, broker.CreateSubscription("alice", "billing", "orders.#"))
+	_, err := broker.Publish(ctx, "alice", "orders.created", []byte("order"), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "Create_Taken_Name",
+			call: func() error { return broker.CreateSubscription("mallory", "billing", "orders.#") },
+		},
+		{
+			name: "Pull",
+			call: func() error {
+				_, err := broker.Pull(ctx, "mallory", "billing", 1, 0)
+				return err
+			},
+		},
+		{
+			name: "Ack",
+			call: func() error { return broker.Ack("mallory", "billing", []domain.ID{"d1"}) },
+		},
+		{
+			name: "Delete",
+			call: func() error { return broker.DeleteSubscription("mallory", "billing") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), domain.ErrForbidden, "Another identity should be refused")
+		})
+	}
+
+	deliveries, err := broker.Pull(ctx, "alice", "billing", 1, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1, "The owner should still consume the subscription")
+	assert.NoError(t, broker.DeleteSubscription("alice", "billing"))
+}
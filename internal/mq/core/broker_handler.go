@@ -2,17 +2,30 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/hoppermq/hopper/internal/common"
 	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core/client"
+	"github.com/hoppermq/hopper/internal/mq/core/protocol"
 	"github.com/hoppermq/hopper/internal/mq/core/protocol/container"
-	"github.com/hoppermq/hopper/internal/mq/core/protocol/frames"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
+	wire "github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
 )
 
+// closeDrainTimeout bound the time spent flushing a client pending deliveries on a client initiated close.
+const closeDrainTimeout = 5 * time.Second
+
 func (b *Broker) handleNewClientConnection(ctx context.Context, evt *events.NewConnectionEvent) {
 	client := b.clientManager.HandleNewClient(evt.Conn)
+	client.SetTransport(evt.Transport)
+	if evt.Identity != "" {
+		client.SetIdentity(evt.Identity)
+		b.Logger.Info("client authenticated by certificate", "client_id", client.ID, "identity", evt.Identity)
+	}
 	ctr := b.containerManager.CreateNewContainer(
 		common.GenerateIdentifier,
 		client.ID,
@@ -22,13 +35,17 @@ func (b *Broker) handleNewClientConnection(ctx context.Context, evt *events.NewC
 		"container_id",
 		ctr.GetID(),
 		"current_state",
-		ctr.State,
+		ctr.GetState(),
 	)
+	client.AttachContainer(ctr.GetID())
+
 	frameHeaderPayload := &frames.PayloadHeader{}
 	framePayload := frames.CreateOpenFramePayload(
 		frameHeaderPayload,
 		client.ID,
 		ctr.GetID(),
+		protocol.SupportedVersions,
+		protocol.BrokerCapabilities,
 	)
 
 	frame, err := frames.CreateFrame(
@@ -46,33 +63,18 @@ func (b *Broker) handleNewClientConnection(ctx context.Context, evt *events.NewC
 		return
 	}
 
-	data, err := b.Serializer.SerializeFrame(frame)
-	if err != nil {
-		b.Logger.Warn("failed to serialize frame", "error", err)
-	}
-	sendMsgEvt := &events.SendMessageEvent{
-		ClientID:  client.ID,
-		Conn:      client.Conn,
-		Message:   data,
-		Transport: domain.TransportTypeTCP,
-		BaseEvent: events.BaseEvent{
-			EventType: domain.EventTypeSendMessage,
-		},
-	}
-
-	if err := b.eb.Publish(ctx, sendMsgEvt); err != nil {
-		b.Logger.Warn("failed to publish new message send event", "error", err)
-		return
-	}
-
+	// the client may answer as soon as the Open frame is written, the container must already expect it.
 	b.containerManager.UpdateContainerState(ctr.ID, domain.ContainerOpenSent)
 
+	if err := b.sendFrame(ctx, client, frame); err != nil {
+		b.Logger.Warn("failed to send open frame", "error", err)
+	}
 }
 
 func (b *Broker) handleConnectionClosed(ctx context.Context, evt *events.ClientDisconnectEvent) {
 	b.Logger.Info("client disconnected event", "client", evt.ClientID)
 
-	b.clientManager.RemoveClient(evt.ClientID)
+	b.releaseClient(evt.ClientID)
 }
 
 func (b *Broker) handleConnectionClosedByConn(ctx context.Context, evt *events.ClientDisconnectedEvent) {
@@ -84,16 +86,48 @@ func (b *Broker) handleConnectionClosedByConn(ctx context.Context, evt *events.C
 
 	b.Logger.Info("client disconnected event", "client", client.ID)
 
-	b.clientManager.RemoveClient(client.ID)
+	b.releaseClient(client.ID)
+}
+
+// releaseClient remove the client, its container and the state held for them.
+// A connection dropped without Close frame leave its container behind otherwise.
+func (b *Broker) releaseClient(clientID domain.ID) {
+	if c := b.clientManager.GetClient(clientID); c != nil {
+		b.containerManager.RemoveContainer(c.GetContainer())
+	}
+	b.clientManager.RemoveClient(clientID)
+	if b.rateLimiter != nil {
+		b.rateLimiter.Release(clientID)
+	}
 }
 
-func (b *Broker) RouteControlFrames(ctx context.Context, frame domain.Frame) {
+func (b *Broker) RouteControlFrames(ctx context.Context, conn domain.Connection, frame domain.Frame) {
 	frameType := frame.GetType()
 
-	container := b.getContainerForFrame(frame)
+	container := b.getContainerForFrame(conn, frame)
 	if container == nil {
 		b.Logger.Warn("container not found for frame", "frame_type", frameType)
+		b.rejectFrame(ctx, conn, domain.ErrContainerNotFound, frame)
+		return
+	}
+
+	switch frameType {
+	case domain.FrameTypeAuth:
+		b.authenticate(ctx, conn, container, frame)
 		return
+	case domain.FrameTypeConnect:
+		if !b.isAuthenticated(conn) {
+			b.Logger.Warn("connect refused, client not authenticated", "container_id", container.GetID())
+			b.rejectFrame(ctx, conn, domain.ErrUnauthorized, frame)
+			return
+		}
+	case domain.FrameTypeSubscribe:
+		if payload, ok := frame.GetPayload().(domain.SubscribeFramePayload); ok {
+			if err := b.authorize(conn, payload.GetTopic(), domain.PermissionSubscribe); err != nil {
+				b.rejectFrame(ctx, conn, err, frame)
+				return
+			}
+		}
 	}
 
 	sendCallback := b.createFrameSendCallback()
@@ -103,36 +137,157 @@ func (b *Broker) RouteControlFrames(ctx context.Context, frame domain.Frame) {
 			"frame_type", frameType,
 			"container_id", container.GetID(),
 			"error", err)
+		b.rejectFrame(ctx, conn, err, frame)
 		return
 	}
 
+	b.updateTopicRegistry(container, frame)
+
 	b.Logger.Info("frame handled successfully",
 		"frame_type", frameType,
 		"container_id", container.GetID(),
 		"container_state", container.GetState())
+
+	if frameType == domain.FrameTypeClose {
+		b.spawnHandler(ctx, func(ctx context.Context) {
+			b.teardownContainer(ctx, container)
+		})
+	}
 }
 
-func (b *Broker) RouteMessageFrames(frame domain.Frame) {
-	framePayload := frame.GetPayload().(*frames.MessageFramePayload)
-	containers := b.containerManager.FindContainersByTopic(
-		framePayload.GetTopic(),
-	)
-	for _, container := range containers {
-		container.CreateChannel(
-			framePayload.GetTopic(),
-			common.GenerateIdentifier,
-		)
+// routeMessage check the client is authenticated and connected, then its publish permission and rate limits
+// before routing the message frame.
+func (b *Broker) routeMessage(ctx context.Context, conn domain.Connection, frame domain.Frame) {
+	payload, ok := frame.GetPayload().(domain.MessageFramePayload)
+	if !ok {
+		b.rejectFrame(ctx, conn, fmt.Errorf("%w: expected Message payload", domain.ErrInvalidPayload), frame)
+		return
+	}
+
+	if !b.isAuthenticated(conn) {
+		b.Logger.Warn("message refused, client not authenticated", "topic", payload.GetTopic())
+		b.rejectFrame(ctx, conn, domain.ErrUnauthorized, frame)
+		return
+	}
+
+	if err := b.checkConnected(conn); err != nil {
+		b.rejectFrame(ctx, conn, err, frame)
+		return
+	}
+
+	if err := b.authorize(conn, payload.GetTopic(), domain.PermissionPublish); err != nil {
+		b.rejectFrame(ctx, conn, err, frame)
+		return
+	}
+
+	if err := b.limitRate(ctx, conn, payload); err != nil {
+		b.rejectFrame(ctx, conn, err, frame)
+		return
+	}
+
+	b.RouteMessageFrames(ctx, frame)
+	b.confirm(ctx, conn, payload)
+}
+
+// checkConnected return ErrInvalidContainerState unless the container of the connection is connected.
+func (b *Broker) checkConnected(conn domain.Connection) error {
+	c := b.clientManager.GetClientByConnection(conn)
+	if c == nil {
+		return domain.ErrContainerNotFound
+	}
+
+	ctr := b.containerManager.FindContainer(c.GetContainer())
+	if ctr == nil {
+		return domain.ErrContainerNotFound
+	}
+
+	if state := ctr.GetState(); state != domain.ContainerConnected {
+		return fmt.Errorf("%w for Message frame: got %s", domain.ErrInvalidContainerState, state)
+	}
+
+	return nil
+}
+
+// confirm send a Confirm frame for the routed message to its publisher, when its container negotiated the confirms.
+func (b *Broker) confirm(ctx context.Context, conn domain.Connection, payload domain.MessageFramePayload) {
+	c := b.clientManager.GetClientByConnection(conn)
+	if c == nil {
+		return
+	}
+
+	ctr := b.containerManager.FindContainer(c.GetContainer())
+	if ctr == nil || !ctr.Capabilities.Has(domain.CapabilityConfirms) {
+		return
+	}
+
+	frame, err := frames.CreateConfirmFrame(domain.DOFF4, payload.GetMessageID())
+	if err != nil {
+		b.Logger.Warn("failed to create confirm frame", "error", err)
+		return
+	}
+
+	if err := b.sendFrame(ctx, c, frame); err != nil {
+		b.Logger.Warn("failed to send confirm frame", "client_id", c.ID, "message_id", payload.GetMessageID(), "error", err)
+	}
+}
+
+// updateTopicRegistry attach or detach the container from the topic of an handled Subscribe or Unsubscribe frame.
+func (b *Broker) updateTopicRegistry(ctr *container.Container, frame domain.Frame) {
+	switch payload := frame.GetPayload().(type) {
+	case domain.SubscribeFramePayload:
+		b.containerManager.RegisterContainerToTopic(payload.GetTopic(), ctr.GetID())
+	case domain.UnsubscribeFramePayload:
+		b.containerManager.RemoveContainerFromTopic(payload.GetTopic(), ctr.GetID())
+	}
+}
+
+// RouteMessageFrames deliver the message frame to the connected containers subscribed to its topic,
+// record it in the message log and queue it on the matching named subscriptions and streams.
+func (b *Broker) RouteMessageFrames(ctx context.Context, frame domain.Frame) {
+	payload, ok := frame.GetPayload().(domain.MessageFramePayload)
+	if !ok {
+		return
+	}
 
+	for _, ctr := range b.containerManager.FindContainersByTopic(payload.GetTopic()) {
+		if ctr.GetState() != domain.ContainerConnected {
+			continue
+		}
+
+		c := b.clientManager.GetClient(ctr.GetClientID())
+		if c == nil {
+			continue
+		}
+
+		if err := b.sendFrame(ctx, c, frame); err != nil {
+			b.Logger.Warn("failed to deliver message", "client_id", c.ID, "topic", payload.GetTopic(), "error", err)
+		}
 	}
+
+	msg := domain.Delivery{
+		MessageID: payload.GetMessageID(),
+		Topic:     payload.GetTopic(),
+		Content:   payload.GetContent(),
+		Headers:   payload.GetHeaders(),
+	}
+	if b.messageLog != nil {
+		msg.Offset = b.messageLog.Append(msg)
+	}
+
+	b.subscriptions.Publish(msg)
 }
 
 func (b *Broker) RouteErrorFrames(frame domain.Frame) {}
 
 // getContainerForFrame extracts the container from a frame based on its type and payload
-func (b *Broker) getContainerForFrame(frame domain.Frame) *container.Container {
+func (b *Broker) getContainerForFrame(conn domain.Connection, frame domain.Frame) *container.Container {
 	var sourceID domain.ID
 
 	switch frame.GetType() {
+	case domain.FrameTypeClose, domain.FrameTypeSubscribe, domain.FrameTypeUnsubscribe, domain.FrameTypeAuth:
+		if client := b.clientManager.GetClientByConnection(conn); client != nil {
+			sourceID = client.ID
+		}
 	case domain.FrameTypeOpenRcvd:
 		if payload, ok := frame.GetPayload().(domain.OpenRcvdFramePayload); ok {
 			sourceID = payload.GetSourceID()
@@ -141,11 +296,6 @@ func (b *Broker) getContainerForFrame(frame domain.Frame) *container.Container {
 		if payload, ok := frame.GetPayload().(domain.ConnectFramePayload); ok {
 			sourceID = payload.GetSourceID()
 		}
-	case domain.FrameTypeSubscribe:
-		if _, ok := frame.GetPayload().(domain.SubscribeFramePayload); ok {
-			b.Logger.Warn("Subscribe frame handling not yet implemented")
-			return nil
-		}
 	default:
 		b.Logger.Warn("unsupported frame type for container lookup", "frame_type", frame.GetType())
 		return nil
@@ -173,23 +323,8 @@ func (b *Broker) createFrameSendCallback() func(context.Context, domain.Frame, d
 			return fmt.Errorf("client not found: %s", clientID)
 		}
 
-		data, err := b.Serializer.SerializeFrame(frame)
-		if err != nil {
-			return fmt.Errorf("failed to serialize frame: %w", err)
-		}
-
-		sendMsgEvt := &events.SendMessageEvent{
-			ClientID:  clientID,
-			Conn:      client.Conn,
-			Message:   data,
-			Transport: domain.TransportTypeTCP,
-			BaseEvent: events.BaseEvent{
-				EventType: domain.EventTypeSendMessage,
-			},
-		}
-
-		if err := b.eb.Publish(ctx, sendMsgEvt); err != nil {
-			return fmt.Errorf("failed to publish send message event: %w", err)
+		if err := b.sendFrame(ctx, client, frame); err != nil {
+			return err
 		}
 
 		b.Logger.Info("frame sent via callback",
@@ -199,3 +334,124 @@ func (b *Broker) createFrameSendCallback() func(context.Context, domain.Frame, d
 		return nil
 	}
 }
+
+// sendFrame serialize the frame and hand it to the transport, tracking it as an in-flight delivery of the client.
+func (b *Broker) sendFrame(ctx context.Context, c *client.Client, frame domain.Frame) error {
+	data, err := b.Serializer.SerializeFrame(frame)
+	if err != nil {
+		return fmt.Errorf("failed to serialize frame: %w", err)
+	}
+
+	if wire.IsLargeFrame(data) && !b.supportsLargeFrames(c) {
+		return fmt.Errorf("%w: client did not negotiate %s", domain.ErrFrameTooLarge, domain.CapabilityLargeFrames)
+	}
+
+	settle, err := c.TrackDelivery()
+	if err != nil {
+		return err
+	}
+	sendMsgEvt := &events.SendMessageEvent{
+		ClientID:  c.ID,
+		Conn:      c.Conn,
+		Message:   data,
+		Transport: c.GetTransport(),
+		OnSent: func(_ error) {
+			settle()
+		},
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeSendMessage,
+		},
+	}
+
+	if err := b.eb.Publish(ctx, sendMsgEvt); err != nil {
+		// a full channel of another subscriber drop the event for it only, the owning transport settle the delivery.
+		if errors.Is(err, domain.ErrInvalidHeader) {
+			b.Logger.Warn("send message event dropped by a subscriber", "client_id", c.ID, "error", err)
+			return nil
+		}
+		settle()
+		return fmt.Errorf("failed to publish send message event: %w", err)
+	}
+
+	return nil
+}
+
+// supportsLargeFrames return true when the container of the client negotiated the large frames capability.
+func (b *Broker) supportsLargeFrames(c *client.Client) bool {
+	ctr := b.containerManager.FindContainer(c.GetContainer())
+	return ctr != nil && ctr.Capabilities.Has(domain.CapabilityLargeFrames)
+}
+
+// rejectFrame answer the client behind the connection with an Error frame describing the rejection.
+// The rejected frame, when known, is referenced in the error details along with the ID of a rejected message.
+func (b *Broker) rejectFrame(ctx context.Context, conn domain.Connection, reason error, rejected domain.Frame) {
+	code := domain.CodeOf(reason)
+	details := map[string]string{
+		"error": code.String(),
+	}
+	if rejected != nil {
+		details["frame_type"] = fmt.Sprintf("0x%02x", uint8(rejected.GetType()))
+		if payload, ok := rejected.GetPayload().(domain.MessageFramePayload); ok {
+			details["message_id"] = string(payload.GetMessageID())
+		}
+	}
+
+	frame, err := frames.CreateErrorFrame(domain.DOFF4, uint16(code), reason.Error(), details)
+	if err != nil {
+		b.Logger.Warn("failed to create error frame", "error", err)
+		return
+	}
+
+	c := b.clientManager.GetClientByConnection(conn)
+	if c == nil {
+		b.Logger.Warn("client not found for rejected frame", "error_code", code)
+		return
+	}
+
+	if err := b.sendFrame(ctx, c, frame); err != nil {
+		b.Logger.Warn("failed to send error frame", "client_id", c.ID, "error", err)
+		return
+	}
+
+	b.Logger.Info("frame rejected", "client_id", c.ID, "error_code", code, "reason", reason)
+}
+
+// closeClients send a Close frame with the given code and reason to every connected client.
+func (b *Broker) closeClients(ctx context.Context, code uint16, reason string) {
+	for _, c := range b.clientManager.GetClients() {
+		b.closeClient(ctx, c, code, reason)
+	}
+}
+
+// closeClient send a Close frame to the client and move its container to the closing state.
+func (b *Broker) closeClient(ctx context.Context, c *client.Client, code uint16, reason string) {
+	frame, err := frames.CreateCloseFrame(domain.DOFF4, code, reason)
+	if err != nil {
+		b.Logger.Warn("failed to create close frame", "error", err)
+		return
+	}
+
+	if err := b.sendFrame(ctx, c, frame); err != nil {
+		b.Logger.Warn("failed to send close frame", "client_id", c.ID, "error", err)
+		return
+	}
+
+	b.containerManager.UpdateContainerState(c.GetContainer(), domain.ContainerClosing)
+}
+
+// teardownContainer flush the pending deliveries of the container client before removing both of them.
+func (b *Broker) teardownContainer(ctx context.Context, ctr *container.Container) {
+	c := b.clientManager.GetClient(ctr.GetClientID())
+	if c != nil {
+		drainCtx, cancel := context.WithTimeout(ctx, closeDrainTimeout)
+		if err := c.Drain(drainCtx); err != nil {
+			b.Logger.Warn("pending deliveries not flushed before close", "client_id", c.ID, "error", err)
+		}
+		cancel()
+	}
+
+	b.containerManager.RemoveContainer(ctr.GetID())
+	b.releaseClient(ctr.GetClientID())
+
+	b.Logger.Info("container closed", "container_id", ctr.GetID(), "client_id", ctr.GetClientID())
+}
@@ -2,9 +2,12 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/hoppermq/hopper/internal/events"
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
 )
 
 func (b *Broker) onNewClientConnection(ctx context.Context, ch <-chan domain.Event) {
@@ -41,6 +44,22 @@ func (b *Broker) onClientDisconnect(ctx context.Context, ch <-chan domain.Event)
 	}
 }
 
+func (b *Broker) onInvalidFrame(ctx context.Context, ch <-chan domain.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			if c, ok := evt.(*events.InvalidFrameEvent); ok {
+				b.rejectFrame(ctx, c.Conn, c.Err, nil)
+			}
+		}
+	}
+}
+
 func (b *Broker) onReceivingMessage(ctx context.Context, ch <-chan domain.Event) {
 	for {
 		select {
@@ -51,9 +70,18 @@ func (b *Broker) onReceivingMessage(ctx context.Context, ch <-chan domain.Event)
 				return
 			}
 			if c, ok := evt.(*events.MessageReceivedEvent); ok {
+				if protocol.IsLargeFrame(c.Message) && !b.connSupportsLargeFrames(c.Conn) {
+					b.rejectFrame(ctx, c.Conn, fmt.Errorf("%w: %s not negotiated", domain.ErrFrameTooLarge, domain.CapabilityLargeFrames), nil)
+					continue
+				}
+
 				frame, err := b.Serializer.DeserializeFrame(c.Message)
 				if err != nil {
 					b.Logger.Warn("failed to deserialize the frame", "error", err)
+					if !errors.Is(err, domain.ErrUnsupportedFrameType) {
+						err = fmt.Errorf("%w: %w", domain.ErrInvalidFrame, err)
+					}
+					b.rejectFrame(ctx, c.Conn, err, nil)
 					continue
 				}
 
@@ -62,9 +90,10 @@ func (b *Broker) onReceivingMessage(ctx context.Context, ch <-chan domain.Event)
 				switch {
 				case b.fm.IsMessageFrame(frameType):
 					b.Logger.Info("message frame received", "frame_type", frameType)
+					b.routeMessage(ctx, c.Conn, frame)
 				case b.fm.IsControlFrame(frameType):
 					b.Logger.Info("control frame received", "frame_type", frameType)
-					b.RouteControlFrames(ctx, frame)
+					b.RouteControlFrames(ctx, c.Conn, frame)
 				case b.fm.IsErrorFrame(frameType):
 					b.Logger.Info("error frame received", "frame_type", frameType)
 				}
@@ -72,3 +101,8 @@ func (b *Broker) onReceivingMessage(ctx context.Context, ch <-chan domain.Event)
 		}
 	}
 }
+
+func (b *Broker) connSupportsLargeFrames(conn domain.Connection) bool {
+	client := b.clientManager.GetClientByConnection(conn)
+	return client != nil && b.supportsLargeFrames(client)
+}
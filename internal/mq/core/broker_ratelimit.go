@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core/client"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// limitRate account the message published by the client against its rate limits.
+// When the limiter throttle the client, the transport is asked to pause the reads of the connection.
+func (b *Broker) limitRate(ctx context.Context, conn domain.Connection, payload domain.MessageFramePayload) error {
+	if b.rateLimiter == nil {
+		return nil
+	}
+
+	client := b.clientManager.GetClientByConnection(conn)
+	if client == nil {
+		return nil
+	}
+
+	delay, err := b.rateLimiter.Reserve(client.ID, client.GetIdentity(), payload.GetTopic(), len(payload.GetContent()))
+	if err != nil {
+		b.Logger.Warn("message rate limited", "client_id", client.ID, "topic", payload.GetTopic(), "error", err)
+		return err
+	}
+
+	if delay > 0 {
+		b.throttle(ctx, client, delay)
+	}
+
+	return nil
+}
+
+func (b *Broker) throttle(ctx context.Context, c *client.Client, delay time.Duration) {
+	evt := &events.ThrottleConnectionEvent{
+		Conn:      c.Conn,
+		Delay:     delay,
+		Transport: c.GetTransport(),
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeThrottleConnection,
+		},
+	}
+
+	if err := b.eb.Publish(ctx, evt); err != nil {
+		b.Logger.Warn("failed to publish throttle event", "client_id", c.ID, "error", err)
+	}
+}
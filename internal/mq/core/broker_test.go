@@ -1 +1,80 @@
 package core
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+func TestBroker_Connection_Dropped_Without_Close(t *testing.T) {
+	t.Parallel()
+
+	broker := NewBroker(slog.New(slog.DiscardHandler), events.NewEventBus(10))
+
+	conn, peer := net.Pipe()
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = peer.Close()
+	})
+
+	c := broker.clientManager.HandleNewClient(conn)
+	ctr := broker.containerManager.CreateNewContainer(func() domain.ID { return "container-1" }, c.ID)
+	c.AttachContainer(ctr.GetID())
+	broker.containerManager.UpdateContainerState(ctr.GetID(), domain.ContainerConnected)
+	require.Len(t, broker.Containers(), 1)
+
+	broker.handleConnectionClosedByConn(context.Background(), &events.ClientDisconnectedEvent{
+		Conn:      conn,
+		Transport: domain.TransportTypeTCP,
+	})
+
+	assert.Empty(t, broker.Clients())
+	assert.Empty(t, broker.Containers())
+}
+
+func TestBroker_SendFrame_Subscriber_Channel_Full(t *testing.T) {
+	t.Parallel()
+
+	eb := events.NewEventBus(1)
+	broker := NewBroker(slog.New(slog.DiscardHandler), eb)
+
+	owner := eb.Subscribe(string(domain.EventTypeSendMessage))
+	idle := eb.Subscribe(string(domain.EventTypeSendMessage))
+	require.NoError(t, eb.Publish(context.Background(), &events.SendMessageEvent{
+		BaseEvent: events.BaseEvent{EventType: domain.EventTypeSendMessage},
+	}))
+	<-owner
+
+	conn, peer := net.Pipe()
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = peer.Close()
+	})
+	c := broker.clientManager.HandleNewClient(conn)
+
+	frame, err := frames.CreateErrorFrame(domain.DOFF4, 1, "test", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, broker.sendFrame(context.Background(), c, frame),
+		"A full channel of another subscriber should not fail the delivery")
+	assert.Len(t, idle, 1)
+
+	evt, ok := (<-owner).(*events.SendMessageEvent)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.Drain(ctx), context.DeadlineExceeded, "The delivery should stay in flight until the transport settle it")
+
+	evt.OnSent(nil)
+	assert.NoError(t, c.Drain(context.Background()))
+}
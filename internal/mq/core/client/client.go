@@ -1,19 +1,33 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"sync"
 
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
+// ErrDraining is returned when tracking a delivery to a client whose deliveries are drained.
+var ErrDraining = errors.New("client deliveries are draining")
+
 // Client represents a single client connection to the broker.
 type Client struct {
 	ID          domain.ID
 	containerID domain.ID
+	identity    string
+	transport   domain.TransportType
 	Conn        domain.Connection
 	Mut         sync.Mutex
 
-	closed bool
+	closed       bool
+	authFailures int
+
+	// deliveries count the in-flight deliveries, drained is closed once they are settled after Drain started.
+	deliveryMu sync.Mutex
+	deliveries int
+	draining   bool
+	drained    chan struct{}
 }
 
 // GetID return the client ID.
@@ -28,5 +42,106 @@ func (c *Client) GetConn() domain.Connection {
 
 // GetContainer return the container attached to the client.
 func (c *Client) GetContainer() domain.ID {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
 	return c.containerID
 }
+
+// GetIdentity return the authenticated identity of the client, empty for anonymous clients.
+func (c *Client) GetIdentity() string {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	return c.identity
+}
+
+// SetIdentity set the authenticated identity of the client.
+func (c *Client) SetIdentity(identity string) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.identity = identity
+}
+
+// GetTransport return the transport the client is connected through.
+func (c *Client) GetTransport() domain.TransportType {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	return c.transport
+}
+
+// SetTransport set the transport the client is connected through.
+func (c *Client) SetTransport(transport domain.TransportType) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.transport = transport
+}
+
+// RecordAuthFailure count a failed authentication attempt and return the number of failures so far.
+func (c *Client) RecordAuthFailure() int {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.authFailures++
+	return c.authFailures
+}
+
+// AttachContainer attach the container assigned to the client.
+func (c *Client) AttachContainer(containerID domain.ID) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.containerID = containerID
+}
+
+// TrackDelivery register an outbound delivery to the client and return the func settling it.
+// The deliveries are refused with ErrDraining once Drain started.
+func (c *Client) TrackDelivery() (func(), error) {
+	c.deliveryMu.Lock()
+	defer c.deliveryMu.Unlock()
+
+	if c.draining {
+		return nil, ErrDraining
+	}
+	c.deliveries++
+
+	var once sync.Once
+	return func() {
+		once.Do(c.settleDelivery)
+	}, nil
+}
+
+func (c *Client) settleDelivery() {
+	c.deliveryMu.Lock()
+	defer c.deliveryMu.Unlock()
+
+	c.deliveries--
+	// no delivery is tracked while draining, the count reach zero once.
+	if c.draining && c.deliveries == 0 {
+		close(c.drained)
+	}
+}
+
+// Drain refuse the new deliveries and wait until every tracked delivery is settled or the context is done.
+func (c *Client) Drain(ctx context.Context) error {
+	c.deliveryMu.Lock()
+	if !c.draining {
+		c.draining = true
+		c.drained = make(chan struct{})
+		if c.deliveries == 0 {
+			close(c.drained)
+		}
+	}
+	drained := c.drained
+	c.deliveryMu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,12 +3,14 @@ package client
 import (
 	"context"
 	"testing"
+	"time"
 
-	"github.com/hoppermq/hopper/internal/common"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
 	"github.com/hoppermq/hopper/pkg/domain/mocks"
 	mocks_generator "github.com/hoppermq/hopper/pkg/domain/mocks/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClientManager(t *testing.T) {
@@ -567,3 +569,97 @@ func TestClientManager_Shutdown(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Drain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		settle  bool
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Drain_With_Settled_Deliveries",
+			settle:  true,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "Drain_With_Pending_Deliveries_Times_Out",
+			settle:  false,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{ID: "drain-client"}
+			settle, err := client.TrackDelivery()
+			require.NoError(t, err)
+			if tt.settle {
+				settle()
+				settle()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			tt.wantErr(t, client.Drain(ctx))
+
+			_, err = client.TrackDelivery()
+			assert.ErrorIs(t, err, ErrDraining, "Deliveries should be refused once draining")
+			settle()
+		})
+	}
+}
+
+func TestClient_Drain_WaitsForSettlement(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{ID: "drain-client"}
+	settles := make([]func(), 0, 3)
+	for range 3 {
+		settle, err := client.TrackDelivery()
+		require.NoError(t, err)
+		settles = append(settles, settle)
+	}
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- client.Drain(context.Background())
+	}()
+
+	for _, settle := range settles {
+		settle()
+	}
+
+	select {
+	case err := <-drained:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Drain should return once the deliveries are settled")
+	}
+	assert.NoError(t, client.Drain(context.Background()), "Drain should be idempotent")
+}
+
+func TestClientManager_ShutdownDrainsDeliveries(t *testing.T) {
+	t.Parallel()
+
+	conn := mocks.NewMockConnection(t)
+	conn.On("Close").Return(nil).Once()
+
+	cm := NewManager(common.GenerateIdentifier)
+	client := cm.HandleNewClient(conn)
+	settle, err := client.TrackDelivery()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = cm.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Shutdown should report undrained deliveries")
+	assert.Nil(t, cm.GetClient(client.ID), "Client should be removed even when not drained")
+
+	settle()
+}
@@ -83,8 +83,30 @@ func (cm *Manager) GetClient(clientID domain.ID) *Client {
 	return cm.client[clientID]
 }
 
+// GetClients return a snapshot of every client managed by the ClientManager.
+func (cm *Manager) GetClients() []*Client {
+	cm.mut.RLock()
+	defer cm.mut.RUnlock()
+
+	clients := make([]*Client, 0, len(cm.client))
+	for _, client := range cm.client {
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
 // Shutdown gracefully disconnects all clients managed by the ClientManager.
+// Pending deliveries are drained until the context deadline before closing the connections.
 func (cm *Manager) Shutdown(ctx context.Context) error {
+	var drainErr error
+	for _, client := range cm.GetClients() {
+		if err := client.Drain(ctx); err != nil {
+			drainErr = err
+			break
+		}
+	}
+
 	cm.mut.Lock()
 	defer cm.mut.Unlock()
 
@@ -95,13 +117,10 @@ func (cm *Manager) Shutdown(ctx context.Context) error {
 				// Log the error but continue shutting down other connections
 				// This ensures graceful shutdown even if some connections fail to close
 			}
-
 			client.closed = true
 		}
 		client.Mut.Unlock()
-
 		delete(cm.client, id)
 	}
-
-	return nil
+	return drainErr
 }
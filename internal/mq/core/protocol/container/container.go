@@ -2,6 +2,8 @@
 package container
 
 import (
+	"sync"
+
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
@@ -9,8 +11,18 @@ import (
 type Container struct {
 	ID       domain.ID
 	ClientID domain.ID
-	State    domain.ContainerState
 
+	// stateMu guard the state, read and written by the connection handlers and the broker shutdown.
+	stateMu sync.RWMutex
+	state   domain.ContainerState
+
+	ProtocolVersion domain.ProtocolVersion // negotiated during the handshake
+	Capabilities    domain.Capability      // negotiated during the handshake
+	IncomingWindow  uint32                 // negotiated during the handshake
+	OutgoingWindow  uint32                 // negotiated during the handshake
+
+	// channelsMu guard the channels, changed by the frame handlers and read when the broker release the container.
+	channelsMu      sync.RWMutex
 	Channels        map[domain.ID]domain.Channel // Channels by uuid
 	ChannelsByTopic map[string]domain.ID         // storing uuid channel by topic
 }
@@ -42,7 +54,7 @@ func NewContainer(id, clientID domain.ID) *Container {
 	return &Container{
 		ID:              id,
 		ClientID:        clientID,
-		State:           domain.ContainerCreated,
+		state:           domain.ContainerCreated,
 		Channels:        make(map[domain.ID]domain.Channel),
 		ChannelsByTopic: make(map[string]domain.ID),
 	}
@@ -54,6 +66,10 @@ func (ctr *Container) CreateChannel(
 	generateIdentifier func() domain.ID,
 ) *Channel {
 	channel := NewChannel(generateIdentifier, topic)
+
+	ctr.channelsMu.Lock()
+	defer ctr.channelsMu.Unlock()
+
 	ctr.Channels[channel.ID] = channel
 	ctr.ChannelsByTopic[topic] = channel.ID
 
@@ -62,6 +78,9 @@ func (ctr *Container) CreateChannel(
 
 // RemoveChannel remove the channel from the container.
 func (ctr *Container) RemoveChannel(topic string) {
+	ctr.channelsMu.Lock()
+	defer ctr.channelsMu.Unlock()
+
 	chanToRemove := ctr.findChannelByTopic(topic)
 	if chanToRemove != nil {
 		delete(ctr.Channels, chanToRemove.GetID())
@@ -69,9 +88,34 @@ func (ctr *Container) RemoveChannel(topic string) {
 	}
 }
 
+// HasChannel return true when a channel of the container is attached to the topic.
+func (ctr *Container) HasChannel(topic string) bool {
+	ctr.channelsMu.RLock()
+	defer ctr.channelsMu.RUnlock()
+
+	_, ok := ctr.ChannelsByTopic[topic]
+	return ok
+}
+
+// Topics return the topics the channels of the container are attached to.
+func (ctr *Container) Topics() []string {
+	ctr.channelsMu.RLock()
+	defer ctr.channelsMu.RUnlock()
+
+	topics := make([]string, 0, len(ctr.ChannelsByTopic))
+	for topic := range ctr.ChannelsByTopic {
+		topics = append(topics, topic)
+	}
+
+	return topics
+}
+
 // SetState set the current container state.
 func (ctr *Container) SetState(state domain.ContainerState) {
-	ctr.State = state
+	ctr.stateMu.Lock()
+	defer ctr.stateMu.Unlock()
+
+	ctr.state = state
 }
 
 // GetID return the containerID.
@@ -81,5 +125,8 @@ func (ctr *Container) GetID() domain.ID {
 
 // GetState return the current containerState.
 func (ctr *Container) GetState() domain.ContainerState {
-	return ctr.State
+	ctr.stateMu.RLock()
+	defer ctr.stateMu.RUnlock()
+
+	return ctr.state
 }
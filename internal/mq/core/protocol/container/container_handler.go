@@ -4,25 +4,39 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/hoppermq/hopper/internal/common"
-	"github.com/hoppermq/hopper/internal/mq/core/protocol/frames"
+	"github.com/hoppermq/hopper/internal/mq/core/protocol"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
 )
 
+// sessionWindow is the window the broker announce in the Begin frame, the outgoing one is capped by the client.
+const sessionWindow uint32 = 1000
+
 // FrameSendCallback represents a callback function for sending frames back to clients
 type FrameSendCallback func(ctx context.Context, frame domain.Frame, clientID domain.ID) error
 
-// HandleConnectFrame handles Connect frame and creates Begin frame response using callback approach
+// HandleConnectFrame handles Connect frame and creates Begin frame response using callback approach.
+// The client may acknowledge the Open frame with an OpenRcvd frame before connecting.
 func (ctr *Container) HandleConnectFrame(ctx context.Context, frame domain.Frame, sendCallback FrameSendCallback) error {
-	if ctr.State != domain.ContainerOpenSent {
-		return fmt.Errorf("invalid container state for Connect frame: expected %s, got %s",
-			domain.ContainerOpenSent, ctr.State)
+	if state := ctr.GetState(); state != domain.ContainerOpenSent && state != domain.ContainerReserved {
+		return fmt.Errorf("%w for Connect frame: expected %s or %s, got %s",
+			domain.ErrInvalidContainerState, domain.ContainerOpenSent, domain.ContainerReserved, state)
 	}
 
 	connectPayload, ok := frame.GetPayload().(domain.ConnectFramePayload)
 	if !ok {
-		return fmt.Errorf("invalid payload type for Connect frame")
+		return fmt.Errorf("%w: expected Connect payload", domain.ErrInvalidPayload)
+	}
+
+	version := connectPayload.GetProtocolVersion()
+	capabilities, err := protocol.Negotiate(version, connectPayload.GetCapabilities())
+	if err != nil {
+		return err
 	}
+	ctr.ProtocolVersion = version
+	ctr.Capabilities = capabilities
+	ctr.IncomingWindow, ctr.OutgoingWindow = negotiateWindows(connectPayload.GetIncomingWindow())
 
 	beginFrame, err := ctr.createBeginFrame(connectPayload.GetSourceID())
 	if err != nil {
@@ -31,47 +45,91 @@ func (ctr *Container) HandleConnectFrame(ctx context.Context, frame domain.Frame
 
 	_ = ctr.CreateChannel("__temp__", common.GenerateIdentifier)
 
-	ctr.State = domain.ContainerConnected
+	ctr.SetState(domain.ContainerConnected)
 
 	return sendCallback(ctx, beginFrame, connectPayload.GetSourceID())
 }
 
 func (ctr *Container) HandleOpenRcvdFrame(frame domain.Frame) error {
-	if ctr.State != domain.ContainerOpenSent {
-		return fmt.Errorf("invalid container state for OpenRcvd frame: expected %s, got %s",
-			domain.ContainerOpenSent, ctr.State)
+	if state := ctr.GetState(); state != domain.ContainerOpenSent {
+		return fmt.Errorf("%w for OpenRcvd frame: expected %s, got %s",
+			domain.ErrInvalidContainerState, domain.ContainerOpenSent, state)
 	}
 
 	_, ok := frame.GetPayload().(domain.OpenRcvdFramePayload)
 	if !ok {
-		return fmt.Errorf("invalid payload type for OpenRcvd frame")
+		return fmt.Errorf("%w: expected OpenRcvd payload", domain.ErrInvalidPayload)
 	}
 
-	ctr.State = domain.ContainerReserved
+	ctr.SetState(domain.ContainerReserved)
 
 	return nil
 }
 
 // HandleSubscribeFrame handles Subscribe frame and creates channels for topic subscription
 func (ctr *Container) HandleSubscribeFrame(ctx context.Context, frame domain.Frame, sendCallback FrameSendCallback) error {
-	if ctr.State != domain.ContainerConnected {
-		return fmt.Errorf("invalid container state for Subscribe frame: expected %s, got %s",
-			domain.ContainerConnected, ctr.State)
+	if state := ctr.GetState(); state != domain.ContainerConnected {
+		return fmt.Errorf("%w for Subscribe frame: expected %s, got %s",
+			domain.ErrInvalidContainerState, domain.ContainerConnected, state)
 	}
 
 	subscribePayload, ok := frame.GetPayload().(domain.SubscribeFramePayload)
 	if !ok {
-		return fmt.Errorf("invalid payload type for Subscribe frame")
+		return fmt.Errorf("%w: expected Subscribe payload", domain.ErrInvalidPayload)
 	}
 
 	topic := subscribePayload.GetTopic()
-	if _, exists := ctr.ChannelsByTopic[topic]; !exists {
+	if !ctr.HasChannel(topic) {
 		ctr.CreateChannel(topic, common.GenerateIdentifier)
 	}
 
 	return nil
 }
 
+// HandleCloseFrame handles a client initiated Close frame and acknowledge it with a Close frame.
+// The container is moved to the closing state until its pending deliveries are flushed.
+func (ctr *Container) HandleCloseFrame(ctx context.Context, frame domain.Frame, sendCallback FrameSendCallback) error {
+	if state := ctr.GetState(); state == domain.ContainerCreated || state == domain.ContainerClosing {
+		return fmt.Errorf("%w for Close frame: got %s", domain.ErrInvalidContainerState, state)
+	}
+
+	closePayload, ok := frame.GetPayload().(domain.CloseFramePayload)
+	if !ok {
+		return fmt.Errorf("%w: expected Close payload", domain.ErrInvalidPayload)
+	}
+
+	closeFrame, err := frames.CreateCloseFrame(domain.DOFF4, closePayload.GetCode(), closePayload.GetReason())
+	if err != nil {
+		return fmt.Errorf("failed to create Close frame: %w", err)
+	}
+
+	ctr.SetState(domain.ContainerClosing)
+
+	return sendCallback(ctx, closeFrame, ctr.ClientID)
+}
+
+// HandleUnsubscribeFrame handles Unsubscribe frame and removes the channel attached to the topic.
+func (ctr *Container) HandleUnsubscribeFrame(frame domain.Frame) error {
+	if state := ctr.GetState(); state != domain.ContainerConnected {
+		return fmt.Errorf("%w for Unsubscribe frame: expected %s, got %s",
+			domain.ErrInvalidContainerState, domain.ContainerConnected, state)
+	}
+
+	unsubscribePayload, ok := frame.GetPayload().(domain.UnsubscribeFramePayload)
+	if !ok {
+		return fmt.Errorf("%w: expected Unsubscribe payload", domain.ErrInvalidPayload)
+	}
+
+	topic := unsubscribePayload.GetTopic()
+	if !ctr.HasChannel(topic) {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownTopic, topic)
+	}
+
+	ctr.RemoveChannel(topic)
+
+	return nil
+}
+
 // createBeginFrame creates a Begin frame for this container
 func (ctr *Container) createBeginFrame(sourceID domain.ID) (domain.Frame, error) {
 	beginFrame, err := frames.CreateBeginFrame(
@@ -80,8 +138,8 @@ func (ctr *Container) createBeginFrame(sourceID domain.ID) (domain.Frame, error)
 		ctr.ID,
 		0,
 		0,
-		1000,
-		1000,
+		ctr.IncomingWindow,
+		ctr.OutgoingWindow,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Begin frame: %w", err)
@@ -100,11 +158,25 @@ func (ctr *Container) HandleFrame(ctx context.Context, frame domain.Frame, sendC
 		return ctr.HandleConnectFrame(ctx, frame, sendCallback)
 	case domain.FrameTypeSubscribe:
 		return ctr.HandleSubscribeFrame(ctx, frame, sendCallback)
+	case domain.FrameTypeUnsubscribe:
+		return ctr.HandleUnsubscribeFrame(frame)
+	case domain.FrameTypeClose:
+		return ctr.HandleCloseFrame(ctx, frame, sendCallback)
 	default:
-		return fmt.Errorf("unsupported frame type: %v", frameType)
+		return fmt.Errorf("%w: %v", domain.ErrUnsupportedFrameType, frameType)
 	}
 }
 
 func (ctr *Container) GetClientID() domain.ID {
 	return ctr.ClientID
 }
+
+// negotiateWindows return the windows of the Begin frame, the outgoing window never exceed
+// the incoming window announced by the client in its Connect frame.
+func negotiateWindows(clientWindow uint32) (incoming uint32, outgoing uint32) {
+	if clientWindow > 0 && clientWindow < sessionWindow {
+		return sessionWindow, clientWindow
+	}
+
+	return sessionWindow, sessionWindow
+}
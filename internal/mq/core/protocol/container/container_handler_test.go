@@ -2,6 +2,7 @@ package container
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/hoppermq/hopper/pkg/domain"
@@ -11,10 +12,13 @@ import (
 func TestContainer_HandleConnectFrame(t *testing.T) {
 	t.Run("HandleConnectFrame_ValidState_Success", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerOpenSent
+		container.SetState(domain.ContainerOpenSent)
 
 		payload := mocks.NewMockConnectFramePayload(t)
 		payload.On("GetSourceID").Return(domain.ID("client123")).Twice()
+		payload.On("GetProtocolVersion").Return(domain.ProtocolVersion1).Once()
+		payload.On("GetCapabilities").Return(domain.CapabilityLargeFrames | domain.CapabilityCompression).Once()
+		payload.On("GetIncomingWindow").Return(uint32(64)).Once()
 
 		mockFrame := mocks.NewMockFrame(t)
 		mockFrame.On("GetPayload").Return(payload)
@@ -37,8 +41,8 @@ func TestContainer_HandleConnectFrame(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error: %v", err)
 		}
-		if container.State != domain.ContainerConnected {
-			t.Errorf("Expected state %v, got %v", domain.ContainerConnected, container.State)
+		if container.GetState() != domain.ContainerConnected {
+			t.Errorf("Expected state %v, got %v", domain.ContainerConnected, container.GetState())
 		}
 		if callbackCount != 1 {
 			t.Errorf("Expected 1 callback call, got %d", callbackCount)
@@ -46,11 +50,77 @@ func TestContainer_HandleConnectFrame(t *testing.T) {
 		if _, exists := container.ChannelsByTopic["__temp__"]; !exists {
 			t.Error("Expected temporary channel to be created")
 		}
+		if container.Capabilities != domain.CapabilityLargeFrames {
+			t.Errorf("Expected capabilities %v, got %v", domain.CapabilityLargeFrames, container.Capabilities)
+		}
+		if container.OutgoingWindow != 64 {
+			t.Errorf("Expected outgoing window 64, got %d", container.OutgoingWindow)
+		}
+	})
+
+	t.Run("HandleConnectFrame_After_OpenRcvd_Success", func(t *testing.T) {
+		container := NewContainer("container123", "client123")
+		container.SetState(domain.ContainerReserved)
+
+		payload := mocks.NewMockConnectFramePayload(t)
+		payload.On("GetSourceID").Return(domain.ID("client123")).Twice()
+		payload.On("GetProtocolVersion").Return(domain.ProtocolVersion1).Once()
+		payload.On("GetCapabilities").Return(domain.CapabilityLargeFrames).Once()
+		payload.On("GetIncomingWindow").Return(uint32(0)).Once()
+
+		mockFrame := mocks.NewMockFrame(t)
+		mockFrame.On("GetPayload").Return(payload)
+
+		testCallback := func(ctx context.Context, frame domain.Frame, clientID domain.ID) error {
+			return nil
+		}
+
+		err := container.HandleConnectFrame(context.Background(), mockFrame, testCallback)
+
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if container.GetState() != domain.ContainerConnected {
+			t.Errorf("Expected state %v, got %v", domain.ContainerConnected, container.GetState())
+		}
+		if container.OutgoingWindow != sessionWindow {
+			t.Errorf("Expected outgoing window %d, got %d", sessionWindow, container.OutgoingWindow)
+		}
+	})
+
+	t.Run("HandleConnectFrame_UnsupportedVersion_Error", func(t *testing.T) {
+		container := NewContainer("container123", "client123")
+		container.SetState(domain.ContainerOpenSent)
+
+		payload := mocks.NewMockConnectFramePayload(t)
+		payload.On("GetProtocolVersion").Return(domain.ProtocolVersion(42)).Once()
+		payload.On("GetCapabilities").Return(domain.Capability(0)).Once()
+
+		mockFrame := mocks.NewMockFrame(t)
+		mockFrame.On("GetPayload").Return(payload)
+
+		callbackCount := 0
+		testCallback := func(ctx context.Context, frame domain.Frame, clientID domain.ID) error {
+			callbackCount++
+			return nil
+		}
+
+		err := container.HandleConnectFrame(context.Background(), mockFrame, testCallback)
+
+		if !errors.Is(err, domain.ErrUnsupportedVersion) {
+			t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+		}
+		if container.GetState() != domain.ContainerOpenSent {
+			t.Errorf("Expected state %v, got %v", domain.ContainerOpenSent, container.GetState())
+		}
+		if callbackCount != 0 {
+			t.Errorf("Expected 0 callback calls, got %d", callbackCount)
+		}
 	})
 
 	t.Run("HandleConnectFrame_InvalidState_Error", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerCreated
+		container.SetState(domain.ContainerCreated)
 
 		mockFrame := mocks.NewMockFrame(t)
 
@@ -66,8 +136,8 @@ func TestContainer_HandleConnectFrame(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error but got none")
 		}
-		if container.State != domain.ContainerCreated {
-			t.Errorf("Expected state %v, got %v", domain.ContainerCreated, container.State)
+		if container.GetState() != domain.ContainerCreated {
+			t.Errorf("Expected state %v, got %v", domain.ContainerCreated, container.GetState())
 		}
 		if callbackCount != 0 {
 			t.Errorf("Expected 0 callback calls, got %d", callbackCount)
@@ -78,7 +148,7 @@ func TestContainer_HandleConnectFrame(t *testing.T) {
 func TestContainer_HandleOpenRcvdFrame(t *testing.T) {
 	t.Run("HandleOpenRcvdFrame_ValidState_Success", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerOpenSent
+		container.SetState(domain.ContainerOpenSent)
 
 		payload := mocks.NewMockOpenRcvdFramePayload(t)
 		mockFrame := mocks.NewMockFrame(t)
@@ -89,14 +159,14 @@ func TestContainer_HandleOpenRcvdFrame(t *testing.T) {
 		if err != nil {
 			t.Errorf("Unexpected error: %v", err)
 		}
-		if container.State != domain.ContainerReserved {
-			t.Errorf("Expected state %v, got %v", domain.ContainerReserved, container.State)
+		if container.GetState() != domain.ContainerReserved {
+			t.Errorf("Expected state %v, got %v", domain.ContainerReserved, container.GetState())
 		}
 	})
 
 	t.Run("HandleOpenRcvdFrame_InvalidState_Error", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerCreated
+		container.SetState(domain.ContainerCreated)
 
 		mockFrame := mocks.NewMockFrame(t)
 
@@ -105,8 +175,8 @@ func TestContainer_HandleOpenRcvdFrame(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error but got none")
 		}
-		if container.State != domain.ContainerCreated {
-			t.Errorf("Expected state %v, got %v", domain.ContainerCreated, container.State)
+		if container.GetState() != domain.ContainerCreated {
+			t.Errorf("Expected state %v, got %v", domain.ContainerCreated, container.GetState())
 		}
 	})
 }
@@ -114,7 +184,7 @@ func TestContainer_HandleOpenRcvdFrame(t *testing.T) {
 func TestContainer_HandleSubscribeFrame(t *testing.T) {
 	t.Run("HandleSubscribeFrame_ValidState_Success", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerConnected
+		container.SetState(domain.ContainerConnected)
 
 		topic := "test.topic"
 		payload := mocks.NewMockSubscribeFramePayload(t)
@@ -140,7 +210,7 @@ func TestContainer_HandleSubscribeFrame(t *testing.T) {
 
 	t.Run("HandleSubscribeFrame_InvalidState_Error", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerOpenSent
+		container.SetState(domain.ContainerOpenSent)
 
 		mockFrame := mocks.NewMockFrame(t)
 
@@ -160,7 +230,7 @@ func TestContainer_HandleSubscribeFrame(t *testing.T) {
 func TestContainer_HandleFrame(t *testing.T) {
 	t.Run("HandleFrame_UnsupportedType_Error", func(t *testing.T) {
 		container := NewContainer("container123", "client123")
-		container.State = domain.ContainerConnected
+		container.SetState(domain.ContainerConnected)
 
 		mockFrame := mocks.NewMockFrame(t)
 		mockFrame.On("GetType").Return(domain.FrameTypeMessage)
@@ -177,3 +247,94 @@ func TestContainer_HandleFrame(t *testing.T) {
 		}
 	})
 }
+
+func TestContainer_HandleCloseFrame(t *testing.T) {
+	t.Run("HandleCloseFrame_ValidState_Success", func(t *testing.T) {
+		container := NewContainer("container123", "client123")
+		container.SetState(domain.ContainerConnected)
+
+		payload := mocks.NewMockCloseFramePayload(t)
+		payload.On("GetCode").Return(domain.CloseCodeNormal)
+		payload.On("GetReason").Return("bye")
+
+		mockFrame := mocks.NewMockFrame(t)
+		mockFrame.On("GetPayload").Return(payload)
+
+		callbackCount := 0
+		testCallback := func(ctx context.Context, frame domain.Frame, clientID domain.ID) error {
+			callbackCount++
+			if frame.GetType() != domain.FrameTypeClose {
+				t.Errorf("Expected Close frame, got %v", frame.GetType())
+			}
+			if clientID != "client123" {
+				t.Errorf("Expected clientID 'client123', got %v", clientID)
+			}
+			return nil
+		}
+
+		err := container.HandleCloseFrame(context.Background(), mockFrame, testCallback)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if container.GetState() != domain.ContainerClosing {
+			t.Errorf("Expected state %v, got %v", domain.ContainerClosing, container.GetState())
+		}
+		if callbackCount != 1 {
+			t.Errorf("Expected 1 callback call, got %d", callbackCount)
+		}
+	})
+
+	t.Run("HandleCloseFrame_AlreadyClosing_Error", func(t *testing.T) {
+		container := NewContainer("container123", "client123")
+		container.SetState(domain.ContainerClosing)
+
+		mockFrame := mocks.NewMockFrame(t)
+
+		testCallback := func(ctx context.Context, frame domain.Frame, clientID domain.ID) error {
+			return nil
+		}
+
+		err := container.HandleCloseFrame(context.Background(), mockFrame, testCallback)
+		if err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+}
+
+func TestManager_RemoveContainer(t *testing.T) {
+	mgr := NewContainerManager()
+	ctr := mgr.CreateNewContainer(func() domain.ID { return "container123" }, "client123")
+	ctr.CreateChannel("orders.created", func() domain.ID { return "channel123" })
+	mgr.RegisterContainerToTopic("orders.created", ctr.GetID())
+
+	mgr.RemoveContainer(ctr.GetID())
+
+	if mgr.FindContainer(ctr.GetID()) != nil {
+		t.Error("Expected container to be removed")
+	}
+	if containers := mgr.FindContainersByTopic("orders.created"); len(containers) != 0 {
+		t.Errorf("Expected no container attached to topic, got %d", len(containers))
+	}
+}
+
+func TestManager_FindContainersByTopic(t *testing.T) {
+	mgr := NewContainerManager()
+	exact := mgr.CreateNewContainer(func() domain.ID { return "exact" }, "client1")
+	wildcard := mgr.CreateNewContainer(func() domain.ID { return "wildcard" }, "client2")
+	other := mgr.CreateNewContainer(func() domain.ID { return "other" }, "client3")
+
+	mgr.RegisterContainerToTopic("orders.created", exact.GetID())
+	mgr.RegisterContainerToTopic("orders.*", wildcard.GetID())
+	mgr.RegisterContainerToTopic("#", wildcard.GetID())
+	mgr.RegisterContainerToTopic("payments.*", other.GetID())
+
+	containers := mgr.FindContainersByTopic("orders.created")
+	if len(containers) != 2 {
+		t.Fatalf("Expected 2 containers, got %d", len(containers))
+	}
+	for _, ctr := range containers {
+		if ctr.GetID() == other.GetID() {
+			t.Errorf("Expected container %s not to match", other.GetID())
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package container
 
-import "github.com/hoppermq/hopper/pkg/domain"
+import (
+	"github.com/hoppermq/hopper/pkg/domain"
+)
 
+// findChannelByTopic return the channel attached to the topic, the caller hold channelsMu.
 func (ctr *Container) findChannelByTopic(topic string) domain.Channel {
 	if channelID, ok := ctr.ChannelsByTopic[topic]; ok {
 		return ctr.Channels[channelID]
@@ -10,21 +13,35 @@ func (ctr *Container) findChannelByTopic(topic string) domain.Channel {
 }
 
 func (ctr *Container) findChannelByID(id domain.ID) domain.Channel {
+	ctr.channelsMu.RLock()
+	defer ctr.channelsMu.RUnlock()
+
 	return ctr.Channels[id]
 }
 
-// FindContainersByTopic return all container attached to a topic as subscriber.
-func (mgr *Manager) FindContainersByTopic(topic string) []Container {
+// FindContainersByTopic return all container attached as subscriber to a pattern matching the topic.
+// A container matching through several patterns is returned once.
+func (mgr *Manager) FindContainersByTopic(topic string) []*Container {
+	mgr.mut.RLock()
+	defer mgr.mut.RUnlock()
 	mgr.Registry.mu.RLock()
 	defer mgr.Registry.mu.RUnlock()
 
-	var containers []Container
+	var containers []*Container
+	seen := make(map[domain.ID]struct{})
 
-	containersID := mgr.Registry.data[topic]
-	for containerID, _ := range containersID {
-		container := mgr.Containers[containerID]
-		if container != nil {
-			containers = append(containers, *container)
+	for pattern, containersID := range mgr.Registry.data {
+		if !domain.MatchTopic(pattern, topic) {
+			continue
+		}
+		for containerID := range containersID {
+			if _, ok := seen[containerID]; ok {
+				continue
+			}
+			seen[containerID] = struct{}{}
+			if container := mgr.Containers[containerID]; container != nil {
+				containers = append(containers, container)
+			}
 		}
 	}
 
@@ -42,7 +42,10 @@ func (mgr *Manager) CreateNewContainer(
 	clientID domain.ID,
 ) *Container {
 	container := NewContainer(idGenerator(), clientID)
+
+	mgr.mut.Lock()
 	mgr.Containers[container.ID] = container
+	mgr.mut.Unlock()
 
 	container.SetState(domain.ContainerCreated)
 	return container
@@ -73,6 +76,21 @@ func (rContainer *Registry) Unregister(topic string, id domain.ID) {
 	}
 }
 
+// Snapshot return a copy of the registry, the containers attached to every topic.
+func (rContainer *Registry) Snapshot() map[string][]domain.ID {
+	rContainer.mu.RLock()
+	defer rContainer.mu.RUnlock()
+
+	snapshot := make(map[string][]domain.ID, len(rContainer.data))
+	for topic, set := range rContainer.data {
+		for id := range set {
+			snapshot[topic] = append(snapshot[topic], id)
+		}
+	}
+
+	return snapshot
+}
+
 // RegisterContainerToTopic set a container to the registry attached to a topic.
 func (mgr *Manager) RegisterContainerToTopic(
 	topic string,
@@ -88,3 +106,19 @@ func (mgr *Manager) RemoveContainerFromTopic(
 ) {
 	mgr.Registry.Unregister(topic, containerID)
 }
+
+// RemoveContainer detach the container from all its topics and remove it from the orchestrator.
+func (mgr *Manager) RemoveContainer(containerID domain.ID) {
+	mgr.mut.Lock()
+	defer mgr.mut.Unlock()
+
+	ctr, ok := mgr.Containers[containerID]
+	if !ok {
+		return
+	}
+
+	for _, topic := range ctr.Topics() {
+		mgr.Registry.Unregister(topic, containerID)
+	}
+	delete(mgr.Containers, containerID)
+}
@@ -4,5 +4,10 @@ import "github.com/hoppermq/hopper/pkg/domain"
 
 // UpdateContainerState will update the container state to a new state.
 func (manager *Manager) UpdateContainerState(containerID domain.ID, newState domain.ContainerState) {
-	manager.Containers[containerID].SetState(newState)
+	manager.mut.RLock()
+	defer manager.mut.RUnlock()
+
+	if ctr, ok := manager.Containers[containerID]; ok {
+		ctr.SetState(newState)
+	}
 }
@@ -2,10 +2,13 @@ package container
 
 import "github.com/hoppermq/hopper/pkg/domain"
 
-// FindContainerByClientID return the given container.
+// FindContainerByClientID return the container owned by the given client.
 func (mgr *Manager) FindContainerByClientID(clientID domain.ID) *Container {
+	mgr.mut.RLock()
+	defer mgr.mut.RUnlock()
+
 	for _, ctr := range mgr.Containers {
-		if ctr.GetID() == clientID {
+		if ctr.GetClientID() == clientID {
 			return ctr
 		}
 	}
@@ -15,9 +18,25 @@ func (mgr *Manager) FindContainerByClientID(clientID domain.ID) *Container {
 
 // FindContainer return the container associated to the client.
 func (mgr *Manager) FindContainer(containerID domain.ID) *Container {
+	mgr.mut.RLock()
+	defer mgr.mut.RUnlock()
+
 	if ctr, ok := mgr.Containers[containerID]; ok {
 		return ctr
 	}
 
 	return nil
 }
+
+// FindContainers return a snapshot of every container of the orchestrator.
+func (mgr *Manager) FindContainers() []*Container {
+	mgr.mut.RLock()
+	defer mgr.mut.RUnlock()
+
+	containers := make([]*Container, 0, len(mgr.Containers))
+	for _, ctr := range mgr.Containers {
+		containers = append(containers, ctr)
+	}
+
+	return containers
+}
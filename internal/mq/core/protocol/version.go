@@ -0,0 +1,26 @@
+package protocol
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// SupportedVersions list the protocol versions spoken by the broker, newest first.
+var SupportedVersions = []domain.ProtocolVersion{
+	domain.ProtocolVersion1,
+}
+
+// BrokerCapabilities is the set of capabilities the broker advertise in the Open frame.
+const BrokerCapabilities = domain.CapabilityLargeFrames | domain.CapabilityConfirms
+
+// Negotiate validate the version requested by a client and return the capabilities
+// both peers agreed on, the intersection of the requested and advertised sets.
+func Negotiate(version domain.ProtocolVersion, requested domain.Capability) (domain.Capability, error) {
+	if !slices.Contains(SupportedVersions, version) {
+		return 0, fmt.Errorf("%w: %d (supported: %v)", domain.ErrUnsupportedVersion, version, SupportedVersions)
+	}
+
+	return requested & BrokerCapabilities, nil
+}
@@ -0,0 +1,58 @@
+// Package retention keep the last routed messages in memory so stream consumers can resume.
+package retention
+
+import (
+	"sync"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Log is a fixed size in-memory message log, the oldest messages are evicted first.
+type Log struct {
+	mu       sync.RWMutex
+	messages []domain.Delivery
+	next     int
+	size     int
+	offset   uint64
+}
+
+// NewLog return a log retaining up to capacity messages.
+func NewLog(capacity int) *Log {
+	return &Log{
+		messages: make([]domain.Delivery, max(capacity, 1)),
+	}
+}
+
+// Append record the message and return its offset.
+func (l *Log) Append(msg domain.Delivery) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.offset++
+	msg.Offset = l.offset
+	l.messages[l.next] = msg
+	l.next = (l.next + 1) % len(l.messages)
+	l.size = min(l.size+1, len(l.messages))
+
+	return l.offset
+}
+
+// ReadAfter return the retained messages with an offset above the given one, oldest first.
+// Messages evicted from the log are silently skipped.
+func (l *Log) ReadAfter(offset uint64) []domain.Delivery {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if offset >= l.offset {
+		return nil
+	}
+
+	n := min(uint64(l.size), l.offset-offset)
+	messages := make([]domain.Delivery, 0, n)
+	start := (l.next - int(n) + len(l.messages)) % len(l.messages)
+	for i := range int(n) {
+		messages = append(messages, l.messages[(start+i)%len(l.messages)])
+	}
+
+	return messages
+}
@@ -0,0 +1,42 @@
+package retention
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func TestLog_ReadAfter(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(3)
+	for _, id := range []domain.ID{"m1", "m2", "m3", "m4"} {
+		log.Append(domain.Delivery{MessageID: id})
+	}
+
+	tests := []struct {
+		name   string
+		offset uint64
+		want   []domain.ID
+	}{
+		{name: "From_Start_Skip_Evicted", offset: 0, want: []domain.ID{"m2", "m3", "m4"}},
+		{name: "After_Offset", offset: 2, want: []domain.ID{"m3", "m4"}},
+		{name: "Up_To_Date", offset: 4, want: nil},
+		{name: "Future_Offset", offset: 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []domain.ID
+			for _, msg := range log.ReadAfter(tt.offset) {
+				got = append(got, msg.MessageID)
+				assert.Greater(t, msg.Offset, tt.offset)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package subscription
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+const (
+	defaultAckTimeout = 30 * time.Second
+	defaultMaxPending = 10000
+)
+
+// Manager hold the named subscriptions and the streams, and fan the published messages out to them.
+type Manager struct {
+	mu   sync.RWMutex
+	subs map[string]*Subscription
+
+	streams streams
+
+	ackTimeout time.Duration
+	maxPending int
+	now        func() time.Time
+	generateID func() domain.ID
+}
+
+// Option configure the manager.
+type Option func(*Manager)
+
+// WithAckTimeout set how long a delivery stay in flight before being redelivered.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		if timeout > 0 {
+			m.ackTimeout = timeout
+		}
+	}
+}
+
+// WithMaxPending bound the pending messages of each subscription, 0 disable the bound.
+func WithMaxPending(maxPending int) Option {
+	return func(m *Manager) {
+		m.maxPending = maxPending
+	}
+}
+
+// WithClock set the clock used to expire the in-flight deliveries.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+// WithIDGenerator set the generator of the delivery ids.
+func WithIDGenerator(generateID func() domain.ID) Option {
+	return func(m *Manager) {
+		m.generateID = generateID
+	}
+}
+
+// NewManager return a new subscription manager.
+func NewManager(opts ...Option) *Manager {
+	m := &Manager{
+		subs:       make(map[string]*Subscription),
+		streams:    streams{open: make(map[*Stream]struct{})},
+		ackTimeout: defaultAckTimeout,
+		maxPending: defaultMaxPending,
+		now:        time.Now,
+		generateID: common.GenerateIdentifier,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Create bind a new subscription owned by the identity to the topic pattern.
+// Creating an existing subscription on the same topic return it, on another topic ErrSubscriptionConflict
+// and owned by another identity ErrForbidden.
+func (m *Manager) Create(name, topic, owner string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sub, ok := m.subs[name]; ok {
+		if sub.Owner != owner {
+			return nil, fmt.Errorf("%w: %q is owned by another identity", domain.ErrForbidden, name)
+		}
+		if sub.Topic != topic {
+			return nil, fmt.Errorf("%w: %q is bound to %q", domain.ErrSubscriptionConflict, name, sub.Topic)
+		}
+		return sub, nil
+	}
+
+	sub := newSubscription(name, topic, owner, m)
+	m.subs[name] = sub
+
+	return sub, nil
+}
+
+// Get return the subscription, ErrSubscriptionNotFound when it does not exist.
+func (m *Manager) Get(name string) (*Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sub, ok := m.subs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", domain.ErrSubscriptionNotFound, name)
+	}
+
+	return sub, nil
+}
+
+// Delete remove the subscription and its messages.
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subs[name]; !ok {
+		return fmt.Errorf("%w: %q", domain.ErrSubscriptionNotFound, name)
+	}
+	delete(m.subs, name)
+
+	return nil
+}
+
+// Publish queue the message on every subscription and stream whose pattern match its topic.
+// It return the number of subscriptions the message was queued on.
+func (m *Manager) Publish(msg domain.Delivery) int {
+	m.publishStreams(msg)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := 0
+	for _, sub := range m.subs {
+		if domain.MatchTopic(sub.Topic, msg.Topic) {
+			sub.Push(msg)
+			matched++
+		}
+	}
+
+	return matched
+}
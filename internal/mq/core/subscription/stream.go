@@ -0,0 +1,89 @@
+package subscription
+
+import (
+	"sync"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Stream receive the messages published on its topic patterns as long as its consumer keep up.
+type Stream struct {
+	Topics []string
+
+	messages chan domain.Delivery
+	mgr      *Manager
+}
+
+// Messages return the channel of the stream messages, closed when the stream is closed.
+func (s *Stream) Messages() <-chan domain.Delivery {
+	return s.messages
+}
+
+// Match return true when one of the stream patterns match the topic.
+func (s *Stream) Match(topic string) bool {
+	for _, pattern := range s.Topics {
+		if domain.MatchTopic(pattern, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Close detach the stream from the manager and close its channel.
+func (s *Stream) Close() {
+	s.mgr.closeStream(s)
+}
+
+// streams hold the open streams, a stream is closed when its buffer is full.
+type streams struct {
+	mu   sync.RWMutex
+	open map[*Stream]struct{}
+}
+
+// OpenStream attach a new stream on the topic patterns, buffering up to buffer messages.
+func (m *Manager) OpenStream(topics []string, buffer int) *Stream {
+	s := &Stream{
+		Topics:   topics,
+		messages: make(chan domain.Delivery, max(buffer, 1)),
+		mgr:      m,
+	}
+
+	m.streams.mu.Lock()
+	m.streams.open[s] = struct{}{}
+	m.streams.mu.Unlock()
+
+	return s
+}
+
+// publishStreams push the message to the matching streams and close the streams falling behind.
+func (m *Manager) publishStreams(msg domain.Delivery) {
+	var slow []*Stream
+
+	m.streams.mu.RLock()
+	for s := range m.streams.open {
+		if !s.Match(msg.Topic) {
+			continue
+		}
+		select {
+		case s.messages <- msg:
+		default:
+			slow = append(slow, s)
+		}
+	}
+	m.streams.mu.RUnlock()
+
+	for _, s := range slow {
+		m.closeStream(s)
+	}
+}
+
+func (m *Manager) closeStream(s *Stream) {
+	m.streams.mu.Lock()
+	defer m.streams.mu.Unlock()
+
+	if _, ok := m.streams.open[s]; ok {
+		delete(m.streams.open, s)
+		close(s.messages)
+	}
+}
@@ -0,0 +1,195 @@
+// Package subscription hold the named subscriptions consumed by pulling clients, e.g. the http gateway.
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// inflight is a delivery waiting for its ack.
+type inflight struct {
+	delivery domain.Delivery
+	deadline time.Time
+}
+
+// Subscription buffer the messages published on a topic pattern until a consumer pull and ack them.
+type Subscription struct {
+	Name  string
+	Topic string
+	// Owner is the identity which created the subscription, the only one allowed to consume and delete it.
+	Owner string
+
+	mu       sync.Mutex
+	pending  []domain.Delivery
+	inflight map[domain.ID]*inflight
+	notify   chan struct{}
+
+	ackTimeout time.Duration
+	maxPending int
+	now        func() time.Time
+	generateID func() domain.ID
+}
+
+func newSubscription(name, topic, owner string, mgr *Manager) *Subscription {
+	return &Subscription{
+		Name:       name,
+		Topic:      topic,
+		Owner:      owner,
+		inflight:   make(map[domain.ID]*inflight),
+		notify:     make(chan struct{}),
+		ackTimeout: mgr.ackTimeout,
+		maxPending: mgr.maxPending,
+		now:        mgr.now,
+		generateID: mgr.generateID,
+	}
+}
+
+// Push queue the message for the consumers, the oldest pending message is dropped when the queue is full.
+// It return false when a message was dropped.
+func (s *Subscription) Push(msg domain.Delivery) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := false
+	if s.maxPending > 0 && len(s.pending) >= s.maxPending {
+		s.pending = s.pending[1:]
+		dropped = true
+	}
+	s.pending = append(s.pending, msg)
+	s.wake()
+
+	return !dropped
+}
+
+// Pull return up to max deliveries, waiting up to wait for the first one when none is pending.
+// The deliveries stay in flight until acked or until the ack timeout expire.
+func (s *Subscription) Pull(ctx context.Context, max int, wait time.Duration) []domain.Delivery {
+	if max <= 0 {
+		max = 1
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		deliveries, notify, next := s.take(max)
+		if len(deliveries) > 0 || wait <= 0 {
+			return deliveries
+		}
+
+		if s.wait(ctx, timeout.C, notify, next) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		deliveries, _, _ = s.take(max)
+		return deliveries
+	}
+}
+
+// wait block until a message is pushed or an in-flight delivery expire, returning true,
+// or until the pull timeout or the context end, returning false.
+func (s *Subscription) wait(ctx context.Context, timeout <-chan time.Time, notify <-chan struct{}, next time.Duration) bool {
+	var redelivery <-chan time.Time
+	if next > 0 {
+		timer := time.NewTimer(next)
+		defer timer.Stop()
+		redelivery = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timeout:
+		return false
+	case <-notify:
+		return true
+	case <-redelivery:
+		return true
+	}
+}
+
+// Ack settle the in-flight deliveries, the known ones are settled even when some are unknown.
+func (s *Subscription) Ack(deliveryIDs ...domain.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unknown []domain.ID
+	for _, id := range deliveryIDs {
+		if _, ok := s.inflight[id]; !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+		delete(s.inflight, id)
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %v", domain.ErrUnknownDelivery, unknown)
+	}
+
+	return nil
+}
+
+// Len return the number of pending and in-flight messages.
+func (s *Subscription) Len() (pending, inflight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending), len(s.inflight)
+}
+
+// take move up to max pending messages in flight, after requeuing the expired deliveries.
+// It return the channel notified on the next push and the delay before the next expiry, 0 when none.
+func (s *Subscription) take(max int) ([]domain.Delivery, <-chan struct{}, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.requeueExpired(now)
+
+	n := min(max, len(s.pending))
+	deliveries := make([]domain.Delivery, 0, n)
+	for _, msg := range s.pending[:n] {
+		msg.ID = s.generateID()
+		msg.Attempt++
+		s.inflight[msg.ID] = &inflight{delivery: msg, deadline: now.Add(s.ackTimeout)}
+		deliveries = append(deliveries, msg)
+	}
+	s.pending = s.pending[n:]
+
+	var next time.Duration
+	for _, msg := range s.inflight {
+		if delay := msg.deadline.Sub(now); next == 0 || delay < next {
+			next = delay
+		}
+	}
+
+	return deliveries, s.notify, next
+}
+
+// requeueExpired put the deliveries past their ack deadline back at the head of the queue.
+func (s *Subscription) requeueExpired(now time.Time) {
+	var expired []domain.Delivery
+	for id, msg := range s.inflight {
+		if !now.Before(msg.deadline) {
+			expired = append(expired, msg.delivery)
+			delete(s.inflight, id)
+		}
+	}
+
+	if len(expired) > 0 {
+		s.pending = append(expired, s.pending...)
+	}
+}
+
+// wake notify the waiting consumers.
+func (s *Subscription) wake() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
@@ -0,0 +1,182 @@
+package subscription
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func sequence() func() domain.ID {
+	n := 0
+	return func() domain.ID {
+		n++
+		return domain.ID("d" + strconv.Itoa(n))
+	}
+}
+
+func TestManager_Create(t *testing.T) {
+	t.Parallel()
+
+	mgr := NewManager()
+
+	sub, err := mgr.Create("billing", "orders.#", "")
+	require.NoError(t, err)
+
+	again, err := mgr.Create("billing", "orders.#", "")
+	require.NoError(t, err)
+	assert.Same(t, sub, again)
+
+	_, err = mgr.Create("billing", "payments.#", "")
+	assert.ErrorIs(t, err, domain.ErrSubscriptionConflict)
+
+	_, err = mgr.Create("billing", "orders.#", "mallory")
+	assert.ErrorIs(t, err, domain.ErrForbidden, "Another identity should not take over the subscription")
+
+	require.NoError(t, mgr.Delete("billing"))
+	_, err = mgr.Get("billing")
+	assert.ErrorIs(t, err, domain.ErrSubscriptionNotFound)
+	assert.ErrorIs(t, mgr.Delete("billing"), domain.ErrSubscriptionNotFound)
+}
+
+func TestManager_Publish(t *testing.T) {
+	t.Parallel()
+
+	mgr := NewManager()
+	orders, err := mgr.Create("orders", "orders.*", "")
+	require.NoError(t, err)
+	all, err := mgr.Create("all", "#", "")
+	require.NoError(t, err)
+	_, err = mgr.Create("payments", "payments.*", "")
+	require.NoError(t, err)
+
+	matched := mgr.Publish(domain.Delivery{MessageID: "m1", Topic: "orders.created"})
+	assert.Equal(t, 2, matched)
+
+	pending, _ := orders.Len()
+	assert.Equal(t, 1, pending)
+	pending, _ = all.Len()
+	assert.Equal(t, 1, pending)
+}
+
+func TestSubscription_PullAck(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	mgr := NewManager(WithClock(clock.Now), WithIDGenerator(sequence()), WithAckTimeout(time.Second))
+	sub, err := mgr.Create("orders", "orders.#", "")
+	require.NoError(t, err)
+
+	for _, id := range []domain.ID{"m1", "m2", "m3"} {
+		sub.Push(domain.Delivery{MessageID: id, Topic: "orders.created"})
+	}
+
+	deliveries := sub.Pull(context.Background(), 2, 0)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, domain.ID("m1"), deliveries[0].MessageID)
+	assert.Equal(t, domain.ID("d1"), deliveries[0].ID)
+	assert.Equal(t, 1, deliveries[0].Attempt)
+
+	require.NoError(t, sub.Ack("d1"))
+	assert.ErrorIs(t, sub.Ack("d1"), domain.ErrUnknownDelivery)
+
+	clock.now = clock.now.Add(time.Second)
+
+	deliveries = sub.Pull(context.Background(), 10, 0)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, domain.ID("m2"), deliveries[0].MessageID, "expired delivery is redelivered first")
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, domain.ID("m3"), deliveries[1].MessageID)
+
+	pending, inflight := sub.Len()
+	assert.Equal(t, 0, pending)
+	assert.Equal(t, 2, inflight)
+}
+
+func TestSubscription_Push_Drop_Oldest(t *testing.T) {
+	t.Parallel()
+
+	mgr := NewManager(WithMaxPending(2))
+	sub, err := mgr.Create("orders", "orders", "")
+	require.NoError(t, err)
+
+	assert.True(t, sub.Push(domain.Delivery{MessageID: "m1"}))
+	assert.True(t, sub.Push(domain.Delivery{MessageID: "m2"}))
+	assert.False(t, sub.Push(domain.Delivery{MessageID: "m3"}))
+
+	deliveries := sub.Pull(context.Background(), 10, 0)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, domain.ID("m2"), deliveries[0].MessageID)
+}
+
+func TestSubscription_Pull_LongPoll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		push    bool
+		wait    time.Duration
+		wantLen int
+	}{
+		{name: "Wakeup_On_Push", push: true, wait: 5 * time.Second, wantLen: 1},
+		{name: "Timeout_Without_Message", wait: 20 * time.Millisecond, wantLen: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sub, err := NewManager().Create("orders", "orders", "")
+			require.NoError(t, err)
+
+			if tt.push {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					sub.Push(domain.Delivery{MessageID: "m1"})
+				}()
+			}
+
+			start := time.Now()
+			deliveries := sub.Pull(context.Background(), 1, tt.wait)
+			assert.Len(t, deliveries, tt.wantLen)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func TestManager_OpenStream(t *testing.T) {
+	t.Parallel()
+
+	mgr := NewManager()
+	orders := mgr.OpenStream([]string{"orders.*", "payments.done"}, 1)
+	slow := mgr.OpenStream([]string{"#"}, 1)
+
+	mgr.Publish(domain.Delivery{MessageID: "m1", Topic: "orders.created"})
+	mgr.Publish(domain.Delivery{MessageID: "m2", Topic: "audit.login"})
+
+	msg := <-orders.Messages()
+	assert.Equal(t, domain.ID("m1"), msg.MessageID)
+
+	msg = <-slow.Messages()
+	assert.Equal(t, domain.ID("m1"), msg.MessageID)
+	_, ok := <-slow.Messages()
+	assert.False(t, ok, "stream falling behind is closed")
+
+	orders.Close()
+	orders.Close()
+	_, ok = <-orders.Messages()
+	assert.False(t, ok)
+}
@@ -15,7 +15,7 @@ type HopperMQService struct {
 	eb domain.IEventBus
 
 	broker     domain.IService
-	tcpHandler domain.Transport
+	transports []domain.Transport
 
 	logger *slog.Logger
 
@@ -40,10 +40,10 @@ func WithBroker(broker domain.IService) Option {
 	}
 }
 
-// WithTransport inject transport layer.
-func WithTransport(transport domain.Transport) Option {
+// WithTransport inject transport layers.
+func WithTransport(transports ...domain.Transport) Option {
 	return func(s *HopperMQService) {
-		s.tcpHandler = transport
+		s.transports = append(s.transports, transports...)
 	}
 }
 
@@ -107,19 +107,20 @@ func (h *HopperMQService) Run(ctx context.Context) error {
 func (h *HopperMQService) Stop(ctx context.Context) error {
 	h.logger.Info("Stopping services")
 
-	if h.cancel != nil {
-		h.cancel()
+	// the broker is stopped first so close frames can still flow through the transport.
+	if h.broker != nil {
+		if err := h.broker.Stop(ctx); err != nil {
+			h.logger.Error("Failed to stop broker")
+		}
 	}
 
-	if h.tcpHandler != nil {
-		if err := h.tcpHandler.Stop(ctx); err != nil {
-			h.logger.Error("Failed to stop TCP HAndler")
-		}
+	if h.cancel != nil {
+		h.cancel()
 	}
 
-	if h.broker != nil {
-		if err := h.broker.Stop(ctx); err != nil {
-			h.logger.Error("Failed to stop broker")
+	for _, transport := range h.transports {
+		if err := transport.Stop(ctx); err != nil {
+			h.logger.Error("Failed to stop transport", "error", err)
 		}
 	}
 
@@ -134,7 +135,7 @@ func (h *HopperMQService) RegisterEventBus(bus domain.IEventBus) {
 	if h.broker != nil {
 		h.broker.RegisterEventBus(bus)
 	}
-	if h.tcpHandler != nil {
-		h.tcpHandler.RegisterEventBus(bus)
+	for _, transport := range h.transports {
+		transport.RegisterEventBus(bus)
 	}
 }
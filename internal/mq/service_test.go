@@ -62,7 +62,7 @@ func TestCreateService(t *testing.T) {
 				return assert.Equal(t, "hopper-mq", service.Name()) &&
 					assert.NotNil(t, service.logger) &&
 					assert.Nil(t, service.broker) &&
-					assert.Nil(t, service.tcpHandler)
+					assert.Empty(t, service.transports)
 			},
 		},
 		{
@@ -76,7 +76,7 @@ func TestCreateService(t *testing.T) {
 				return assert.Equal(t, "hopper-mq", service.Name()) &&
 					assert.Nil(t, service.broker) &&
 					assert.Nil(t, service.logger) &&
-					assert.Nil(t, service.tcpHandler)
+					assert.Empty(t, service.transports)
 			},
 		},
 	}
@@ -116,7 +116,7 @@ func TestHopperMQService_RegisterEventBus(t *testing.T) {
 					mockTransport := mocks.NewMockTransport(t)
 					return &HopperMQService{
 						broker:     mockBroker,
-						tcpHandler: mockTransport,
+						transports: []domain.Transport{mockTransport},
 					}
 				}(),
 				eb: mocks.NewMockIEventBus(t),
@@ -125,7 +125,7 @@ func TestHopperMQService_RegisterEventBus(t *testing.T) {
 			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
 				args := value.(args)
 				mockBroker := args.service.broker.(*mocks.MockIService)
-				mockTransport := args.service.tcpHandler.(*mocks.MockTransport)
+				mockTransport := args.service.transports[0].(*mocks.MockTransport)
 				mockEB := args.eb.(*mocks.MockIEventBus)
 
 				mockBroker.EXPECT().RegisterEventBus(mockEB).Once()
@@ -140,7 +140,7 @@ func TestHopperMQService_RegisterEventBus(t *testing.T) {
 			args: args{
 				service: &HopperMQService{
 					broker:     nil,
-					tcpHandler: nil,
+					transports: nil,
 				},
 				eb: mocks.NewMockIEventBus(t),
 			},
@@ -189,7 +189,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 					logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 					return &HopperMQService{
 						broker:     mockBroker,
-						tcpHandler: mockTransport,
+						transports: []domain.Transport{mockTransport},
 						eb:         mockEB,
 						logger:     logger,
 					}
@@ -200,7 +200,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
 				args := value.(args)
 				mockBroker := args.service.broker.(*mocks.MockIService)
-				mockTransport := args.service.tcpHandler.(*mocks.MockTransport)
+				mockTransport := args.service.transports[0].(*mocks.MockTransport)
 
 				mockBroker.EXPECT().Run(mock.Anything).Return(nil).Once()
 				mockTransport.EXPECT().Stop(mock.Anything).Return(nil).Once()
@@ -235,7 +235,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 					logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 					return &HopperMQService{
 						broker:     mockBroker,
-						tcpHandler: mockTransport,
+						transports: []domain.Transport{mockTransport},
 						eb:         mockEB,
 						logger:     logger,
 					}
@@ -246,7 +246,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
 				args := value.(args)
 				mockBroker := args.service.broker.(*mocks.MockIService)
-				mockTransport := args.service.tcpHandler.(*mocks.MockTransport)
+				mockTransport := args.service.transports[0].(*mocks.MockTransport)
 
 				mockBroker.EXPECT().Run(mock.Anything).Return(errors.New("broker failed")).Once()
 				mockTransport.EXPECT().Stop(mock.Anything).Return(nil).Once()
@@ -280,7 +280,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 					logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 					return &HopperMQService{
 						broker:     mockBroker,
-						tcpHandler: mockTransport,
+						transports: []domain.Transport{mockTransport},
 						logger:     logger,
 					}
 				}(),
@@ -290,7 +290,7 @@ func TestHopperMQService_RunAndStop(t *testing.T) {
 			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
 				args := value.(args)
 				mockBroker := args.service.broker.(*mocks.MockIService)
-				mockTransport := args.service.tcpHandler.(*mocks.MockTransport)
+				mockTransport := args.service.transports[0].(*mocks.MockTransport)
 
 				mockTransport.EXPECT().Stop(mock.Anything).Return(errors.New("transport stop failed")).Once()
 				mockBroker.EXPECT().Stop(mock.Anything).Return(errors.New("broker stop failed")).Once()
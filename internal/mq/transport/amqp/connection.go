@@ -0,0 +1,945 @@
+package amqp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+const (
+	clientVersion = "amqp/1.0"
+
+	mechanismPlain     Symbol = "PLAIN"
+	mechanismAnonymous Symbol = "ANONYMOUS"
+
+	// channelMax is the highest channel, i.e. the number of sessions, a client may use.
+	channelMax uint16 = 255
+	// sessionWindow is the number of transfer frames a client may send before the session window is renewed.
+	sessionWindow uint32 = 65535
+	// linkCredit is the number of messages a client may send on a link before the credit is renewed.
+	linkCredit uint32 = 256
+	// maxQueued bound the messages waiting for credit on a link, the oldest are dropped.
+	maxQueued = 1024
+
+	// snd-settle-mode settled, the broker send every message settled.
+	sndSettleModeSettled uint8 = 1
+)
+
+var (
+	// ErrInvalidAddress is returned when a link or message address is not a valid HopperMQ topic.
+	ErrInvalidAddress = errors.New("invalid amqp address")
+
+	// errConnectionClosed end a connection without error, e.g. on Close.
+	errConnectionClosed = errors.New("connection closed")
+)
+
+// outbound is a frame sent by the broker to the connection.
+type outbound struct {
+	data   []byte
+	onSent func(err error)
+}
+
+// delivery is a message routed by the broker waiting for credit on a link.
+type delivery struct {
+	topic     string
+	messageID domain.ID
+	content   []byte
+	headers   map[string]string
+}
+
+// link is an attached link, the broker is the receiver when the client send messages.
+type link struct {
+	name     string
+	handle   uint32
+	receiver bool
+	// address is the topic messages are published on for a receiver, the topic pattern for a sender.
+	address string
+
+	deliveryCount uint32
+	credit        uint32
+	drain         bool
+	queue         []delivery
+
+	// the frames of a transfer split over several frames.
+	transfer   []byte
+	deliveryID uint32
+	settled    bool
+	assembling bool
+}
+
+// session is a begun session, the client channel is used on both sides.
+type session struct {
+	channel uint16
+
+	nextIncomingID   uint32
+	incomingWindow   uint32
+	nextOutgoingID   uint32
+	remoteIncoming   uint32
+	nextDeliveryID   uint32
+	links            map[uint32]*link
+	incomingSinceAck uint32
+}
+
+// connection translate the performatives of an AMQP 1.0 client to HopperMQ frames and back.
+//
+// The broker sees the connection as a regular HopperMQ client: the SASL PLAIN credentials are sent in
+// an Auth frame, the connection is accepted once the broker answer the Connect frame with a Begin frame.
+// A link attached as receiver by the client subscribe its source address, a topic pattern, the messages
+// transferred on a link attached as sender are published on its target address, or on the "to" property
+// of the message when the target has no address.
+type connection struct {
+	handler *AMQP
+	conn    net.Conn
+	reader  *bufio.Reader
+
+	sasl bool
+	// brokerHandshake answer the Open frame of the broker, with the SASL PLAIN credentials when given.
+	brokerHandshake protocol.ClientHandshake
+
+	clientID    domain.ID
+	containerID domain.ID
+
+	opened       bool
+	maxFrameSize uint32
+	heartbeat    time.Duration
+
+	sessions      map[uint16]*session
+	subscriptions map[string]int // links by HopperMQ topic pattern
+
+	frames chan outbound
+	done   chan struct{}
+}
+
+func newConnection(handler *AMQP, conn net.Conn) *connection {
+	return &connection{
+		handler: handler,
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		brokerHandshake: protocol.ClientHandshake{
+			ClientVersion: clientVersion,
+			Capabilities:  domain.CapabilityLargeFrames,
+		},
+		maxFrameSize:  minMaxFrameSize,
+		sessions:      make(map[uint16]*session),
+		subscriptions: make(map[string]int),
+		frames:        make(chan outbound, 64), //nolint:mnd
+		done:          make(chan struct{}),
+	}
+}
+
+// negotiate read the protocol header and, for SASL connections, the SASL init frame.
+func (c *connection) negotiate() error {
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.handler.connectTimeout))
+	defer func() { _ = c.conn.SetReadDeadline(time.Time{}) }()
+
+	protocolID, err := readProtocolHeader(c.reader)
+	if err != nil {
+		return err
+	}
+
+	switch protocolID {
+	case protocolAMQP:
+		return writeProtocolHeader(c.conn, protocolAMQP)
+	case protocolSASL:
+		c.sasl = true
+	default:
+		_ = writeProtocolHeader(c.conn, protocolSASL)
+		return fmt.Errorf("%w: protocol id %d", ErrInvalidProtocolHeader, protocolID)
+	}
+
+	if err := writeProtocolHeader(c.conn, protocolSASL); err != nil {
+		return err
+	}
+	if err := c.send(frameTypeSASL, 0, descriptorSASLMechanisms, Array{mechanismPlain, mechanismAnonymous}); err != nil {
+		return err
+	}
+
+	f, err := readFrame(c.reader, c.handler.maxFrameSize)
+	if err != nil {
+		return err
+	}
+	p, _, err := decodePerformative(f.body)
+	if err != nil {
+		return err
+	}
+	if f.kind != frameTypeSASL || p.descriptor != descriptorSASLInit {
+		return fmt.Errorf("%w: expected sasl-init, got 0x%02x", ErrInvalidEncoding, p.descriptor)
+	}
+
+	mechanism := Symbol(p.fields.string(0))
+	if mechanism != mechanismPlain && mechanism != mechanismAnonymous {
+		_ = c.send(frameTypeSASL, 0, descriptorSASLOutcome, saslAuth)
+		return fmt.Errorf("unsupported sasl mechanism %q", mechanism)
+	}
+	if mechanism == mechanismPlain {
+		c.brokerHandshake.Mechanism = domain.AuthMechanismPlain
+		c.brokerHandshake.Response, _ = p.fields.get(1).([]byte)
+	}
+
+	return nil
+}
+
+// handshake connect the client to the broker, then complete the SASL exchange.
+func (c *connection) handshake(ctx context.Context) error {
+	timeout := time.NewTimer(c.handler.connectTimeout)
+	defer timeout.Stop()
+
+	err := c.awaitBroker(ctx, timeout.C)
+	if err != nil {
+		c.refuse(err)
+		return err
+	}
+
+	if !c.sasl {
+		return nil
+	}
+
+	if err := c.send(frameTypeSASL, 0, descriptorSASLOutcome, saslOK); err != nil {
+		return err
+	}
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.handler.connectTimeout))
+	defer func() { _ = c.conn.SetReadDeadline(time.Time{}) }()
+
+	protocolID, err := readProtocolHeader(c.reader)
+	if err != nil {
+		return err
+	}
+	if protocolID != protocolAMQP {
+		return fmt.Errorf("%w: protocol id %d after sasl", ErrInvalidProtocolHeader, protocolID)
+	}
+
+	return writeProtocolHeader(c.conn, protocolAMQP)
+}
+
+// awaitBroker answer the Open frame of the broker and wait for the Begin frame accepting the client.
+func (c *connection) awaitBroker(ctx context.Context, timeout <-chan time.Time) error {
+	for {
+		var out outbound
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return errors.New("handshake with the broker timed out")
+		case out = <-c.frames:
+		}
+
+		accepted, err := c.handleHandshakeFrame(ctx, out.data)
+		if out.onSent != nil {
+			out.onSent(err)
+		}
+		if err != nil || accepted {
+			return err
+		}
+	}
+}
+
+func (c *connection) handleHandshakeFrame(ctx context.Context, data []byte) (bool, error) {
+	frame, err := c.handler.serializer.DeserializeFrame(data)
+	if err != nil {
+		return false, err
+	}
+
+	switch payload := frame.GetPayload().(type) {
+	case domain.OpenFramePayload:
+		c.clientID = payload.GetSourceID()
+		c.containerID = payload.GetAssignedContainerID()
+		return false, c.brokerHandshake.Answer(payload, func(frame domain.Frame) error {
+			return c.sendFrame(ctx, frame)
+		})
+	case domain.BeginFramePayload:
+		return true, nil
+	case domain.ErrorFramePayload:
+		if c.brokerHandshake.Tolerate(payload) {
+			return false, nil
+		}
+		return false, &brokerError{code: domain.ErrorCode(payload.GetErrorCode()), message: payload.GetErrorMessage()}
+	case domain.CloseFramePayload:
+		return false, &brokerError{message: payload.GetReason()}
+	}
+
+	return false, nil
+}
+
+// brokerError is an Error or Close frame refusing the client.
+type brokerError struct {
+	code    domain.ErrorCode
+	message string
+}
+
+func (e *brokerError) Error() string {
+	return fmt.Sprintf("connection refused by the broker: %s", e.message)
+}
+
+// refuse report the failed handshake, with the SASL outcome or an Open followed by a Close.
+func (c *connection) refuse(err error) {
+	var refused *brokerError
+	unauthorized := errors.As(err, &refused) &&
+		(refused.code == domain.ErrorCodeUnauthorized || refused.code == domain.ErrorCodeForbidden)
+
+	if c.sasl {
+		code := saslSys
+		if unauthorized {
+			code = saslAuth
+		}
+		_ = c.send(frameTypeSASL, 0, descriptorSASLOutcome, code)
+		return
+	}
+
+	condition := conditionInternalError
+	if unauthorized {
+		condition = conditionUnauthorized
+	}
+	_ = c.send(frameTypeAMQP, 0, descriptorOpen, string(c.containerID))
+	_ = c.send(frameTypeAMQP, 0, descriptorClose, amqpError(condition, err.Error()))
+}
+
+// run serve the connection until the client or the broker close it.
+func (c *connection) run(ctx context.Context) error {
+	defer close(c.done)
+
+	incoming := make(chan *frame)
+	readErr := make(chan error, 1)
+	go c.readLoop(ctx, incoming, readErr)
+
+	var ticker *time.Ticker
+	var heartbeat <-chan time.Time
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-readErr:
+		case f := <-incoming:
+			err = c.handleFrame(ctx, f)
+			if c.heartbeat > 0 && ticker == nil {
+				ticker = time.NewTicker(c.heartbeat)
+				heartbeat = ticker.C
+			}
+		case out := <-c.frames:
+			err = c.handleBrokerFrame(out.data)
+			if out.onSent != nil {
+				out.onSent(err)
+			}
+		case <-heartbeat:
+			err = c.write(frameTypeAMQP, 0, nil)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLoop read the frames of the client, enforcing the idle timeout and the throttling.
+func (c *connection) readLoop(ctx context.Context, incoming chan<- *frame, readErr chan<- error) {
+	for {
+		if !c.handler.throttler.Wait(ctx, c.conn) {
+			readErr <- ctx.Err()
+			return
+		}
+
+		if c.handler.idleTimeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.handler.idleTimeout))
+		}
+
+		f, err := readFrame(c.reader, c.handler.maxFrameSize)
+		if err != nil {
+			readErr <- err
+			return
+		}
+
+		select {
+		case incoming <- f:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+//nolint:cyclop
+func (c *connection) handleFrame(ctx context.Context, f *frame) error {
+	if len(f.body) == 0 {
+		return nil
+	}
+	if f.kind != frameTypeAMQP {
+		return c.closeWithError(conditionNotAllowed, "unexpected sasl frame")
+	}
+
+	p, payload, err := decodePerformative(f.body)
+	if err != nil {
+		return c.closeWithError(conditionDecodeError, err.Error())
+	}
+
+	if !c.opened && p.descriptor != descriptorOpen {
+		return c.closeWithError(conditionNotAllowed, "expected open")
+	}
+
+	switch p.descriptor {
+	case descriptorOpen:
+		return c.handleOpen(p.fields)
+	case descriptorBegin:
+		return c.handleBegin(f.channel, p.fields)
+	case descriptorClose:
+		_ = c.send(frameTypeAMQP, 0, descriptorClose)
+		return errConnectionClosed
+	}
+
+	s, ok := c.sessions[f.channel]
+	if !ok {
+		return c.closeWithError(conditionNotFound, fmt.Sprintf("no session on channel %d", f.channel))
+	}
+
+	switch p.descriptor {
+	case descriptorAttach:
+		return c.handleAttach(ctx, s, p.fields)
+	case descriptorFlow:
+		return c.handleFlow(s, p.fields)
+	case descriptorTransfer:
+		return c.handleTransfer(ctx, s, p.fields, payload)
+	case descriptorDisposition:
+		// the broker send every message settled, the client dispositions are not acted on.
+		return nil
+	case descriptorDetach:
+		return c.handleDetach(ctx, s, p.fields)
+	case descriptorEnd:
+		c.endSession(ctx, s)
+		return c.send(frameTypeAMQP, s.channel, descriptorEnd)
+	default:
+		return c.closeWithError(conditionNotAllowed, fmt.Sprintf("unexpected performative 0x%02x", p.descriptor))
+	}
+}
+
+func (c *connection) handleOpen(f fields) error {
+	if c.opened {
+		return c.closeWithError(conditionNotAllowed, "connection already opened")
+	}
+	c.opened = true
+
+	c.maxFrameSize = max(f.uint32(2, math.MaxUint32), minMaxFrameSize)
+	if idleTimeout := f.uint32(4, 0); idleTimeout > 0 {
+		// the heartbeats are sent twice per idle timeout of the client.
+		c.heartbeat = time.Duration(idleTimeout) * time.Millisecond / 2 //nolint:mnd
+	}
+
+	c.handler.logger.Info("amqp client connected", "client_id", c.clientID, "container_id", f.string(0))
+
+	var idleTimeout any
+	if c.handler.idleTimeout > 0 {
+		idleTimeout = uint32(c.handler.idleTimeout.Milliseconds())
+	}
+
+	return c.send(frameTypeAMQP, 0, descriptorOpen,
+		string(c.containerID),
+		nil,
+		c.handler.maxFrameSize,
+		channelMax,
+		idleTimeout,
+	)
+}
+
+func (c *connection) handleBegin(channel uint16, f fields) error {
+	if f.get(0) != nil {
+		return c.closeWithError(conditionNotAllowed, "sessions are begun by the client")
+	}
+	if channel > channelMax {
+		return c.closeWithError(conditionNotAllowed, fmt.Sprintf("channel %d above channel max", channel))
+	}
+	if _, ok := c.sessions[channel]; ok {
+		return c.closeWithError(conditionNotAllowed, fmt.Sprintf("session already begun on channel %d", channel))
+	}
+
+	s := &session{
+		channel:        channel,
+		nextIncomingID: f.uint32(1, 0),
+		incomingWindow: sessionWindow,
+		remoteIncoming: f.uint32(2, 0),
+		links:          make(map[uint32]*link),
+	}
+	c.sessions[channel] = s
+
+	return c.send(frameTypeAMQP, channel, descriptorBegin,
+		channel,
+		s.nextOutgoingID,
+		s.incomingWindow,
+		sessionWindow,
+	)
+}
+
+//nolint:cyclop
+func (c *connection) handleAttach(ctx context.Context, s *session, f fields) error {
+	name := f.string(0)
+	handle := f.uint32(1, math.MaxUint32)
+	if handle == math.MaxUint32 {
+		return c.closeWithError(conditionInvalidField, "attach without handle")
+	}
+	if _, ok := s.links[handle]; ok {
+		return c.closeWithError(conditionNotAllowed, fmt.Sprintf("handle %d in use", handle))
+	}
+
+	// the client role, false for a sender, the broker take the other role.
+	clientReceiver := f.bool(2)
+	source, target := f.get(5), f.get(6)
+
+	l := &link{name: name, handle: handle, receiver: !clientReceiver}
+
+	var err error
+	if l.receiver {
+		l.deliveryCount = f.uint32(9, 0)
+		if address := terminusAddress(target); address != "" {
+			l.address, err = toHopperTopic(address)
+		}
+	} else {
+		l.address, err = toHopperPattern(terminusAddress(source))
+	}
+
+	if err != nil {
+		c.handler.logger.Warn("amqp link refused", "client_id", c.clientID, "link", name, "error", err)
+		return c.refuseLink(s, l, source, target, err)
+	}
+
+	s.links[handle] = l
+
+	if l.receiver {
+		if err := c.send(frameTypeAMQP, s.channel, descriptorAttach,
+			name, handle, true, f.get(3), nil, source, target); err != nil {
+			return err
+		}
+		l.credit = linkCredit
+		return c.sendFlow(s, l)
+	}
+
+	if err := c.subscribe(ctx, l.address); err != nil {
+		return err
+	}
+
+	return c.send(frameTypeAMQP, s.channel, descriptorAttach,
+		name, handle, false, sndSettleModeSettled, nil, source, target, nil, nil, l.deliveryCount)
+}
+
+// refuseLink attach the link without terminus then detach it with the error, as the refusal of a link is signaled.
+func (c *connection) refuseLink(s *session, l *link, source, target any, reason error) error {
+	if l.receiver {
+		target = nil
+	} else {
+		source = nil
+	}
+
+	if err := c.send(frameTypeAMQP, s.channel, descriptorAttach,
+		l.name, l.handle, l.receiver, nil, nil, source, target); err != nil {
+		return err
+	}
+
+	return c.send(frameTypeAMQP, s.channel, descriptorDetach,
+		l.handle, true, amqpError(conditionInvalidField, reason.Error()))
+}
+
+func (c *connection) handleFlow(s *session, f fields) error {
+	nextIncomingID := f.uint32(0, 0)
+	s.remoteIncoming = nextIncomingID + f.uint32(1, 0) - s.nextOutgoingID
+
+	handle := f.uint32(4, math.MaxUint32)
+	l, ok := s.links[handle]
+	if handle == math.MaxUint32 || !ok {
+		if f.bool(9) {
+			return c.sendFlow(s, nil)
+		}
+		return c.pumpSession(s)
+	}
+
+	if !l.receiver {
+		l.credit = f.uint32(5, 0) + f.uint32(6, 0) - l.deliveryCount
+		l.drain = f.bool(8)
+	}
+
+	if err := c.pump(s, l); err != nil {
+		return err
+	}
+
+	if l.drain && l.credit > 0 {
+		l.deliveryCount += l.credit
+		l.credit = 0
+		return c.sendFlow(s, l)
+	}
+	if f.bool(9) {
+		return c.sendFlow(s, l)
+	}
+
+	return nil
+}
+
+func (c *connection) handleTransfer(ctx context.Context, s *session, f fields, payload []byte) error {
+	s.nextIncomingID++
+	s.incomingSinceAck++
+
+	handle := f.uint32(0, math.MaxUint32)
+	l, ok := s.links[handle]
+	if !ok || !l.receiver {
+		return c.closeWithError(conditionNotFound, fmt.Sprintf("no receiving link with handle %d", handle))
+	}
+
+	if !l.assembling {
+		l.deliveryID = f.uint32(1, 0)
+		l.settled = f.bool(4)
+		l.assembling = true
+	}
+	l.transfer = append(l.transfer, payload...)
+	if len(l.transfer) > c.handler.maxMessageSize {
+		return c.closeWithError(conditionNotAllowed, "message too large")
+	}
+
+	if f.bool(5) {
+		return nil
+	}
+
+	l.assembling = false
+	body := l.transfer
+	l.transfer = nil
+	if f.bool(9) { // aborted
+		return nil
+	}
+
+	l.deliveryCount++
+	if l.credit > 0 {
+		l.credit--
+	}
+
+	outcome := c.publish(ctx, l, body)
+	if !l.settled {
+		if err := c.send(frameTypeAMQP, s.channel, descriptorDisposition,
+			true, l.deliveryID, nil, true, outcome); err != nil {
+			return err
+		}
+	}
+
+	if l.credit <= linkCredit/2 || s.incomingSinceAck >= sessionWindow/2 {
+		l.credit = linkCredit
+		return c.sendFlow(s, l)
+	}
+
+	return nil
+}
+
+// publish hand a transferred message to the broker and return the delivery outcome.
+func (c *connection) publish(ctx context.Context, l *link, body []byte) *Described {
+	msg, err := decodeMessage(body)
+	if err != nil {
+		return rejected(conditionDecodeError, err)
+	}
+
+	topic := l.address
+	if topic == "" {
+		if topic, err = toHopperTopic(msg.to); err != nil {
+			return rejected(conditionInvalidField, err)
+		}
+	}
+
+	frame, err := frames.CreateMessageFrame(domain.DOFF4, topic, common.GenerateIdentifier(), msg.content, msg.headers)
+	if err != nil {
+		return rejected(conditionInternalError, err)
+	}
+	if err := c.sendFrame(ctx, frame); err != nil {
+		return rejected(conditionInternalError, err)
+	}
+
+	return &Described{Descriptor: descriptorAccepted, Value: []any{}}
+}
+
+func rejected(condition Symbol, err error) *Described {
+	return &Described{Descriptor: descriptorRejected, Value: []any{amqpError(condition, err.Error())}}
+}
+
+func (c *connection) handleDetach(ctx context.Context, s *session, f fields) error {
+	handle := f.uint32(0, math.MaxUint32)
+	l, ok := s.links[handle]
+	if !ok {
+		// the answer to the detach of a refused link.
+		return nil
+	}
+
+	c.detach(ctx, s, l)
+
+	return c.send(frameTypeAMQP, s.channel, descriptorDetach, handle, true)
+}
+
+func (c *connection) detach(ctx context.Context, s *session, l *link) {
+	delete(s.links, l.handle)
+
+	if !l.receiver {
+		c.unsubscribe(ctx, l.address)
+	}
+}
+
+func (c *connection) endSession(ctx context.Context, s *session) {
+	for _, l := range s.links {
+		c.detach(ctx, s, l)
+	}
+	delete(c.sessions, s.channel)
+}
+
+// subscribe subscribe the client to the topic pattern on its first link.
+func (c *connection) subscribe(ctx context.Context, pattern string) error {
+	c.subscriptions[pattern]++
+	if c.subscriptions[pattern] > 1 {
+		return nil
+	}
+
+	frame, err := frames.CreateSubscribeFrame(domain.DOFF4, pattern, 0, "")
+	if err != nil {
+		return err
+	}
+
+	return c.sendFrame(ctx, frame)
+}
+
+// unsubscribe unsubscribe the client from the topic pattern once its last link is detached.
+func (c *connection) unsubscribe(ctx context.Context, pattern string) {
+	c.subscriptions[pattern]--
+	if c.subscriptions[pattern] > 0 {
+		return
+	}
+	delete(c.subscriptions, pattern)
+
+	frame, err := frames.CreateUnsubscribeFrame(domain.DOFF4, pattern)
+	if err == nil {
+		err = c.sendFrame(ctx, frame)
+	}
+	if err != nil {
+		c.handler.logger.Warn("failed to unsubscribe amqp link", "client_id", c.clientID, "topic", pattern, "error", err)
+	}
+}
+
+// handleBrokerFrame translate a frame sent by the broker once the client is connected.
+func (c *connection) handleBrokerFrame(data []byte) error {
+	frame, err := c.handler.serializer.DeserializeFrame(data)
+	if err != nil {
+		return err
+	}
+
+	switch payload := frame.GetPayload().(type) {
+	case domain.MessageFramePayload:
+		return c.route(delivery{
+			topic:     payload.GetTopic(),
+			messageID: payload.GetMessageID(),
+			content:   payload.GetContent(),
+			headers:   payload.GetHeaders(),
+		})
+	case domain.ErrorFramePayload:
+		c.handler.logger.Warn("amqp operation refused by the broker",
+			"client_id", c.clientID,
+			"error_code", domain.ErrorCode(payload.GetErrorCode()),
+			"reason", payload.GetErrorMessage())
+	case domain.CloseFramePayload:
+		c.handler.logger.Info("amqp connection closed by the broker", "client_id", c.clientID, "reason", payload.GetReason())
+		return c.closeWithError(conditionConnectionForced, payload.GetReason())
+	}
+
+	return nil
+}
+
+// route queue the message on every sending link whose pattern match its topic.
+func (c *connection) route(d delivery) error {
+	for _, s := range c.sessions {
+		for _, l := range s.links {
+			if l.receiver || !domain.MatchTopic(l.address, d.topic) {
+				continue
+			}
+
+			if len(l.queue) >= maxQueued {
+				c.handler.logger.Warn("amqp link queue full, dropping the oldest message", "client_id", c.clientID, "link", l.name)
+				l.queue = l.queue[1:]
+			}
+			l.queue = append(l.queue, d)
+
+			if err := c.pump(s, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *connection) pumpSession(s *session) error {
+	for _, l := range s.links {
+		if err := c.pump(s, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pump transfer the queued messages of a sending link while the link credit and the session window allow it.
+func (c *connection) pump(s *session, l *link) error {
+	for !l.receiver && len(l.queue) > 0 && l.credit > 0 {
+		d := l.queue[0]
+
+		body, err := encodeMessage(d.topic, string(d.messageID), d.content, d.headers)
+		if err != nil {
+			return err
+		}
+
+		deliveryID := s.nextDeliveryID
+		tag := binary.BigEndian.AppendUint32(nil, deliveryID)
+
+		chunks, err := c.splitTransfer(l.handle, deliveryID, tag, body)
+		if err != nil {
+			return err
+		}
+		if uint32(len(chunks)) > s.remoteIncoming {
+			return nil
+		}
+
+		for _, chunk := range chunks {
+			if err := c.write(frameTypeAMQP, s.channel, chunk); err != nil {
+				return err
+			}
+			s.nextOutgoingID++
+			s.remoteIncoming--
+		}
+
+		l.queue = l.queue[1:]
+		s.nextDeliveryID++
+		l.deliveryCount++
+		l.credit--
+	}
+
+	return nil
+}
+
+// splitTransfer encode the transfer frames carrying the message within the max frame size of the client.
+func (c *connection) splitTransfer(handle, deliveryID uint32, tag, body []byte) ([][]byte, error) {
+	var chunks [][]byte
+	for first := true; first || len(body) > 0; first = false {
+		// the more flag is the last field and encode on a single byte, its value does not change the size.
+		performative := func(more bool) ([]byte, error) {
+			if first {
+				return encodePerformative(descriptorTransfer, handle, deliveryID, tag, uint32(0), true, more)
+			}
+			return encodePerformative(descriptorTransfer, handle, nil, nil, nil, nil, more)
+		}
+
+		header, err := performative(true)
+		if err != nil {
+			return nil, err
+		}
+
+		room := int(c.maxFrameSize) - frameHeaderSize - len(header)
+		if room <= 0 {
+			return nil, fmt.Errorf("%w: max frame size %d", ErrFrameTooLarge, c.maxFrameSize)
+		}
+
+		n := min(room, len(body))
+		if n == len(body) {
+			if header, err = performative(false); err != nil {
+				return nil, err
+			}
+		}
+
+		chunks = append(chunks, append(header, body[:n]...))
+		body = body[n:]
+	}
+
+	return chunks, nil
+}
+
+// sendFlow send the session flow state, and the link flow state when a link is given.
+func (c *connection) sendFlow(s *session, l *link) error {
+	s.incomingSinceAck = 0
+	flow := []any{s.nextIncomingID, s.incomingWindow, s.nextOutgoingID, sessionWindow}
+	if l != nil {
+		flow = append(flow, l.handle, l.deliveryCount, l.credit, nil, l.drain)
+	}
+
+	return c.send(frameTypeAMQP, s.channel, descriptorFlow, flow...)
+}
+
+// closeWithError close the connection with the error condition.
+func (c *connection) closeWithError(condition Symbol, description string) error {
+	_ = c.send(frameTypeAMQP, 0, descriptorClose, amqpError(condition, description))
+
+	return fmt.Errorf("%s: %s", condition, description)
+}
+
+// sendFrame serialize the frame and hand it to the broker as a frame received from the client.
+func (c *connection) sendFrame(ctx context.Context, frame domain.Frame) error {
+	data, err := c.handler.serializer.SerializeFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	evt := &events.MessageReceivedEvent{
+		Conn:      c.conn,
+		Message:   data,
+		Transport: domain.TransportTypeAMQP,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeReceiveMessage,
+		},
+	}
+
+	return c.handler.eb.Publish(ctx, evt)
+}
+
+// send encode the performative and write it on the channel.
+func (c *connection) send(kind byte, channel uint16, descriptor uint64, fields ...any) error {
+	body, err := encodePerformative(descriptor, fields...)
+	if err != nil {
+		return err
+	}
+
+	return c.write(kind, channel, body)
+}
+
+func (c *connection) write(kind byte, channel uint16, body []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	return writeFrame(c.conn, kind, channel, body)
+}
+
+// toHopperTopic map a link or message address to the HopperMQ topic messages are published on.
+func toHopperTopic(address string) (string, error) {
+	topic := trimAddress(address)
+	if topic == "" || strings.ContainsAny(topic, "*#") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+
+	return topic, nil
+}
+
+// toHopperPattern map the source address of a receiving link to the HopperMQ topic pattern it subscribe.
+func toHopperPattern(address string) (string, error) {
+	pattern := trimAddress(address)
+	if pattern == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+
+	return pattern, nil
+}
+
+// trimAddress strip the "/topics/" or "topic://" prefix clients commonly put in front of a topic.
+func trimAddress(address string) string {
+	for _, prefix := range []string{"topic://", "/topics/", "/topic/"} {
+		address = strings.TrimPrefix(address, prefix)
+	}
+
+	return address
+}
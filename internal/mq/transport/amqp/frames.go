@@ -0,0 +1,250 @@
+package amqp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// protocol ids of the AMQP protocol header.
+const (
+	protocolAMQP byte = 0
+	protocolSASL byte = 3
+)
+
+// frame types.
+const (
+	frameTypeAMQP byte = 0
+	frameTypeSASL byte = 1
+)
+
+// descriptors of the performatives, the SASL frames and the composite types.
+const (
+	descriptorOpen        uint64 = 0x10
+	descriptorBegin       uint64 = 0x11
+	descriptorAttach      uint64 = 0x12
+	descriptorFlow        uint64 = 0x13
+	descriptorTransfer    uint64 = 0x14
+	descriptorDisposition uint64 = 0x15
+	descriptorDetach      uint64 = 0x16
+	descriptorEnd         uint64 = 0x17
+	descriptorClose       uint64 = 0x18
+
+	descriptorError    uint64 = 0x1d
+	descriptorAccepted uint64 = 0x24
+	descriptorRejected uint64 = 0x25
+	descriptorSource   uint64 = 0x28
+	descriptorTarget   uint64 = 0x29
+
+	descriptorSASLMechanisms uint64 = 0x40
+	descriptorSASLInit       uint64 = 0x41
+	descriptorSASLOutcome    uint64 = 0x44
+)
+
+// SASL outcome codes.
+const (
+	saslOK   byte = 0
+	saslAuth byte = 1
+	saslSys  byte = 2
+)
+
+// error conditions.
+const (
+	conditionInternalError    Symbol = "amqp:internal-error"
+	conditionNotFound         Symbol = "amqp:not-found"
+	conditionUnauthorized     Symbol = "amqp:unauthorized-access"
+	conditionDecodeError      Symbol = "amqp:decode-error"
+	conditionNotAllowed       Symbol = "amqp:not-allowed"
+	conditionInvalidField     Symbol = "amqp:invalid-field"
+	conditionConnectionForced Symbol = "amqp:connection:forced"
+)
+
+const (
+	// frameHeaderSize is the size of the frame header, the data offset is expressed in 4 bytes words.
+	frameHeaderSize = 8
+	// minMaxFrameSize is the smallest max frame size a peer may announce.
+	minMaxFrameSize = 512
+)
+
+var (
+	// ErrInvalidProtocolHeader is returned when a connection does not start with an AMQP 1.0 protocol header.
+	ErrInvalidProtocolHeader = errors.New("invalid amqp protocol header")
+	// ErrFrameTooLarge is returned when a frame exceed the negotiated max frame size.
+	ErrFrameTooLarge = errors.New("amqp frame too large")
+)
+
+// frame is an AMQP or SASL frame, the body is empty for the heartbeat frames.
+type frame struct {
+	kind    byte
+	channel uint16
+	body    []byte
+}
+
+// readProtocolHeader read the 8 bytes protocol header and return its protocol id.
+func readProtocolHeader(r io.Reader) (byte, error) {
+	header := make([]byte, 8) //nolint:mnd
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	if string(header[:4]) != "AMQP" || header[5] != 1 || header[6] != 0 || header[7] != 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidProtocolHeader, header)
+	}
+
+	return header[4], nil
+}
+
+// writeProtocolHeader write the AMQP 1.0 protocol header of the protocol id.
+func writeProtocolHeader(w io.Writer, protocolID byte) error {
+	_, err := w.Write([]byte{'A', 'M', 'Q', 'P', protocolID, 1, 0, 0})
+	return err
+}
+
+// readFrame read the next frame, refusing frames larger than maxSize bytes.
+func readFrame(r *bufio.Reader, maxSize uint32) (*frame, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header)
+	doff := uint32(header[4]) * 4 //nolint:mnd
+	if doff < frameHeaderSize || size < doff {
+		return nil, fmt.Errorf("%w: frame size %d, data offset %d", ErrInvalidEncoding, size, doff)
+	}
+	if maxSize > 0 && size > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+
+	rest := make([]byte, size-frameHeaderSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+
+	return &frame{
+		kind:    header[5],
+		channel: binary.BigEndian.Uint16(header[6:]),
+		body:    rest[doff-frameHeaderSize:],
+	}, nil
+}
+
+// writeFrame write a frame without extended header.
+func writeFrame(w io.Writer, kind byte, channel uint16, body []byte) error {
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(body))
+	binary.BigEndian.PutUint32(buf, uint32(frameHeaderSize+len(body)))
+	buf[4] = 2
+	buf[5] = kind
+	binary.BigEndian.PutUint16(buf[6:], channel)
+
+	_, err := w.Write(append(buf, body...))
+	return err
+}
+
+// performative is a described list, the fields are the list items.
+type performative struct {
+	descriptor uint64
+	fields     fields
+}
+
+// decodePerformative decode the performative starting the body, the remaining bytes are the payload of a transfer.
+func decodePerformative(body []byte) (*performative, []byte, error) {
+	d := &decoder{buf: body}
+	v, err := d.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	described, ok := v.(*Described)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: performative is not a described type", ErrInvalidEncoding)
+	}
+	descriptor, ok := toUint64(described.Descriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: descriptor %v", ErrInvalidEncoding, described.Descriptor)
+	}
+	list, ok := described.Value.([]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: performative 0x%02x is not a list", ErrInvalidEncoding, descriptor)
+	}
+
+	return &performative{descriptor: descriptor, fields: list}, d.buf, nil
+}
+
+// encodePerformative encode the performative, the trailing nil fields are omitted.
+func encodePerformative(descriptor uint64, fields ...any) ([]byte, error) {
+	for len(fields) > 0 && fields[len(fields)-1] == nil {
+		fields = fields[:len(fields)-1]
+	}
+
+	return marshal(&Described{Descriptor: descriptor, Value: fields})
+}
+
+// fields are the fields of a composite value, absent fields read as nil.
+type fields []any
+
+func (f fields) get(i int) any {
+	if i < len(f) {
+		return f[i]
+	}
+
+	return nil
+}
+
+func (f fields) uint32(i int, fallback uint32) uint32 {
+	if v, ok := toUint64(f.get(i)); ok && v <= uint64(^uint32(0)) {
+		return uint32(v)
+	}
+
+	return fallback
+}
+
+func (f fields) bool(i int) bool {
+	v, _ := f.get(i).(bool)
+	return v
+}
+
+func (f fields) string(i int) string {
+	switch v := f.get(i).(type) {
+	case string:
+		return v
+	case Symbol:
+		return string(v)
+	}
+
+	return ""
+}
+
+func toUint64(v any) (uint64, bool) {
+	switch v := v.(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// terminusAddress return the address of a source or target.
+func terminusAddress(terminus any) string {
+	described, ok := terminus.(*Described)
+	if !ok {
+		return ""
+	}
+	list, ok := described.Value.([]any)
+	if !ok {
+		return ""
+	}
+
+	return fields(list).string(0)
+}
+
+// amqpError return an error composite with the condition and description.
+func amqpError(condition Symbol, description string) *Described {
+	return &Described{Descriptor: descriptorError, Value: []any{condition, description}}
+}
@@ -0,0 +1,398 @@
+// Package amqp provides the AMQP 1.0 transport handler, mapping the AMQP connections, sessions and links
+// onto HopperMQ containers and topics so existing AMQP clients can publish and consume.
+package amqp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
+)
+
+const (
+	// defaultAddress is the IANA AMQP port + 1, the IANA port is served by the HopperMQ TCP transport.
+	defaultAddress        = ":5673"
+	defaultMaxFrameSize   = 64 * 1024
+	defaultMaxMessageSize = 1 << 20
+	defaultIdleTimeout    = 60 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+	writeTimeout          = 5 * time.Second
+	shutdownTimeout       = 10 * time.Second
+)
+
+var (
+	// ErrInvalidFrameSize is returned when the max frame size is below the AMQP minimum of 512 bytes.
+	ErrInvalidFrameSize = errors.New("invalid amqp max frame size")
+	// ErrInvalidMessageSize is returned when the max message size is not positive.
+	ErrInvalidMessageSize = errors.New("invalid amqp max message size")
+	// ErrMissingTLSConfig is returned when TLS is requested without a TLS configuration.
+	ErrMissingTLSConfig = errors.New("missing tls configuration")
+)
+
+// AMQP is an AMQP 1.0 handler.
+type AMQP struct {
+	Listener       net.Listener
+	logger         *slog.Logger
+	address        string
+	tlsConfig      *tls.Config
+	limiter        *transport.Limiter
+	maxFrameSize   uint32
+	maxMessageSize int
+	idleTimeout    time.Duration
+	connectTimeout time.Duration
+
+	serializer *serializer.Serializer
+	throttler  transport.Throttler
+	conns      transport.Conns
+
+	eb domain.IEventBus
+
+	mu          sync.Mutex
+	connections map[domain.Connection]*connection
+
+	cancel context.CancelFunc
+}
+
+type config struct {
+	logger         *slog.Logger
+	address        string
+	tlsConfig      *tls.Config
+	limiter        *transport.Limiter
+	maxFrameSize   uint32
+	maxMessageSize int
+	idleTimeout    time.Duration
+	connectTimeout time.Duration
+}
+
+// Option configure the AMQP handler.
+type Option func(*config) error
+
+// WithLogger inject the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+
+		return nil
+	}
+}
+
+// WithAddress set the address the handler listen on, :5673 by default.
+func WithAddress(address string) Option {
+	return func(c *config) error {
+		if address != "" {
+			c.address = address
+		}
+
+		return nil
+	}
+}
+
+// WithLimiter admit the accepted connections through the limiter shared by the transports.
+func WithLimiter(limiter *transport.Limiter) Option {
+	return func(c *config) error {
+		c.limiter = limiter
+
+		return nil
+	}
+}
+
+// WithTLS terminate TLS on the listener with the given configuration, a nil configuration is refused
+// rather than silently serving plaintext.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		if tlsConfig == nil {
+			return ErrMissingTLSConfig
+		}
+		c.tlsConfig = tlsConfig
+
+		return nil
+	}
+}
+
+// WithMaxFrameSize set the max frame size announced to the clients, larger messages are split over several frames.
+func WithMaxFrameSize(size uint32) Option {
+	return func(c *config) error {
+		if size < minMaxFrameSize {
+			return ErrInvalidFrameSize
+		}
+		c.maxFrameSize = size
+
+		return nil
+	}
+}
+
+// WithMaxMessageSize set the maximum size in bytes of a message transferred by a client.
+func WithMaxMessageSize(size int) Option {
+	return func(c *config) error {
+		if size <= 0 {
+			return ErrInvalidMessageSize
+		}
+		c.maxMessageSize = size
+
+		return nil
+	}
+}
+
+// WithIdleTimeout set the idle timeout announced to the clients, silent clients are disconnected after it.
+// A zero timeout disable the idle detection.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout >= 0 {
+			c.idleTimeout = timeout
+		}
+
+		return nil
+	}
+}
+
+// WithConnectTimeout set how long a client has to negotiate the protocol and be accepted by the broker.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout > 0 {
+			c.connectTimeout = timeout
+		}
+
+		return nil
+	}
+}
+
+// NewAMQP return the new AMQP handler.
+func NewAMQP(opts ...Option) (*AMQP, error) {
+	handlerConfig := &config{
+		address:        defaultAddress,
+		maxFrameSize:   defaultMaxFrameSize,
+		maxMessageSize: defaultMaxMessageSize,
+		idleTimeout:    defaultIdleTimeout,
+		connectTimeout: defaultConnectTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(handlerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AMQP{
+		logger:         handlerConfig.logger,
+		address:        handlerConfig.address,
+		tlsConfig:      handlerConfig.tlsConfig,
+		limiter:        handlerConfig.limiter,
+		maxFrameSize:   handlerConfig.maxFrameSize,
+		maxMessageSize: handlerConfig.maxMessageSize,
+		idleTimeout:    handlerConfig.idleTimeout,
+		connectTimeout: handlerConfig.connectTimeout,
+		serializer: serializer.NewSerializer(
+			common.NewPool(func() *bytes.Buffer {
+				return &bytes.Buffer{}
+			}),
+		),
+		connections: make(map[domain.Connection]*connection),
+	}, nil
+}
+
+// HandleConnection accept the AMQP clients until the listener is closed.
+func (a *AMQP) HandleConnection(ctx context.Context) error {
+	for {
+		conn, err := a.Listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return ctx.Err()
+			}
+			a.logger.Warn("failed to accept amqp connection", "error", err)
+			continue
+		}
+
+		go a.serveConn(ctx, conn)
+	}
+}
+
+func (a *AMQP) serveConn(ctx context.Context, conn net.Conn) {
+	if !a.conns.Track(conn) {
+		a.logger.Warn("transport closed, refusing connection", "transport", domain.TransportTypeAMQP)
+		_ = conn.Close()
+		return
+	}
+	defer a.conns.Untrack(conn)
+	defer func() {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			a.logger.Warn("failed to close connection", "error", err)
+		}
+	}()
+
+	if a.eb == nil {
+		a.logger.Warn("EventBus not registered, skipping event publishing")
+		return
+	}
+
+	c := newConnection(a, conn)
+	if err := c.negotiate(); err != nil {
+		a.logger.Info("amqp negotiation failed", "remote_addr", conn.RemoteAddr().String(), "error", err)
+		return
+	}
+
+	identity := ""
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		identity = security.PeerIdentity(tlsConn.ConnectionState())
+	}
+
+	a.track(conn, c)
+	defer a.untrack(conn)
+	defer a.throttler.Forget(conn)
+
+	evt := &events.NewConnectionEvent{
+		Conn:      conn,
+		Identity:  identity,
+		Transport: domain.TransportTypeAMQP,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeNewConnection,
+		},
+	}
+
+	if err := a.eb.Publish(ctx, evt); err != nil {
+		a.logger.Warn("failed to publish new connection event", "error", err)
+		return
+	}
+
+	err := c.handshake(ctx)
+	if err == nil {
+		err = c.run(ctx)
+	}
+
+	switch {
+	case errors.Is(err, errConnectionClosed), errors.Is(err, io.EOF), errors.Is(err, context.Canceled):
+		a.logger.Info("amqp client disconnected", "client_id", c.clientID)
+	default:
+		a.logger.Warn("amqp connection ended", "client_id", c.clientID, "error", err)
+	}
+
+	disconnected := &events.ClientDisconnectedEvent{
+		Transport: domain.TransportTypeAMQP,
+		Conn:      conn,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeConnectionClosed,
+		},
+	}
+
+	if err := a.eb.Publish(context.WithoutCancel(ctx), disconnected); err != nil {
+		a.logger.Warn("failed to publish client disconnected event", "error", err)
+	}
+}
+
+func (a *AMQP) track(conn domain.Connection, c *connection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.connections[conn] = c
+}
+
+func (a *AMQP) untrack(conn domain.Connection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.connections, conn)
+}
+
+func (a *AMQP) connection(conn domain.Connection) *connection {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.connections[conn]
+}
+
+// Run wil start the AMQP component.
+func (a *AMQP) Run(ctx context.Context) error {
+	a.logger.Info("starting AMQP component", "address", a.address)
+
+	l, err := net.Listen("tcp", a.address)
+	if err != nil {
+		return err
+	}
+	l = a.limiter.Listener(l, domain.TransportTypeAMQP, a.logger)
+	if a.tlsConfig != nil {
+		l = tls.NewListener(l, a.tlsConfig)
+	}
+	a.Listener = l
+
+	ctx, a.cancel = context.WithCancel(ctx)
+
+	transport.Dispatcher{
+		Transport: domain.TransportTypeAMQP,
+		Sink:      a.forward,
+		Throttler: &a.throttler,
+	}.Run(ctx, a.eb, &a.conns)
+
+	go func() {
+		a.logger.Info("AMQP server running", "address", l.Addr().String())
+		if err := a.HandleConnection(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			a.logger.Warn("AMQP Handler failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop will shut down gracefully the AMQP component.
+func (a *AMQP) Stop(_ context.Context) error {
+	a.logger.Info("stopping AMQP Component")
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	if a.Listener != nil {
+		if err := a.Listener.Close(); err != nil {
+			a.logger.Warn("error closing amqp listener", "error", err)
+		}
+	}
+
+	if a.conns.Close(shutdownTimeout) {
+		a.logger.Info("All amqp connections closed gracefully")
+	} else {
+		a.logger.Warn("Timeout waiting for amqp connections to close")
+	}
+
+	return nil
+}
+
+// Name will return the component name.
+func (a *AMQP) Name() string {
+	return "amqp-handler"
+}
+
+// RegisterEventBus will attach the event bus to the component.
+func (a *AMQP) RegisterEventBus(eb domain.IEventBus) {
+	a.eb = eb
+	a.logger.Info("EventBus registered with AMQP", "service", a.Name())
+}
+
+// forward hand a frame sent by the broker to the connection,
+// the connection translate it to AMQP performatives.
+func (a *AMQP) forward(ctx context.Context, conn domain.Connection, data []byte, onSent func(error)) {
+	c := a.connection(conn)
+	if c == nil {
+		if onSent != nil {
+			onSent(net.ErrClosed)
+		}
+		return
+	}
+
+	select {
+	case c.frames <- outbound{data: data, onSent: onSent}:
+	case <-c.done:
+		if onSent != nil {
+			onSent(net.ErrClosed)
+		}
+	case <-ctx.Done():
+	}
+}
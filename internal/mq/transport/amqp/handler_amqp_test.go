@@ -0,0 +1,257 @@
+package amqp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core"
+	"github.com/hoppermq/hopper/internal/security"
+)
+
+// testClient is a minimal AMQP 1.0 client.
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dial(t *testing.T, a *AMQP) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", a.Listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *testClient) header(protocolID byte) {
+	c.t.Helper()
+
+	require.NoError(c.t, writeProtocolHeader(c.conn, protocolID))
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	got, err := readProtocolHeader(c.reader)
+	require.NoError(c.t, err)
+	require.Equal(c.t, protocolID, got)
+}
+
+func (c *testClient) send(kind byte, channel uint16, descriptor uint64, payload []byte, fields ...any) {
+	c.t.Helper()
+
+	body, err := encodePerformative(descriptor, fields...)
+	require.NoError(c.t, err)
+	require.NoError(c.t, writeFrame(c.conn, kind, channel, append(body, payload...)))
+}
+
+// receive return the next performative, skipping the heartbeats.
+func (c *testClient) receive(descriptor uint64) (fields, []byte) {
+	c.t.Helper()
+
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		f, err := readFrame(c.reader, 0)
+		require.NoError(c.t, err)
+		if len(f.body) == 0 {
+			continue
+		}
+
+		p, payload, err := decodePerformative(f.body)
+		require.NoError(c.t, err)
+		require.Equal(c.t, descriptor, p.descriptor, "fields: %v", p.fields)
+
+		return p.fields, payload
+	}
+}
+
+// sasl authenticate with the mechanism and return the outcome code.
+func (c *testClient) sasl(mechanism Symbol, response []byte) uint8 {
+	c.t.Helper()
+
+	c.header(protocolSASL)
+	mechanisms, _ := c.receive(descriptorSASLMechanisms)
+	assert.Contains(c.t, mechanisms.get(0), mechanism)
+
+	c.send(frameTypeSASL, 0, descriptorSASLInit, nil, mechanism, response)
+	outcome, _ := c.receive(descriptorSASLOutcome)
+	code, _ := outcome.get(0).(uint8)
+
+	return code
+}
+
+// open open the connection and begin a session on channel 0.
+func (c *testClient) open(maxFrameSize uint32) {
+	c.t.Helper()
+
+	c.header(protocolAMQP)
+	c.send(frameTypeAMQP, 0, descriptorOpen, nil, "test-client", nil, maxFrameSize)
+	c.receive(descriptorOpen)
+
+	c.send(frameTypeAMQP, 0, descriptorBegin, nil, nil, uint32(0), uint32(1000), uint32(1000))
+	begin, _ := c.receive(descriptorBegin)
+	assert.Equal(c.t, uint16(0), begin.get(0))
+}
+
+func startAMQP(t *testing.T) *AMQP {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := slog.New(slog.DiscardHandler)
+	eb := events.NewEventBus(10)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	broker := core.NewBroker(logger, eb)
+	broker.RegisterEventBus(eb)
+	broker.RegisterAuthenticator(security.NewAuthenticator(security.User{Username: "app", PasswordHash: string(hash)}), 0)
+	require.NoError(t, broker.Run(ctx))
+
+	a, err := NewAMQP(WithLogger(logger), WithAddress("127.0.0.1:0"), WithConnectTimeout(2*time.Second))
+	require.NoError(t, err)
+	a.RegisterEventBus(eb)
+	require.NoError(t, a.Run(ctx))
+	t.Cleanup(func() { _ = a.Stop(context.Background()) })
+
+	return a
+}
+
+func source(address string) *Described {
+	return &Described{Descriptor: descriptorSource, Value: []any{address}}
+}
+
+func target(address string) *Described {
+	return &Described{Descriptor: descriptorTarget, Value: []any{address}}
+}
+
+func TestAMQP_Options(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option Option
+		want   bool
+	}{
+		{name: "WithAddress_Option", option: WithAddress(":5671"), want: true},
+		{name: "WithTLS_Option", option: WithTLS(&tls.Config{}), want: true},
+		{name: "WithTLS_Nil_Option", option: WithTLS(nil), want: false},
+		{name: "WithMaxFrameSize_Option", option: WithMaxFrameSize(4096), want: true},
+		{name: "WithMaxFrameSize_Below_Minimum_Option", option: WithMaxFrameSize(256), want: false},
+		{name: "WithMaxMessageSize_Zero_Option", option: WithMaxMessageSize(0), want: false},
+		{name: "WithIdleTimeout_Option", option: WithIdleTimeout(0), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.option(&config{})
+			assert.Equal(t, tt.want, err == nil)
+		})
+	}
+}
+
+func TestAMQP_SASL(t *testing.T) {
+	t.Parallel()
+
+	a := startAMQP(t)
+
+	tests := []struct {
+		name      string
+		mechanism Symbol
+		response  []byte
+		want      uint8
+	}{
+		{name: "Plain_Accepted", mechanism: mechanismPlain, response: []byte("\x00app\x00secret"), want: saslOK},
+		{name: "Plain_Bad_Password", mechanism: mechanismPlain, response: []byte("\x00app\x00wrong"), want: saslAuth},
+		{name: "Anonymous_Without_Authentication", mechanism: mechanismAnonymous, want: saslAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, dial(t, a).sasl(tt.mechanism, tt.response))
+		})
+	}
+}
+
+func TestAMQP_Publish_Consume(t *testing.T) {
+	t.Parallel()
+
+	a := startAMQP(t)
+
+	consumer := dial(t, a)
+	require.Equal(t, saslOK, consumer.sasl(mechanismPlain, []byte("\x00app\x00secret")))
+	// the smallest frame size, the message is split over several transfers.
+	consumer.open(minMaxFrameSize)
+
+	consumer.send(frameTypeAMQP, 0, descriptorAttach, nil, "orders", uint32(0), true, nil, nil, source("orders.*"), target(""))
+	attach, _ := consumer.receive(descriptorAttach)
+	assert.Equal(t, false, attach.get(2), "the broker is the sender")
+	assert.Equal(t, "orders.*", terminusAddress(attach.get(5)))
+	consumer.send(frameTypeAMQP, 0, descriptorFlow, nil,
+		uint32(0), uint32(1000), uint32(0), uint32(1000), uint32(0), uint32(0), uint32(10))
+
+	producer := dial(t, a)
+	require.Equal(t, saslOK, producer.sasl(mechanismPlain, []byte("\x00app\x00secret")))
+	producer.open(defaultMaxFrameSize)
+
+	producer.send(frameTypeAMQP, 0, descriptorAttach, nil, "sender", uint32(1), false, nil, nil, source(""), target("/topics/orders.created"))
+	attach, _ = producer.receive(descriptorAttach)
+	assert.Equal(t, true, attach.get(2), "the broker is the receiver")
+	flow, _ := producer.receive(descriptorFlow)
+	assert.Equal(t, linkCredit, flow.uint32(6, 0))
+
+	content := bytes.Repeat([]byte("x"), 2000)
+	body, err := encodeMessage("", "order-1", content, map[string]string{HeaderContentType: "text/plain", "tenant": "acme"})
+	require.NoError(t, err)
+	producer.send(frameTypeAMQP, 0, descriptorTransfer, body, uint32(1), uint32(0), []byte{0}, uint32(0), false)
+
+	disposition, _ := producer.receive(descriptorDisposition)
+	state, ok := disposition.get(4).(*Described)
+	require.True(t, ok)
+	assert.Equal(t, descriptorAccepted, state.Descriptor)
+
+	var received []byte
+	for {
+		transfer, payload := consumer.receive(descriptorTransfer)
+		assert.Equal(t, uint32(0), transfer.uint32(0, 1))
+		received = append(received, payload...)
+		if !transfer.bool(5) {
+			break
+		}
+	}
+
+	msg, err := decodeMessage(received)
+	require.NoError(t, err)
+	assert.Equal(t, "orders.created", msg.to)
+	assert.Equal(t, content, msg.content)
+	assert.Equal(t, map[string]string{HeaderContentType: "text/plain", "tenant": "acme"}, msg.headers)
+
+	// a pattern is not a valid publish address.
+	producer.send(frameTypeAMQP, 0, descriptorAttach, nil, "invalid", uint32(2), false, nil, nil, source(""), target("orders.*"))
+	producer.receive(descriptorAttach)
+	detach, _ := producer.receive(descriptorDetach)
+	assert.Equal(t, uint32(2), detach.uint32(0, 0))
+	assert.Equal(t, true, detach.get(1))
+
+	consumer.send(frameTypeAMQP, 0, descriptorDetach, nil, uint32(0), true)
+	consumer.receive(descriptorDetach)
+	consumer.send(frameTypeAMQP, 0, descriptorEnd, nil)
+	consumer.receive(descriptorEnd)
+	consumer.send(frameTypeAMQP, 0, descriptorClose, nil)
+	consumer.receive(descriptorClose)
+}
@@ -0,0 +1,162 @@
+package amqp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// descriptors of the message sections.
+const (
+	descriptorHeader                uint64 = 0x70
+	descriptorDeliveryAnnotations   uint64 = 0x71
+	descriptorMessageAnnotations    uint64 = 0x72
+	descriptorProperties            uint64 = 0x73
+	descriptorApplicationProperties uint64 = 0x74
+	descriptorData                  uint64 = 0x75
+	descriptorAmqpSequence          uint64 = 0x76
+	descriptorAmqpValue             uint64 = 0x77
+	descriptorFooter                uint64 = 0x78
+)
+
+// positions of the fields of the properties section.
+const (
+	propertyMessageID     = 0
+	propertyTo            = 2
+	propertySubject       = 3
+	propertyReplyTo       = 4
+	propertyCorrelationID = 5
+	propertyContentType   = 6
+)
+
+// headers mapped from and to the message properties, the application properties are carried as is.
+const (
+	HeaderContentType   = "content-type"
+	HeaderSubject       = "subject"
+	HeaderReplyTo       = "reply-to"
+	HeaderCorrelationID = "correlation-id"
+)
+
+// ErrUnsupportedBody is returned when the body of a message is neither data sections nor a string or binary value.
+var ErrUnsupportedBody = errors.New("unsupported amqp message body")
+
+// message is the part of an AMQP message carried by a HopperMQ message frame.
+type message struct {
+	to      string
+	content []byte
+	headers map[string]string
+}
+
+// decodeMessage decode the sections of a transferred message.
+//
+//nolint:gocyclo,cyclop
+func decodeMessage(payload []byte) (*message, error) {
+	msg := &message{headers: make(map[string]string)}
+
+	d := &decoder{buf: payload}
+	for len(d.buf) > 0 {
+		v, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+		section, ok := v.(*Described)
+		if !ok {
+			return nil, fmt.Errorf("%w: message section is not a described type", ErrInvalidEncoding)
+		}
+		descriptor, _ := toUint64(section.Descriptor)
+
+		switch descriptor {
+		case descriptorHeader, descriptorDeliveryAnnotations, descriptorMessageAnnotations, descriptorFooter:
+			// delivery hints the broker does not act on.
+		case descriptorProperties:
+			list, _ := section.Value.([]any)
+			properties := fields(list)
+			msg.to = properties.string(propertyTo)
+			setHeader(msg.headers, HeaderSubject, properties.get(propertySubject))
+			setHeader(msg.headers, HeaderReplyTo, properties.get(propertyReplyTo))
+			setHeader(msg.headers, HeaderCorrelationID, properties.get(propertyCorrelationID))
+			setHeader(msg.headers, HeaderContentType, properties.get(propertyContentType))
+		case descriptorApplicationProperties:
+			properties, _ := section.Value.(map[any]any)
+			for key, value := range properties {
+				if name, ok := key.(string); ok {
+					setHeader(msg.headers, name, value)
+				}
+			}
+		case descriptorData:
+			data, ok := section.Value.([]byte)
+			if !ok {
+				return nil, fmt.Errorf("%w: data section is not binary", ErrInvalidEncoding)
+			}
+			msg.content = append(msg.content, data...)
+		case descriptorAmqpValue:
+			switch value := section.Value.(type) {
+			case []byte:
+				msg.content = value
+			case string:
+				msg.content = []byte(value)
+			default:
+				return nil, fmt.Errorf("%w: amqp-value of type %T", ErrUnsupportedBody, value)
+			}
+		case descriptorAmqpSequence:
+			return nil, fmt.Errorf("%w: amqp-sequence", ErrUnsupportedBody)
+		default:
+			return nil, fmt.Errorf("%w: unknown message section %v", ErrInvalidEncoding, section.Descriptor)
+		}
+	}
+
+	return msg, nil
+}
+
+// setHeader set the header to the string form of a simple value, nil and compound values are skipped.
+func setHeader(headers map[string]string, name string, value any) {
+	switch v := value.(type) {
+	case nil, []any, map[any]any, Array, *Described:
+	case string:
+		headers[name] = v
+	case Symbol:
+		headers[name] = string(v)
+	case []byte:
+		headers[name] = string(v)
+	default:
+		headers[name] = fmt.Sprint(v)
+	}
+}
+
+// encodeMessage encode a message routed by the broker, the content is carried in a data section.
+func encodeMessage(topic, messageID string, content []byte, headers map[string]string) ([]byte, error) {
+	properties := make([]any, propertyContentType+1)
+	properties[propertyMessageID] = messageID
+	properties[propertyTo] = topic
+	applicationProperties := make(map[any]any, len(headers))
+	for name, value := range headers {
+		switch name {
+		case HeaderSubject:
+			properties[propertySubject] = value
+		case HeaderReplyTo:
+			properties[propertyReplyTo] = value
+		case HeaderCorrelationID:
+			properties[propertyCorrelationID] = value
+		case HeaderContentType:
+			properties[propertyContentType] = Symbol(value)
+		default:
+			applicationProperties[name] = value
+		}
+	}
+
+	e := &encoder{}
+	sections := []any{
+		&Described{Descriptor: descriptorProperties, Value: properties},
+	}
+	if len(applicationProperties) > 0 {
+		sections = append(sections, &Described{Descriptor: descriptorApplicationProperties, Value: applicationProperties})
+	}
+	sections = append(sections, &Described{Descriptor: descriptorData, Value: content})
+
+	for _, section := range sections {
+		if err := e.write(section); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.buf, nil
+}
@@ -0,0 +1,599 @@
+package amqp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// format codes of the AMQP 1.0 type system.
+const (
+	codeDescribed  byte = 0x00
+	codeNull       byte = 0x40
+	codeTrue       byte = 0x41
+	codeFalse      byte = 0x42
+	codeUint0      byte = 0x43
+	codeUlong0     byte = 0x44
+	codeList0      byte = 0x45
+	codeUbyte      byte = 0x50
+	codeByte       byte = 0x51
+	codeSmallUint  byte = 0x52
+	codeSmallUlong byte = 0x53
+	codeSmallInt   byte = 0x54
+	codeSmallLong  byte = 0x55
+	codeBool       byte = 0x56
+	codeUshort     byte = 0x60
+	codeShort      byte = 0x61
+	codeUint       byte = 0x70
+	codeInt        byte = 0x71
+	codeFloat      byte = 0x72
+	codeChar       byte = 0x73
+	codeDecimal32  byte = 0x74
+	codeUlong      byte = 0x80
+	codeLong       byte = 0x81
+	codeDouble     byte = 0x82
+	codeTimestamp  byte = 0x83
+	codeDecimal64  byte = 0x84
+	codeDecimal128 byte = 0x94
+	codeUUID       byte = 0x98
+	codeVbin8      byte = 0xa0
+	codeStr8       byte = 0xa1
+	codeSym8       byte = 0xa3
+	codeVbin32     byte = 0xb0
+	codeStr32      byte = 0xb1
+	codeSym32      byte = 0xb3
+	codeList8      byte = 0xc0
+	codeMap8       byte = 0xc1
+	codeList32     byte = 0xd0
+	codeMap32      byte = 0xd1
+	codeArray8     byte = 0xe0
+	codeArray32    byte = 0xf0
+)
+
+// ErrInvalidEncoding is returned when a value does not follow the AMQP 1.0 type system encoding.
+var ErrInvalidEncoding = errors.New("invalid amqp encoding")
+
+// The AMQP types are decoded to the Go types: null is nil, boolean is bool, ubyte, ushort, uint and ulong
+// are uint8 to uint64, byte, short, int and long are int8 to int64, float and double are float32 and float64,
+// timestamp is time.Time, binary is []byte, string is string, list is []any and map is map[any]any.
+// The types without Go counterpart have their own type, encoding a decoded value restore its AMQP type.
+
+// Symbol is an AMQP symbol, an ASCII string used for names and keys.
+type Symbol string
+
+// UUID is an AMQP uuid.
+type UUID [16]byte
+
+// Char is an AMQP char, a single UTF-32 code point.
+type Char rune
+
+// Decimal is an AMQP decimal32, decimal64 or decimal128 kept in its encoded form.
+type Decimal []byte
+
+// Array is an AMQP array, a sequence of values of a single type.
+type Array []any
+
+// Described is a value annotated with a descriptor, e.g. a performative or a message section.
+type Described struct {
+	Descriptor any
+	Value      any
+}
+
+// encoder append AMQP encoded values to a buffer.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+// variable write a variable width value with its one or four bytes length.
+func (e *encoder) variable(code8, code32 byte, data []byte) {
+	if len(data) <= math.MaxUint8 {
+		e.byte(code8)
+		e.byte(byte(len(data)))
+	} else {
+		e.byte(code32)
+		e.uint32(uint32(len(data)))
+	}
+	e.buf = append(e.buf, data...)
+}
+
+// compound write a list or a map body, the size covering the count and the items.
+func (e *encoder) compound(code8, code32 byte, count int, items []byte) {
+	if len(items)+1 <= math.MaxUint8 && count <= math.MaxUint8 {
+		e.byte(code8)
+		e.byte(byte(len(items) + 1))
+		e.byte(byte(count))
+	} else {
+		e.byte(code32)
+		e.uint32(uint32(len(items) + 4)) //nolint:mnd
+		e.uint32(uint32(count))
+	}
+	e.buf = append(e.buf, items...)
+}
+
+//nolint:gocyclo,cyclop
+func (e *encoder) write(v any) error {
+	switch v := v.(type) {
+	case nil:
+		e.byte(codeNull)
+	case bool:
+		if v {
+			e.byte(codeTrue)
+		} else {
+			e.byte(codeFalse)
+		}
+	case uint8:
+		e.byte(codeUbyte)
+		e.byte(v)
+	case uint16:
+		e.byte(codeUshort)
+		e.uint16(v)
+	case uint32:
+		switch {
+		case v == 0:
+			e.byte(codeUint0)
+		case v <= math.MaxUint8:
+			e.byte(codeSmallUint)
+			e.byte(byte(v))
+		default:
+			e.byte(codeUint)
+			e.uint32(v)
+		}
+	case uint64:
+		switch {
+		case v == 0:
+			e.byte(codeUlong0)
+		case v <= math.MaxUint8:
+			e.byte(codeSmallUlong)
+			e.byte(byte(v))
+		default:
+			e.byte(codeUlong)
+			e.uint64(v)
+		}
+	case int8:
+		e.byte(codeByte)
+		e.byte(byte(v))
+	case int16:
+		e.byte(codeShort)
+		e.uint16(uint16(v))
+	case int32:
+		if v >= math.MinInt8 && v <= math.MaxInt8 {
+			e.byte(codeSmallInt)
+			e.byte(byte(int8(v)))
+		} else {
+			e.byte(codeInt)
+			e.uint32(uint32(v))
+		}
+	case int64:
+		if v >= math.MinInt8 && v <= math.MaxInt8 {
+			e.byte(codeSmallLong)
+			e.byte(byte(int8(v)))
+		} else {
+			e.byte(codeLong)
+			e.uint64(uint64(v))
+		}
+	case int:
+		return e.write(int64(v))
+	case float32:
+		e.byte(codeFloat)
+		e.uint32(math.Float32bits(v))
+	case float64:
+		e.byte(codeDouble)
+		e.uint64(math.Float64bits(v))
+	case time.Time:
+		e.byte(codeTimestamp)
+		e.uint64(uint64(v.UnixMilli()))
+	case Char:
+		e.byte(codeChar)
+		e.uint32(uint32(v))
+	case Decimal:
+		switch len(v) {
+		case 4: //nolint:mnd
+			e.byte(codeDecimal32)
+		case 8: //nolint:mnd
+			e.byte(codeDecimal64)
+		case 16: //nolint:mnd
+			e.byte(codeDecimal128)
+		default:
+			return fmt.Errorf("%w: decimal of %d bytes", ErrInvalidEncoding, len(v))
+		}
+		e.buf = append(e.buf, v...)
+	case UUID:
+		e.byte(codeUUID)
+		e.buf = append(e.buf, v[:]...)
+	case []byte:
+		e.variable(codeVbin8, codeVbin32, v)
+	case string:
+		e.variable(codeStr8, codeStr32, []byte(v))
+	case Symbol:
+		e.variable(codeSym8, codeSym32, []byte(v))
+	case []any:
+		if len(v) == 0 {
+			e.byte(codeList0)
+			return nil
+		}
+		items := &encoder{}
+		for _, item := range v {
+			if err := items.write(item); err != nil {
+				return err
+			}
+		}
+		e.compound(codeList8, codeList32, len(v), items.buf)
+	case map[any]any:
+		items := &encoder{}
+		for key, value := range v {
+			if err := items.write(key); err != nil {
+				return err
+			}
+			if err := items.write(value); err != nil {
+				return err
+			}
+		}
+		e.compound(codeMap8, codeMap32, len(v)*2, items.buf) //nolint:mnd
+	case Array:
+		return e.writeArray(v)
+	case *Described:
+		e.byte(codeDescribed)
+		if err := e.write(v.Descriptor); err != nil {
+			return err
+		}
+		return e.write(v.Value)
+	default:
+		return fmt.Errorf("%w: unsupported type %T", ErrInvalidEncoding, v)
+	}
+
+	return nil
+}
+
+// writeArray write an array with the wide constructor of its element type, only symbols and strings
+// are sent by the handler.
+func (e *encoder) writeArray(v Array) error {
+	items := &encoder{}
+	var code byte
+	for _, item := range v {
+		switch item := item.(type) {
+		case Symbol:
+			code = codeSym32
+			items.uint32(uint32(len(item)))
+			items.buf = append(items.buf, item...)
+		case string:
+			code = codeStr32
+			items.uint32(uint32(len(item)))
+			items.buf = append(items.buf, item...)
+		default:
+			return fmt.Errorf("%w: unsupported array of %T", ErrInvalidEncoding, item)
+		}
+	}
+	if len(v) == 0 {
+		code = codeNull
+	}
+
+	e.byte(codeArray32)
+	e.uint32(uint32(len(items.buf) + 5)) //nolint:mnd
+	e.uint32(uint32(len(v)))
+	e.byte(code)
+	e.buf = append(e.buf, items.buf...)
+
+	return nil
+}
+
+// decoder read AMQP encoded values from a buffer.
+type decoder struct {
+	buf []byte
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.buf) < n {
+		return nil, fmt.Errorf("%w: truncated value", ErrInvalidEncoding)
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	b, err := d.next(2) //nolint:mnd
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.next(4) //nolint:mnd
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.next(8) //nolint:mnd
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// size read the one or four bytes length of a variable width or compound value.
+func (d *decoder) size(wide bool) (int, error) {
+	if !wide {
+		b, err := d.byte()
+		return int(b), err
+	}
+
+	v, err := d.uint32()
+	if uint64(v) > uint64(len(d.buf)) {
+		return 0, fmt.Errorf("%w: truncated value", ErrInvalidEncoding)
+	}
+
+	return int(v), err
+}
+
+func (d *decoder) read() (any, error) {
+	code, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.value(code)
+}
+
+//nolint:gocyclo,cyclop,funlen
+func (d *decoder) value(code byte) (any, error) {
+	switch code {
+	case codeDescribed:
+		descriptor, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+		return &Described{Descriptor: descriptor, Value: value}, nil
+	case codeNull:
+		return nil, nil
+	case codeTrue:
+		return true, nil
+	case codeFalse:
+		return false, nil
+	case codeBool:
+		b, err := d.byte()
+		return b != 0, err
+	case codeUbyte:
+		return d.byte()
+	case codeUshort:
+		return d.uint16()
+	case codeUint0:
+		return uint32(0), nil
+	case codeSmallUint:
+		b, err := d.byte()
+		return uint32(b), err
+	case codeUint:
+		return d.uint32()
+	case codeUlong0:
+		return uint64(0), nil
+	case codeSmallUlong:
+		b, err := d.byte()
+		return uint64(b), err
+	case codeUlong:
+		return d.uint64()
+	case codeByte:
+		b, err := d.byte()
+		return int8(b), err
+	case codeShort:
+		v, err := d.uint16()
+		return int16(v), err
+	case codeSmallInt:
+		b, err := d.byte()
+		return int32(int8(b)), err
+	case codeInt:
+		v, err := d.uint32()
+		return int32(v), err
+	case codeSmallLong:
+		b, err := d.byte()
+		return int64(int8(b)), err
+	case codeLong:
+		v, err := d.uint64()
+		return int64(v), err
+	case codeFloat:
+		v, err := d.uint32()
+		return math.Float32frombits(v), err
+	case codeDouble:
+		v, err := d.uint64()
+		return math.Float64frombits(v), err
+	case codeTimestamp:
+		v, err := d.uint64()
+		return time.UnixMilli(int64(v)).UTC(), err
+	case codeChar:
+		v, err := d.uint32()
+		return Char(v), err
+	case codeDecimal32, codeDecimal64, codeDecimal128:
+		n := map[byte]int{codeDecimal32: 4, codeDecimal64: 8, codeDecimal128: 16}[code]
+		b, err := d.next(n)
+		return Decimal(append([]byte(nil), b...)), err
+	case codeUUID:
+		b, err := d.next(16) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+		var id UUID
+		copy(id[:], b)
+		return id, nil
+	case codeVbin8, codeVbin32:
+		b, err := d.variable(code == codeVbin32)
+		return append([]byte(nil), b...), err
+	case codeStr8, codeStr32:
+		b, err := d.variable(code == codeStr32)
+		return string(b), err
+	case codeSym8, codeSym32:
+		b, err := d.variable(code == codeSym32)
+		return Symbol(b), err
+	case codeList0:
+		return []any{}, nil
+	case codeList8, codeList32:
+		return d.list(code == codeList32)
+	case codeMap8, codeMap32:
+		return d.mapValue(code == codeMap32)
+	case codeArray8, codeArray32:
+		return d.array(code == codeArray32)
+	default:
+		return nil, fmt.Errorf("%w: unknown format code 0x%02x", ErrInvalidEncoding, code)
+	}
+}
+
+func (d *decoder) variable(wide bool) ([]byte, error) {
+	n, err := d.size(wide)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.next(n)
+}
+
+// compound return a decoder over the items of a list, map or array and their count.
+func (d *decoder) compound(wide bool) (*decoder, int, error) {
+	n, err := d.size(wide)
+	if err != nil {
+		return nil, 0, err
+	}
+	body, err := d.next(n)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := &decoder{buf: body}
+	count, err := items.size(wide)
+	if err != nil {
+		return nil, 0, err
+	}
+	if count > len(items.buf) {
+		return nil, 0, fmt.Errorf("%w: count %d exceed the size", ErrInvalidEncoding, count)
+	}
+
+	return items, count, nil
+}
+
+func (d *decoder) list(wide bool) ([]any, error) {
+	items, count, err := d.compound(wide)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]any, 0, count)
+	for range count {
+		item, err := items.read()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+
+	return list, nil
+}
+
+func (d *decoder) mapValue(wide bool) (map[any]any, error) {
+	items, count, err := d.compound(wide)
+	if err != nil {
+		return nil, err
+	}
+	if count%2 != 0 {
+		return nil, fmt.Errorf("%w: odd map count", ErrInvalidEncoding)
+	}
+
+	m := make(map[any]any, count/2) //nolint:mnd
+	for range count / 2 {
+		key, err := items.read()
+		if err != nil {
+			return nil, err
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("%w: map key of type %T", ErrInvalidEncoding, key)
+		}
+		value, err := items.read()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+
+	return m, nil
+}
+
+func (d *decoder) array(wide bool) (Array, error) {
+	items, count, err := d.compound(wide)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := items.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	var descriptor any
+	if code == codeDescribed {
+		if descriptor, err = items.read(); err != nil {
+			return nil, err
+		}
+		if code, err = items.byte(); err != nil {
+			return nil, err
+		}
+	}
+
+	array := make(Array, 0, count)
+	for range count {
+		item, err := items.value(code)
+		if err != nil {
+			return nil, err
+		}
+		if descriptor != nil {
+			item = &Described{Descriptor: descriptor, Value: item}
+		}
+		array = append(array, item)
+	}
+
+	return array, nil
+}
+
+// marshal return the AMQP encoding of the value.
+func marshal(v any) ([]byte, error) {
+	e := &encoder{}
+	if err := e.write(v); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
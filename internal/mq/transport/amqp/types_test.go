@@ -0,0 +1,88 @@
+package amqp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypes_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{name: "Null", value: nil},
+		{name: "Bool", value: true},
+		{name: "Ubyte", value: uint8(7)},
+		{name: "Ushort", value: uint16(65535)},
+		{name: "Uint0", value: uint32(0)},
+		{name: "Small_Uint", value: uint32(200)},
+		{name: "Uint", value: uint32(70000)},
+		{name: "Ulong", value: uint64(1 << 40)},
+		{name: "Small_Int", value: int32(-5)},
+		{name: "Long", value: int64(-1 << 40)},
+		{name: "Double", value: 21.5},
+		{name: "Timestamp", value: time.UnixMilli(1700000000000).UTC()},
+		{name: "UUID", value: UUID{1, 2, 3}},
+		{name: "Binary", value: []byte{0, 1, 2}},
+		{name: "Str8", value: "orders.created"},
+		{name: "Str32", value: string(make([]byte, 300))},
+		{name: "Symbol", value: Symbol("amqp:not-found")},
+		{name: "Empty_List", value: []any{}},
+		{name: "List", value: []any{"a", uint32(1), nil, true}},
+		{name: "Map", value: map[any]any{"key": "value", Symbol("count"): int64(3)}},
+		{name: "Array", value: Array{Symbol("PLAIN"), Symbol("ANONYMOUS")}},
+		{name: "Described", value: &Described{Descriptor: descriptorSource, Value: []any{"orders.*"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := marshal(tt.value)
+			require.NoError(t, err)
+
+			d := &decoder{buf: data}
+			got, err := d.read()
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, got)
+			assert.Empty(t, d.buf)
+		})
+	}
+}
+
+func TestTypes_Truncated(t *testing.T) {
+	t.Parallel()
+
+	data, err := marshal([]any{"orders.created", uint64(1 << 40)})
+	require.NoError(t, err)
+
+	for i := range len(data) {
+		d := &decoder{buf: data[:i]}
+		_, err := d.read()
+		assert.ErrorIs(t, err, ErrInvalidEncoding, "truncated at %d bytes", i)
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	headers := map[string]string{
+		HeaderContentType:   "application/json",
+		HeaderCorrelationID: "42",
+		"tenant":            "acme",
+	}
+
+	data, err := encodeMessage("orders.created", "message-1", []byte(`{"id":1}`), headers)
+	require.NoError(t, err)
+
+	msg, err := decodeMessage(data)
+	require.NoError(t, err)
+	assert.Equal(t, "orders.created", msg.to)
+	assert.Equal(t, []byte(`{"id":1}`), msg.content)
+	assert.Equal(t, headers, msg.headers)
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Conns track the connections and the goroutines of a transport, so they can be closed and waited for on shutdown.
+// The zero value is ready to use.
+type Conns struct {
+	mu     sync.Mutex
+	closed bool
+	conns  map[domain.Connection]struct{}
+	wg     sync.WaitGroup
+}
+
+// Go run fn in a goroutine Close wait for, it return false without running fn once closed.
+func (c *Conns) Go(fn func()) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+		fn()
+	}()
+
+	return true
+}
+
+// Track register a connection Close close and wait for until it is untracked.
+// It return false once closed, the caller then close the connection itself.
+func (c *Conns) Track(conn domain.Connection) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	if c.conns == nil {
+		c.conns = make(map[domain.Connection]struct{})
+	}
+	c.conns[conn] = struct{}{}
+	c.wg.Add(1)
+
+	return true
+}
+
+// Untrack drop a connection registered with Track.
+func (c *Conns) Untrack(conn domain.Connection) {
+	c.mu.Lock()
+	delete(c.conns, conn)
+	c.mu.Unlock()
+
+	c.wg.Done()
+}
+
+// Close close the tracked connections, refuse the new ones and wait up to timeout
+// for the connections and the goroutines to return. It report whether they all returned in time.
+func (c *Conns) Close(timeout time.Duration) bool {
+	c.mu.Lock()
+	c.closed = true
+	for conn := range c.conns {
+		_ = conn.Close()
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Sink hand a frame sent by the broker to a connection of the transport,
+// onSent, when not nil, is called with the outcome once the frame is written or dropped.
+type Sink func(ctx context.Context, conn domain.Connection, data []byte, onSent func(error))
+
+// Dispatcher hand the events the broker publish for a transport to its connections:
+// the frames to send go to the sink and the throttled connections to the throttler.
+type Dispatcher struct {
+	Transport domain.TransportType
+	Sink      Sink
+	Throttler *Throttler
+}
+
+// Run subscribe to the events of the transport and dispatch them until the context is done,
+// the dispatching goroutines are tracked by conns.
+func (d Dispatcher) Run(ctx context.Context, eb domain.IEventBus, conns *Conns) {
+	msgSenderCh := eb.Subscribe(string(domain.EventTypeSendMessage))
+	throttleCh := eb.Subscribe(string(domain.EventTypeThrottleConnection))
+
+	conns.Go(func() {
+		d.SendMessages(ctx, msgSenderCh)
+	})
+
+	conns.Go(func() {
+		d.Throttle(ctx, throttleCh)
+	})
+}
+
+// SendMessages hand the frames sent to the connections of the transport to the sink.
+func (d Dispatcher) SendMessages(ctx context.Context, ch <-chan domain.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			if c, ok := evt.(*events.SendMessageEvent); ok && c.Transport == d.Transport {
+				d.Sink(ctx, c.Conn, c.Message, c.OnSent)
+			}
+		}
+	}
+}
+
+// Throttle pause the reads of the connections of the transport throttled by the broker.
+func (d Dispatcher) Throttle(ctx context.Context, ch <-chan domain.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			if c, ok := evt.(*events.ThrottleConnectionEvent); ok && c.Transport == d.Transport {
+				d.Throttler.Throttle(c.Conn, c.Delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestDispatcher_SendMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		transport domain.TransportType
+		writeErr  error
+		canceled  bool
+	}{
+		{name: "SendMessages_With_SendMessageEvent", transport: domain.TransportTypeTCP},
+		{name: "SendMessages_With_Write_Error", transport: domain.TransportTypeTCP, writeErr: errors.New("write failed")},
+		{name: "SendMessages_Skip_Other_Transport", transport: domain.TransportTypeWebSocket},
+		{name: "SendMessages_With_Context_Cancel", transport: domain.TransportTypeTCP, canceled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tt.canceled {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(context.Background())
+				cancel()
+			}
+
+			mockConn := mocks.NewMockConnection(t)
+			sent := tt.transport == domain.TransportTypeTCP && !tt.canceled
+			if sent {
+				mockConn.On("SetWriteDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
+				mockConn.On("Write", []byte("test message")).Return(12, tt.writeErr).Once()
+			}
+
+			var onSent []error
+			ch := make(chan domain.Event, 1)
+			if !tt.canceled {
+				ch <- &events.SendMessageEvent{
+					ClientID:  "test-client",
+					Conn:      mockConn,
+					Message:   []byte("test message"),
+					Transport: tt.transport,
+					OnSent:    func(err error) { onSent = append(onSent, err) },
+					BaseEvent: events.BaseEvent{
+						EventType: domain.EventTypeSendMessage,
+					},
+				}
+			}
+			close(ch)
+
+			Dispatcher{
+				Transport: domain.TransportTypeTCP,
+				Sink:      newTestFrameServer().Sink,
+			}.SendMessages(ctx, ch)
+
+			if sent {
+				assert.Equal(t, []error{tt.writeErr}, onSent)
+			} else {
+				assert.Empty(t, onSent)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Throttle(t *testing.T) {
+	t.Parallel()
+
+	throttled := mocks.NewMockConnection(t)
+	other := mocks.NewMockConnection(t)
+
+	ch := make(chan domain.Event, 2)
+	ch <- &events.ThrottleConnectionEvent{
+		Conn:      throttled,
+		Delay:     time.Hour,
+		Transport: domain.TransportTypeTCP,
+		BaseEvent: events.BaseEvent{EventType: domain.EventTypeThrottleConnection},
+	}
+	ch <- &events.ThrottleConnectionEvent{
+		Conn:      other,
+		Delay:     time.Hour,
+		Transport: domain.TransportTypeMQTT,
+		BaseEvent: events.BaseEvent{EventType: domain.EventTypeThrottleConnection},
+	}
+	close(ch)
+
+	throttler := &Throttler{}
+	Dispatcher{Transport: domain.TransportTypeTCP, Throttler: throttler}.Throttle(context.Background(), ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, throttler.Wait(ctx, throttled), "The connection of the transport should be throttled")
+	assert.True(t, throttler.Wait(ctx, other), "The connection of another transport should be left alone")
+}
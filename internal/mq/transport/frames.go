@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+)
+
+// writeTimeout bound the time a frame has to be written to a connection.
+const writeTimeout = 5 * time.Second
+
+// FrameServer serve the connections carrying the HopperMQ frames as is, e.g. TCP, WebSocket and in-process:
+// it publish the frames read from a connection on the event bus and write the frames the broker send.
+type FrameServer struct {
+	Transport domain.TransportType
+	Logger    *slog.Logger
+	// MaxFrameSize is the maximum size in bytes of a frame read from a connection, zero disable the limit.
+	MaxFrameSize int
+	// ReadTimeout set a read deadline before each frame, a connection idle past the deadline stay open.
+	ReadTimeout time.Duration
+	Throttler   *Throttler
+}
+
+// Serve publish the connection to the broker then read its frames until the client disconnect,
+// identity is the subject of the client certificate, if any. The connection is tracked by conns
+// and closed when Serve return.
+func (s FrameServer) Serve(ctx context.Context, eb domain.IEventBus, conns *Conns, conn domain.Connection, identity string) {
+	if !conns.Track(conn) {
+		s.Logger.Warn("transport closed, refusing connection", "transport", s.Transport)
+		s.closeConn(conn)
+		return
+	}
+	defer conns.Untrack(conn)
+	defer s.closeConn(conn)
+
+	if eb == nil {
+		s.Logger.Warn("EventBus not registered, skipping event publishing")
+		return
+	}
+
+	evt := &events.NewConnectionEvent{
+		Conn:      conn,
+		Identity:  identity,
+		Transport: s.Transport,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeNewConnection,
+		},
+	}
+
+	if err := eb.Publish(ctx, evt); err != nil {
+		s.Logger.Warn("failed to publish new connection event", "error", err)
+		return
+	}
+
+	defer s.Throttler.Forget(conn)
+
+	reader := bufio.NewReader(conn)
+	for {
+		if !s.Throttler.Wait(ctx, conn) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			if err := s.Receive(ctx, eb, conn, reader); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Receive read a frame from the connection and publish it, it return an error once the client is gone.
+func (s FrameServer) Receive(ctx context.Context, eb domain.IEventBus, conn domain.Connection, reader io.Reader) error {
+	if s.ReadTimeout > 0 {
+		if err := conn.SetReadDeadline(time.Now().Add(s.ReadTimeout)); err != nil {
+			s.Logger.Warn("failed to set read deadline", "error", err)
+			return err
+		}
+	}
+
+	msg, err := protocol.ReadFrame(reader, s.MaxFrameSize)
+	if err != nil {
+		if errors.Is(err, domain.ErrFrameTooLarge) {
+			return s.rejectFrame(ctx, eb, conn, err)
+		}
+
+		var netErr net.Error
+		if s.ReadTimeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+			return nil
+		}
+
+		s.Logger.Info("client disconnected", "transport", s.Transport, "error", err)
+		evt := &events.ClientDisconnectedEvent{
+			Transport: s.Transport,
+			Conn:      conn,
+			BaseEvent: events.BaseEvent{
+				EventType: domain.EventTypeConnectionClosed,
+			},
+		}
+
+		if err := eb.Publish(context.WithoutCancel(ctx), evt); err != nil {
+			s.Logger.Warn("failed to publish client disconnected event", "error", err)
+		}
+
+		return err
+	}
+
+	evt := &events.MessageReceivedEvent{
+		Conn:      conn,
+		Message:   msg,
+		Transport: s.Transport,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeReceiveMessage,
+		},
+	}
+
+	if err := eb.Publish(ctx, evt); err != nil {
+		s.Logger.Warn("failed to publish message event", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Send write the frame to the connection.
+func (s FrameServer) Send(conn domain.Connection, data []byte) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write(data); err != nil {
+		s.Logger.Warn("error sending message", "transport", s.Transport, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Sink write the frame to the connection and report the outcome, it is the Sink of the frame transports.
+func (s FrameServer) Sink(_ context.Context, conn domain.Connection, data []byte, onSent func(error)) {
+	err := s.Send(conn, data)
+	if onSent != nil {
+		onSent(err)
+	}
+}
+
+func (s FrameServer) rejectFrame(ctx context.Context, eb domain.IEventBus, conn domain.Connection, reason error) error {
+	s.Logger.Warn("frame rejected", "transport", s.Transport, "error", reason)
+
+	evt := &events.InvalidFrameEvent{
+		Conn:      conn,
+		Err:       reason,
+		Transport: s.Transport,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeInvalidFrame,
+		},
+	}
+
+	if err := eb.Publish(ctx, evt); err != nil {
+		s.Logger.Warn("failed to publish invalid frame event", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s FrameServer) closeConn(conn domain.Connection) {
+	if err := conn.Close(); err != nil {
+		s.Logger.Warn("failed to close connection", "error", err)
+	}
+}
@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func newTestFrameServer() FrameServer {
+	return FrameServer{
+		Transport: domain.TransportTypeTCP,
+		Logger:    slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		Throttler: &Throttler{},
+	}
+}
+
+func TestFrameServer_Serve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		identity   string
+		setupMocks func(*mocks.MockConnection, *mocks.MockIEventBus)
+	}{
+		{
+			name: "Serve_Handles_Connection_Lifecycle_Correctly",
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					newConnEvt, ok := evt.(*events.NewConnectionEvent)
+					return ok && newConnEvt.Transport == domain.TransportTypeTCP
+				})).Return(nil).Once()
+
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Return(0, io.EOF).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					disconnEvt, ok := evt.(*events.ClientDisconnectedEvent)
+					return ok && disconnEvt.Transport == domain.TransportTypeTCP
+				})).Return(nil).Once()
+
+				mockConn.On("Close").Return(nil).Once()
+			},
+		},
+		{
+			name:     "Serve_Handles_Successful_Message_Reading",
+			identity: "CN=client",
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					newConnEvt, ok := evt.(*events.NewConnectionEvent)
+					return ok && newConnEvt.Identity == "CN=client"
+				})).Return(nil).Once()
+
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
+					buf := args[0].([]byte)
+					copy(buf, encodedFrame("hello world"))
+				}).Return(len(encodedFrame("hello world")), nil).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					msgEvt, ok := evt.(*events.MessageReceivedEvent)
+					return ok && bytes.Equal(msgEvt.Message, encodedFrame("hello world"))
+				})).Return(nil).Once()
+
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Return(0, io.EOF).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					_, ok := evt.(*events.ClientDisconnectedEvent)
+					return ok
+				})).Return(nil).Once()
+
+				mockConn.On("Close").Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockConn := mocks.NewMockConnection(t)
+			mockEB := mocks.NewMockIEventBus(t)
+			tt.setupMocks(mockConn, mockEB)
+
+			newTestFrameServer().Serve(context.Background(), mockEB, &Conns{}, mockConn, tt.identity)
+		})
+	}
+}
+
+func TestFrameServer_Serve_After_Close(t *testing.T) {
+	t.Parallel()
+
+	mockConn := mocks.NewMockConnection(t)
+	mockConn.On("Close").Return(nil).Once()
+
+	conns := &Conns{}
+	assert.True(t, conns.Close(time.Second))
+	assert.False(t, conns.Go(func() {}), "No goroutine should start once closed")
+
+	newTestFrameServer().Serve(context.Background(), mocks.NewMockIEventBus(t), conns, mockConn, "")
+}
+
+func TestFrameServer_Receive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		configure   func(*FrameServer)
+		setupMocks  func(*mocks.MockConnection, *mocks.MockIEventBus)
+		expectError bool
+	}{
+		{
+			name: "ReceiveMessage_Successfully",
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
+					buf := args[0].([]byte)
+					copy(buf, encodedFrame("hello world"))
+				}).Return(len(encodedFrame("hello world")), nil).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					msgEvt, ok := evt.(*events.MessageReceivedEvent)
+					return ok &&
+						msgEvt.Transport == domain.TransportTypeTCP &&
+						bytes.Equal(msgEvt.Message, encodedFrame("hello world"))
+				})).Return(nil).Once()
+			},
+			expectError: false,
+		},
+		{
+			name: "ReceiveMessage_With_EOF",
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Return(0, io.EOF).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					disconnEvt, ok := evt.(*events.ClientDisconnectedEvent)
+					return ok && disconnEvt.Transport == domain.TransportTypeTCP
+				})).Return(nil).Once()
+			},
+			expectError: true,
+		},
+		{
+			name: "ReceiveMessage_With_Read_Timeout",
+			configure: func(s *FrameServer) {
+				s.ReadTimeout = time.Second
+			},
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockConn.On("SetReadDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Return(0, timeoutError{}).Once()
+			},
+			expectError: false,
+		},
+		{
+			name: "ReceiveMessage_Frame_Too_Large",
+			configure: func(s *FrameServer) {
+				s.MaxFrameSize = 8
+			},
+			setupMocks: func(mockConn *mocks.MockConnection, mockEB *mocks.MockIEventBus) {
+				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
+					buf := args[0].([]byte)
+					copy(buf, encodedFrame("hello world"))
+				}).Return(len(encodedFrame("hello world")), nil).Once()
+
+				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+					invalidEvt, ok := evt.(*events.InvalidFrameEvent)
+					return ok &&
+						invalidEvt.Conn == mockConn &&
+						errors.Is(invalidEvt.Err, domain.ErrFrameTooLarge)
+				})).Return(nil).Once()
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockConn := mocks.NewMockConnection(t)
+			mockEB := mocks.NewMockIEventBus(t)
+			tt.setupMocks(mockConn, mockEB)
+
+			s := newTestFrameServer()
+			if tt.configure != nil {
+				tt.configure(&s)
+			}
+
+			err := s.Receive(context.Background(), mockEB, mockConn, bufio.NewReader(mockConn))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func encodedFrame(payload string) []byte {
+	frame := make([]byte, 6, 6+len(payload))
+	binary.BigEndian.PutUint16(frame[0:2], uint16(len(payload)))
+	binary.BigEndian.PutUint16(frame[2:4], uint16(domain.DOFF4))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(domain.FrameTypeMessage))
+	return append(frame, payload...)
+}
@@ -0,0 +1,201 @@
+// Package inproc provides the in-process transport handler, connecting clients living in the broker process
+// through in-memory pipes instead of sockets.
+package inproc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// ErrNotRunning is returned when dialing a handler which is not running.
+var ErrNotRunning = errors.New("inproc transport not running")
+
+// InProc is an in-process handler, the connections are dialed with Dial.
+type InProc struct {
+	logger       *slog.Logger
+	maxFrameSize int
+
+	throttler transport.Throttler
+	conns     transport.Conns
+
+	eb domain.IEventBus
+
+	accept chan net.Conn
+
+	mu      sync.Mutex
+	running bool
+	closed  chan struct{}
+
+	cancel context.CancelFunc
+}
+
+type config struct {
+	logger       *slog.Logger
+	maxFrameSize int
+}
+
+// Option configure the in-process handler.
+type Option func(*config) error
+
+// WithLogger inject the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+
+		return nil
+	}
+}
+
+// WithMaxFrameSize set the maximum size in bytes of a frame read from a connection.
+func WithMaxFrameSize(size int) Option {
+	return func(c *config) error {
+		if size <= protocol.FrameHeaderSize {
+			return domain.ErrInvalidFrame
+		}
+		c.maxFrameSize = size
+
+		return nil
+	}
+}
+
+// NewInProc return the new in-process handler.
+func NewInProc(opts ...Option) (*InProc, error) {
+	handlerConfig := &config{
+		maxFrameSize: protocol.DefaultMaxFrameSize,
+	}
+	for _, opt := range opts {
+		if err := opt(handlerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if handlerConfig.logger == nil {
+		handlerConfig.logger = slog.Default()
+	}
+
+	return &InProc{
+		logger:       handlerConfig.logger,
+		maxFrameSize: handlerConfig.maxFrameSize,
+		accept:       make(chan net.Conn),
+		closed:       make(chan struct{}),
+	}, nil
+}
+
+// Dial open a connection to the broker, the connection carry the encoded frames like a TCP connection.
+// It block until the handler accept the connection or the context is done.
+func (i *InProc) Dial(ctx context.Context) (net.Conn, error) {
+	i.mu.Lock()
+	running := i.running
+	i.mu.Unlock()
+	if !running {
+		return nil, ErrNotRunning
+	}
+
+	client, server := net.Pipe()
+	select {
+	case i.accept <- server:
+		return client, nil
+	case <-i.closed:
+		_ = client.Close()
+		_ = server.Close()
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		_ = client.Close()
+		_ = server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// HandleConnection serve the dialed connections until the context is done.
+func (i *InProc) HandleConnection(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-i.closed:
+			return net.ErrClosed
+		case conn := <-i.accept:
+			go i.frames().Serve(ctx, i.eb, &i.conns, conn, "")
+		}
+	}
+}
+
+// frames return the frame server of the in-process connections.
+func (i *InProc) frames() transport.FrameServer {
+	return transport.FrameServer{
+		Transport:    domain.TransportTypeInProc,
+		Logger:       i.logger,
+		MaxFrameSize: i.maxFrameSize,
+		Throttler:    &i.throttler,
+	}
+}
+
+// Run wil start the in-process component.
+func (i *InProc) Run(ctx context.Context) error {
+	i.logger.Info("starting InProc component")
+
+	ctx, i.cancel = context.WithCancel(ctx)
+
+	transport.Dispatcher{
+		Transport: domain.TransportTypeInProc,
+		Sink:      i.frames().Sink,
+		Throttler: &i.throttler,
+	}.Run(ctx, i.eb, &i.conns)
+
+	i.mu.Lock()
+	i.running = true
+	i.mu.Unlock()
+
+	go func() {
+		if err := i.HandleConnection(ctx); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, net.ErrClosed) {
+			i.logger.Warn("InProc Handler failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop will shut down gracefully the in-process component.
+func (i *InProc) Stop(_ context.Context) error {
+	i.logger.Info("stopping InProc Component")
+
+	i.mu.Lock()
+	if i.running {
+		i.running = false
+		close(i.closed)
+	}
+	i.mu.Unlock()
+
+	if i.cancel != nil {
+		i.cancel()
+	}
+
+	if i.conns.Close(shutdownTimeout) {
+		i.logger.Info("All inproc connections closed gracefully")
+	} else {
+		i.logger.Warn("Timeout waiting for inproc connections to close")
+	}
+
+	return nil
+}
+
+// Name will return the component name.
+func (i *InProc) Name() string {
+	return "inproc-handler"
+}
+
+// RegisterEventBus will attach the event bus to the component.
+func (i *InProc) RegisterEventBus(eb domain.IEventBus) {
+	i.eb = eb
+	i.logger.Info("EventBus registered with InProc", "service", i.Name())
+}
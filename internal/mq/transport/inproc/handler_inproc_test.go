@@ -0,0 +1,132 @@
+package inproc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
+)
+
+func startInProc(t *testing.T) *InProc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := slog.New(slog.DiscardHandler)
+	eb := events.NewEventBus(10)
+
+	i, err := NewInProc(WithLogger(logger))
+	require.NoError(t, err)
+	i.RegisterEventBus(eb)
+
+	broker := core.NewBroker(logger, eb)
+	broker.RegisterEventBus(eb)
+	require.NoError(t, broker.Run(ctx))
+	require.NoError(t, i.Run(ctx))
+	t.Cleanup(func() { _ = i.Stop(context.Background()) })
+
+	return i
+}
+
+func readFrame(t *testing.T, s *serializer.Serializer, conn net.Conn, reader *bufio.Reader) domain.Frame {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	data, err := protocol.ReadFrame(reader, 0)
+	require.NoError(t, err)
+
+	frame, err := s.DeserializeFrame(data)
+	require.NoError(t, err)
+
+	return frame
+}
+
+func TestInProc_Options(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option Option
+		want   bool
+	}{
+		{name: "WithLogger_Option", option: WithLogger(slog.Default()), want: true},
+		{name: "WithMaxFrameSize_Option", option: WithMaxFrameSize(4096), want: true},
+		{name: "WithMaxFrameSize_Too_Small_Option", option: WithMaxFrameSize(2), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.option(&config{})
+			assert.Equal(t, tt.want, err == nil)
+		})
+	}
+}
+
+func TestInProc_Dial_Not_Running(t *testing.T) {
+	t.Parallel()
+
+	i, err := NewInProc(WithLogger(slog.New(slog.DiscardHandler)))
+	require.NoError(t, err)
+
+	_, err = i.Dial(context.Background())
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestInProc_Handshake(t *testing.T) {
+	t.Parallel()
+
+	i := startInProc(t)
+	s := serializer.NewSerializer(common.NewPool(func() *bytes.Buffer {
+		return &bytes.Buffer{}
+	}))
+
+	conn, err := i.Dial(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	reader := bufio.NewReader(conn)
+
+	open, ok := readFrame(t, s, conn, reader).GetPayload().(domain.OpenFramePayload)
+	require.True(t, ok)
+	assert.NotEmpty(t, open.GetSourceID())
+
+	connect, err := frames.CreateConnectFrame(
+		domain.DOFF4, open.GetSourceID(), "inproc-test", 30, domain.ProtocolVersion1, domain.CapabilityLargeFrames, 64,
+	)
+	require.NoError(t, err)
+	data, err := s.SerializeFrame(connect)
+	require.NoError(t, err)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+
+	begin, ok := readFrame(t, s, conn, reader).GetPayload().(domain.BeginFramePayload)
+	require.True(t, ok)
+	assert.Equal(t, uint32(64), begin.GetOutgoingWindow(), "The outgoing window should be capped by the client window")
+
+	require.NoError(t, i.Stop(context.Background()))
+	_, err = i.Dial(context.Background())
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestInProc_Name(t *testing.T) {
+	t.Parallel()
+
+	i := &InProc{}
+	assert.Equal(t, "inproc-handler", i.Name())
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"errors"
+	"expvar"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+var (
+	// ErrInvalidConnectionLimit is returned when a connection limit is negative.
+	ErrInvalidConnectionLimit = errors.New("invalid connection limit")
+	// ErrIPDenied is returned when the remote ip is refused by the ip filter.
+	ErrIPDenied = errors.New("ip denied")
+	// ErrTooManyConnections is returned when the broker already serve the maximum number of connections.
+	ErrTooManyConnections = errors.New("too many connections")
+	// ErrTooManyConnectionsPerIP is returned when the remote ip already hold the maximum number of connections.
+	ErrTooManyConnectionsPerIP = errors.New("too many connections for ip")
+)
+
+// rejectionMetrics map the rejection reasons to their metric key.
+var rejectionMetrics = map[error]string{
+	ErrIPDenied:                "rejected_ip_denied",
+	ErrTooManyConnections:      "rejected_max_connections",
+	ErrTooManyConnectionsPerIP: "rejected_max_connections_per_ip",
+}
+
+var (
+	metricsMu         sync.Mutex
+	connectionMetrics = make(map[domain.TransportType]*expvar.Map)
+)
+
+// transportMetrics return the accepted, active and rejected connections count of the transport,
+// published under /metrics as <transport>_connections.
+func transportMetrics(transportType domain.TransportType) *expvar.Map {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	m, ok := connectionMetrics[transportType]
+	if !ok {
+		m = expvar.NewMap(string(transportType) + "_connections")
+		connectionMetrics[transportType] = m
+	}
+
+	return m
+}
+
+// Limiter admit connections under the global and per-ip caps and the ip filter.
+// A single Limiter is shared by every transport so the caps apply to the whole broker.
+// A cap of zero disable the limit, a nil Limiter admit every connection.
+type Limiter struct {
+	mu       sync.Mutex
+	max      int
+	maxPerIP int
+	filter   *security.IPFilter
+
+	active int
+	perIP  map[string]int
+}
+
+// NewLimiter return a new Limiter with the given caps and ip filter, the filter may be nil.
+func NewLimiter(maxConnections, maxConnectionsPerIP int, filter *security.IPFilter) (*Limiter, error) {
+	if maxConnections < 0 || maxConnectionsPerIP < 0 {
+		return nil, ErrInvalidConnectionLimit
+	}
+
+	return &Limiter{
+		max:      maxConnections,
+		maxPerIP: maxConnectionsPerIP,
+		filter:   filter,
+		perIP:    make(map[string]int),
+	}, nil
+}
+
+// Acquire reserve a slot for a connection from the ip, the slot must be given back with Release.
+// Connections without ip, e.g. over a Unix socket, are only subject to the global cap.
+func (l *Limiter) Acquire(ip net.IP) error {
+	if l == nil {
+		return nil
+	}
+
+	if ip != nil && l.filter != nil && !l.filter.Allowed(ip) {
+		return ErrIPDenied
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.max > 0 && l.active >= l.max {
+		return ErrTooManyConnections
+	}
+
+	if ip == nil {
+		l.active++
+		return nil
+	}
+
+	key := ip.String()
+	if l.maxPerIP > 0 && l.perIP[key] >= l.maxPerIP {
+		return ErrTooManyConnectionsPerIP
+	}
+
+	l.active++
+	l.perIP[key]++
+
+	return nil
+}
+
+// Release give back the slot held by a connection from the ip.
+func (l *Limiter) Release(ip net.IP) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	if ip == nil {
+		return
+	}
+
+	key := ip.String()
+	l.perIP[key]--
+	if l.perIP[key] <= 0 {
+		delete(l.perIP, key)
+	}
+}
+
+// Listener wrap the listener of the transport: the refused connections are closed as soon as accepted,
+// the admitted ones give their slot back once closed. TLS, if any, must wrap the returned listener.
+func (l *Limiter) Listener(inner net.Listener, transportType domain.TransportType, logger *slog.Logger) net.Listener {
+	if l == nil {
+		return inner
+	}
+
+	return &limitedListener{
+		Listener: inner,
+		limiter:  l,
+		metrics:  transportMetrics(transportType),
+		logger:   logger,
+	}
+}
+
+// Handler wrap the HTTP handler upgrading the requests of the transport to connections,
+// the refused requests are answered with 503 Service Unavailable.
+func (l *Limiter) Handler(next http.Handler, transportType domain.TransportType, logger *slog.Logger) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	metrics := transportMetrics(transportType)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteHostIP(r.RemoteAddr)
+		if err := l.Acquire(ip); err != nil {
+			metrics.Add(rejectionMetrics[err], 1)
+			logger.Warn("connection rejected", "transport", transportType, "remote_ip", ip.String(), "reason", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		metrics.Add("accepted", 1)
+		metrics.Add("active", 1)
+		defer func() {
+			l.Release(ip)
+			metrics.Add("active", -1)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitedListener admit the accepted connections through the limiter, it keep accepting past a refused one.
+type limitedListener struct {
+	net.Listener
+	limiter *Limiter
+	metrics *expvar.Map
+	logger  *slog.Logger
+}
+
+func (l *limitedListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := RemoteIP(conn.RemoteAddr())
+		if err := l.limiter.Acquire(ip); err != nil {
+			l.reject(conn, ip, err)
+			continue
+		}
+		l.metrics.Add("accepted", 1)
+		l.metrics.Add("active", 1)
+
+		return &limitedConn{Conn: conn, release: func() {
+			l.limiter.Release(ip)
+			l.metrics.Add("active", -1)
+		}}, nil
+	}
+}
+
+// reject close a connection refused by the limiter and record the rejection.
+func (l *limitedListener) reject(conn net.Conn, ip net.IP, reason error) {
+	l.metrics.Add(rejectionMetrics[reason], 1)
+	l.logger.Warn("connection rejected", "remote_ip", ip.String(), "reason", reason)
+
+	if err := conn.Close(); err != nil {
+		l.logger.Warn("failed to close rejected connection", "error", err)
+	}
+}
+
+// limitedConn give its slot back to the limiter on the first Close.
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+
+	return err
+}
+
+// RemoteIP return the ip of the remote address, nil when the address has no ip, e.g. a Unix socket.
+func RemoteIP(addr net.Addr) net.IP {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+	if addr == nil || addr.Network() == "unix" {
+		return nil
+	}
+
+	return remoteHostIP(addr.String())
+}
+
+func remoteHostIP(hostport string) net.IP {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return nil
+	}
+
+	return net.ParseIP(host)
+}
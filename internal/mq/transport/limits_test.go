@@ -0,0 +1,222 @@
+package transport
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func TestNewLimiter(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLimiter(-1, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidConnectionLimit)
+
+	_, err = NewLimiter(0, -1, nil)
+	assert.ErrorIs(t, err, ErrInvalidConnectionLimit)
+}
+
+func TestLimiter_Acquire(t *testing.T) {
+	t.Parallel()
+
+	filter, err := security.NewIPFilter(nil, []string{"203.0.113.0/24"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		max      int
+		maxPerIP int
+		held     []string
+		ip       string
+		wantErr  error
+	}{
+		{
+			name: "No_Limits",
+			held: []string{"10.0.0.1", "10.0.0.1"},
+			ip:   "10.0.0.1",
+		},
+		{
+			name:    "Blocked_IP",
+			ip:      "203.0.113.7",
+			wantErr: ErrIPDenied,
+		},
+		{
+			name:    "Global_Limit_Reached",
+			max:     2,
+			held:    []string{"10.0.0.1", "10.0.0.2"},
+			ip:      "10.0.0.3",
+			wantErr: ErrTooManyConnections,
+		},
+		{
+			name:     "Per_IP_Limit_Reached",
+			maxPerIP: 1,
+			held:     []string{"10.0.0.1"},
+			ip:       "10.0.0.1",
+			wantErr:  ErrTooManyConnectionsPerIP,
+		},
+		{
+			name:     "No_IP_Skip_Per_IP_Limit",
+			maxPerIP: 1,
+			held:     []string{""},
+			ip:       "",
+		},
+		{
+			name:    "No_IP_Global_Limit_Reached",
+			max:     1,
+			held:    []string{""},
+			ip:      "",
+			wantErr: ErrTooManyConnections,
+		},
+		{
+			name:     "Per_IP_Limit_Other_IP",
+			maxPerIP: 1,
+			held:     []string{"10.0.0.1"},
+			ip:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter, err := NewLimiter(tt.max, tt.maxPerIP, filter)
+			require.NoError(t, err)
+			for _, ip := range tt.held {
+				require.NoError(t, limiter.Acquire(net.ParseIP(ip)))
+			}
+
+			assert.ErrorIs(t, limiter.Acquire(net.ParseIP(tt.ip)), tt.wantErr)
+		})
+	}
+}
+
+func TestLimiter_Release(t *testing.T) {
+	t.Parallel()
+
+	limiter, err := NewLimiter(1, 1, nil)
+	require.NoError(t, err)
+	ip := net.ParseIP("10.0.0.1")
+
+	require.NoError(t, limiter.Acquire(ip))
+	assert.ErrorIs(t, limiter.Acquire(ip), ErrTooManyConnections)
+
+	limiter.Release(ip)
+	assert.Empty(t, limiter.perIP)
+	assert.NoError(t, limiter.Acquire(ip))
+}
+
+func TestLimiter_Listener(t *testing.T) {
+	t.Parallel()
+
+	filter, err := security.NewIPFilter([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+	limiter, err := NewLimiter(1, 0, filter)
+	require.NoError(t, err)
+
+	deniedServer, deniedClient := net.Pipe()
+	admittedServer, admittedClient := net.Pipe()
+	t.Cleanup(func() {
+		_ = deniedClient.Close()
+		_ = admittedClient.Close()
+	})
+
+	inner := &chanListener{conns: make(chan net.Conn, 2)}
+	inner.conns <- &remoteConn{Conn: deniedServer, addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.1")}}
+	inner.conns <- &remoteConn{Conn: admittedServer, addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}}
+
+	l := limiter.Listener(inner, domain.TransportTypeMQTT, slog.New(slog.DiscardHandler))
+
+	conn, err := l.Accept()
+	require.NoError(t, err, "The listener should keep accepting past a refused connection")
+	assert.Equal(t, "10.0.0.1", RemoteIP(conn.RemoteAddr()).String())
+
+	_, err = deniedClient.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "The refused connection should be closed")
+
+	assert.ErrorIs(t, limiter.Acquire(net.ParseIP("10.0.0.2")), ErrTooManyConnections)
+	require.NoError(t, conn.Close())
+	require.NoError(t, conn.Close())
+	assert.NoError(t, limiter.Acquire(net.ParseIP("10.0.0.2")), "Closing the connection should give its slot back once")
+}
+
+func TestLimiter_Handler(t *testing.T) {
+	t.Parallel()
+
+	filter, err := security.NewIPFilter(nil, []string{"203.0.113.0/24"})
+	require.NoError(t, err)
+	limiter, err := NewLimiter(0, 0, filter)
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	})
+	h := limiter.Handler(next, domain.TransportTypeWebSocket, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       int
+	}{
+		{name: "Admitted", remoteAddr: "10.0.0.1:4242", want: http.StatusSwitchingProtocols},
+		{name: "Denied", remoteAddr: "203.0.113.7:4242", want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			r.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	t.Parallel()
+
+	var limiter *Limiter
+	inner := &chanListener{conns: make(chan net.Conn)}
+
+	assert.NoError(t, limiter.Acquire(net.ParseIP("10.0.0.1")))
+	assert.Same(t, inner, limiter.Listener(inner, domain.TransportTypeTCP, nil))
+}
+
+// chanListener accept the connections sent on its channel.
+type chanListener struct {
+	conns chan net.Conn
+}
+
+func (l *chanListener) Accept() (net.Conn, error) {
+	conn, ok := <-l.conns
+	if !ok {
+		return nil, net.ErrClosed
+	}
+
+	return conn, nil
+}
+
+func (l *chanListener) Close() error   { return nil }
+func (l *chanListener) Addr() net.Addr { return &net.TCPAddr{} }
+
+// remoteConn override the remote address of a connection.
+type remoteConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c *remoteConn) RemoteAddr() net.Addr {
+	return c.addr
+}
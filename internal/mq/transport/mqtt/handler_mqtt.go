@@ -0,0 +1,359 @@
+// Package mqtt provides the MQTT 3.1.1 transport handler, translating MQTT packets to HopperMQ frames
+// so MQTT devices and HopperMQ clients share the same topics and routing.
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
+)
+
+const (
+	defaultAddress        = ":1883"
+	defaultMaxPacketSize  = 1 << 20
+	defaultConnectTimeout = 10 * time.Second
+	writeTimeout          = 5 * time.Second
+	shutdownTimeout       = 10 * time.Second
+)
+
+var (
+	// ErrInvalidPacketSize is returned when the maximum packet size is not positive.
+	ErrInvalidPacketSize = errors.New("invalid mqtt packet size")
+	// ErrMissingTLSConfig is returned when TLS is requested without a TLS configuration.
+	ErrMissingTLSConfig = errors.New("missing tls configuration")
+)
+
+// MQTT is an MQTT 3.1.1 handler.
+type MQTT struct {
+	Listener       net.Listener
+	logger         *slog.Logger
+	address        string
+	tlsConfig      *tls.Config
+	limiter        *transport.Limiter
+	maxPacketSize  int
+	connectTimeout time.Duration
+
+	serializer *serializer.Serializer
+	throttler  transport.Throttler
+	conns      transport.Conns
+
+	eb domain.IEventBus
+
+	mu       sync.Mutex
+	sessions map[domain.Connection]*session
+
+	cancel context.CancelFunc
+}
+
+type config struct {
+	logger         *slog.Logger
+	address        string
+	tlsConfig      *tls.Config
+	limiter        *transport.Limiter
+	maxPacketSize  int
+	connectTimeout time.Duration
+}
+
+// Option configure the MQTT handler.
+type Option func(*config) error
+
+// WithLogger inject the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+
+		return nil
+	}
+}
+
+// WithAddress set the address the handler listen on, :1883 by default.
+func WithAddress(address string) Option {
+	return func(c *config) error {
+		if address != "" {
+			c.address = address
+		}
+
+		return nil
+	}
+}
+
+// WithLimiter admit the accepted connections through the limiter shared by the transports.
+func WithLimiter(limiter *transport.Limiter) Option {
+	return func(c *config) error {
+		c.limiter = limiter
+
+		return nil
+	}
+}
+
+// WithTLS terminate TLS on the listener with the given configuration, a nil configuration is refused
+// rather than silently serving plaintext.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		if tlsConfig == nil {
+			return ErrMissingTLSConfig
+		}
+		c.tlsConfig = tlsConfig
+
+		return nil
+	}
+}
+
+// WithMaxPacketSize set the maximum size in bytes of a packet read from a connection.
+func WithMaxPacketSize(size int) Option {
+	return func(c *config) error {
+		if size <= 0 || size > maxRemainingLength {
+			return ErrInvalidPacketSize
+		}
+		c.maxPacketSize = size
+
+		return nil
+	}
+}
+
+// WithConnectTimeout set how long a client has to send its CONNECT and be accepted by the broker.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout > 0 {
+			c.connectTimeout = timeout
+		}
+
+		return nil
+	}
+}
+
+// NewMQTT return the new MQTT handler.
+func NewMQTT(opts ...Option) (*MQTT, error) {
+	handlerConfig := &config{
+		address:        defaultAddress,
+		maxPacketSize:  defaultMaxPacketSize,
+		connectTimeout: defaultConnectTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(handlerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &MQTT{
+		logger:         handlerConfig.logger,
+		address:        handlerConfig.address,
+		tlsConfig:      handlerConfig.tlsConfig,
+		limiter:        handlerConfig.limiter,
+		maxPacketSize:  handlerConfig.maxPacketSize,
+		connectTimeout: handlerConfig.connectTimeout,
+		serializer: serializer.NewSerializer(
+			common.NewPool(func() *bytes.Buffer {
+				return &bytes.Buffer{}
+			}),
+		),
+		sessions: make(map[domain.Connection]*session),
+	}, nil
+}
+
+// HandleConnection accept the MQTT clients until the listener is closed.
+func (m *MQTT) HandleConnection(ctx context.Context) error {
+	for {
+		conn, err := m.Listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return ctx.Err()
+			}
+			m.logger.Warn("failed to accept mqtt connection", "error", err)
+			continue
+		}
+
+		go m.serveConn(ctx, conn)
+	}
+}
+
+func (m *MQTT) serveConn(ctx context.Context, conn net.Conn) {
+	if !m.conns.Track(conn) {
+		m.logger.Warn("transport closed, refusing connection", "transport", domain.TransportTypeMQTT)
+		_ = conn.Close()
+		return
+	}
+	defer m.conns.Untrack(conn)
+	defer func() {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			m.logger.Warn("failed to close connection", "error", err)
+		}
+	}()
+
+	if m.eb == nil {
+		m.logger.Warn("EventBus not registered, skipping event publishing")
+		return
+	}
+
+	s := newSession(m, conn)
+	if err := s.readConnect(); err != nil {
+		m.logger.Info("mqtt connect refused", "remote_addr", conn.RemoteAddr().String(), "error", err)
+		return
+	}
+
+	identity := ""
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		identity = security.PeerIdentity(tlsConn.ConnectionState())
+	}
+
+	m.track(conn, s)
+	defer m.untrack(conn)
+	defer m.throttler.Forget(conn)
+
+	evt := &events.NewConnectionEvent{
+		Conn:      conn,
+		Identity:  identity,
+		Transport: domain.TransportTypeMQTT,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeNewConnection,
+		},
+	}
+
+	if err := m.eb.Publish(ctx, evt); err != nil {
+		m.logger.Warn("failed to publish new connection event", "error", err)
+		return
+	}
+
+	err := s.run(ctx)
+	switch {
+	case errors.Is(err, errSessionClosed), errors.Is(err, io.EOF), errors.Is(err, context.Canceled):
+		m.logger.Info("mqtt client disconnected", "client_id", s.clientID)
+	default:
+		m.logger.Warn("mqtt session ended", "client_id", s.clientID, "error", err)
+	}
+
+	s.publishWill(context.WithoutCancel(ctx))
+
+	disconnected := &events.ClientDisconnectedEvent{
+		Transport: domain.TransportTypeMQTT,
+		Conn:      conn,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeConnectionClosed,
+		},
+	}
+
+	if err := m.eb.Publish(context.WithoutCancel(ctx), disconnected); err != nil {
+		m.logger.Warn("failed to publish client disconnected event", "error", err)
+	}
+}
+
+func (m *MQTT) track(conn domain.Connection, s *session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[conn] = s
+}
+
+func (m *MQTT) untrack(conn domain.Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, conn)
+}
+
+func (m *MQTT) session(conn domain.Connection) *session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sessions[conn]
+}
+
+// Run wil start the MQTT component.
+func (m *MQTT) Run(ctx context.Context) error {
+	m.logger.Info("starting MQTT component", "address", m.address)
+
+	l, err := net.Listen("tcp", m.address)
+	if err != nil {
+		return err
+	}
+	l = m.limiter.Listener(l, domain.TransportTypeMQTT, m.logger)
+	if m.tlsConfig != nil {
+		l = tls.NewListener(l, m.tlsConfig)
+	}
+	m.Listener = l
+
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	transport.Dispatcher{
+		Transport: domain.TransportTypeMQTT,
+		Sink:      m.forward,
+		Throttler: &m.throttler,
+	}.Run(ctx, m.eb, &m.conns)
+
+	go func() {
+		m.logger.Info("MQTT server running", "address", l.Addr().String())
+		if err := m.HandleConnection(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			m.logger.Warn("MQTT Handler failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop will shut down gracefully the MQTT component.
+func (m *MQTT) Stop(_ context.Context) error {
+	m.logger.Info("stopping MQTT Component")
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	if m.Listener != nil {
+		if err := m.Listener.Close(); err != nil {
+			m.logger.Warn("error closing mqtt listener", "error", err)
+		}
+	}
+
+	if m.conns.Close(shutdownTimeout) {
+		m.logger.Info("All mqtt connections closed gracefully")
+	} else {
+		m.logger.Warn("Timeout waiting for mqtt connections to close")
+	}
+
+	return nil
+}
+
+// Name will return the component name.
+func (m *MQTT) Name() string {
+	return "mqtt-handler"
+}
+
+// RegisterEventBus will attach the event bus to the component.
+func (m *MQTT) RegisterEventBus(eb domain.IEventBus) {
+	m.eb = eb
+	m.logger.Info("EventBus registered with MQTT", "service", m.Name())
+}
+
+// forward hand a frame sent by the broker to the session of the connection,
+// the session translate it to MQTT packets.
+func (m *MQTT) forward(ctx context.Context, conn domain.Connection, data []byte, onSent func(error)) {
+	s := m.session(conn)
+	if s == nil {
+		if onSent != nil {
+			onSent(net.ErrClosed)
+		}
+		return
+	}
+
+	select {
+	case s.frames <- outbound{data: data, onSent: onSent}:
+	case <-s.done:
+		if onSent != nil {
+			onSent(net.ErrClosed)
+		}
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,221 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core"
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// testClient is a minimal MQTT client.
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dial(t *testing.T, address string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *testClient) send(kind, flags byte, body []byte) {
+	c.t.Helper()
+
+	require.NoError(c.t, writePacket(c.conn, kind, flags, body))
+}
+
+func (c *testClient) receive(kind byte) *packet {
+	c.t.Helper()
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	p, err := readPacket(c.reader, 0)
+	require.NoError(c.t, err)
+	require.Equal(c.t, kind, p.kind)
+
+	return p
+}
+
+func (c *testClient) connect(clientID string) {
+	c.t.Helper()
+
+	c.send(packetConnect, 0, connectBody(clientID, 30, "device", "secret"))
+	assert.Equal(c.t, []byte{0, connackAccepted}, c.receive(packetConnack).body)
+}
+
+func startMQTT(t *testing.T, authenticator domain.Authenticator) *MQTT {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := slog.New(slog.DiscardHandler)
+	eb := events.NewEventBus(10)
+
+	broker := core.NewBroker(logger, eb)
+	broker.RegisterEventBus(eb)
+	if authenticator != nil {
+		broker.RegisterAuthenticator(authenticator, 0)
+	}
+	require.NoError(t, broker.Run(ctx))
+
+	m, err := NewMQTT(WithLogger(logger), WithAddress("127.0.0.1:0"), WithConnectTimeout(2*time.Second))
+	require.NoError(t, err)
+	m.RegisterEventBus(eb)
+	require.NoError(t, m.Run(ctx))
+	t.Cleanup(func() { _ = m.Stop(context.Background()) })
+
+	return m
+}
+
+func TestMQTT_Options(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option Option
+		want   bool
+	}{
+		{name: "WithAddress_Option", option: WithAddress(":8883"), want: true},
+		{name: "WithTLS_Option", option: WithTLS(&tls.Config{}), want: true},
+		{name: "WithTLS_Nil_Option", option: WithTLS(nil), want: false},
+		{name: "WithMaxPacketSize_Option", option: WithMaxPacketSize(4096), want: true},
+		{name: "WithMaxPacketSize_Zero_Option", option: WithMaxPacketSize(0), want: false},
+		{name: "WithConnectTimeout_Option", option: WithConnectTimeout(time.Second), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.option(&config{})
+			assert.Equal(t, tt.want, err == nil)
+		})
+	}
+}
+
+func TestMQTT_Publish_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	m := startMQTT(t, nil)
+	address := m.Listener.Addr().String()
+
+	subscriber := dial(t, address)
+	subscriber.connect("dashboard")
+
+	subscribe := append(appendString(encodePacketID(1), "sensors/+/temperature"), 2)
+	subscribe = append(appendString(subscribe, "sensors/#"), 0)
+	subscribe = append(appendString(subscribe, "sensors/#/bad"), 0)
+	subscriber.send(packetSubscribe, 0x02, subscribe)
+	assert.Equal(t, []byte{0, 1, 1, 0, subackFailure}, subscriber.receive(packetSuback).body)
+
+	publisher := dial(t, address)
+	publisher.connect("sensor-kitchen")
+
+	flags, body := encodePublish(&publishPacket{
+		topic:    "sensors/kitchen/temperature",
+		packetID: 9,
+		qos:      1,
+		payload:  []byte("21.5"),
+	})
+	publisher.send(packetPublish, flags, body)
+	assert.Equal(t, encodePacketID(9), publisher.receive(packetPuback).body)
+
+	p := subscriber.receive(packetPublish)
+	delivered, err := parsePublish(p.flags, p.body)
+	require.NoError(t, err)
+	assert.Equal(t, "sensors/kitchen/temperature", delivered.topic)
+	assert.Equal(t, byte(1), delivered.qos, "the highest granted qos is used")
+	assert.Equal(t, []byte("21.5"), delivered.payload)
+	subscriber.send(packetPuback, 0, encodePacketID(delivered.packetID))
+
+	subscriber.send(packetPingreq, 0, nil)
+	subscriber.receive(packetPingresp)
+
+	subscriber.send(packetUnsubscribe, 0x02, appendString(encodePacketID(2), "sensors/+/temperature"))
+	assert.Equal(t, encodePacketID(2), subscriber.receive(packetUnsuback).body)
+
+	flags, body = encodePublish(&publishPacket{topic: "sensors/garage/temperature", payload: []byte("12.0")})
+	publisher.send(packetPublish, flags, body)
+
+	p = subscriber.receive(packetPublish)
+	delivered, err = parsePublish(p.flags, p.body)
+	require.NoError(t, err)
+	assert.Equal(t, "sensors/garage/temperature", delivered.topic)
+	assert.Equal(t, byte(0), delivered.qos)
+}
+
+func TestMQTT_Connect_Refused(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	m := startMQTT(t, security.NewAuthenticator(security.User{Username: "device", PasswordHash: string(hash)}))
+
+	tests := []struct {
+		name string
+		body func() []byte
+		want byte
+	}{
+		{
+			name: "Accepted",
+			body: func() []byte { return connectBody("sensor", 0, "device", "secret") },
+			want: connackAccepted,
+		},
+		{
+			name: "Bad_Credentials",
+			body: func() []byte { return connectBody("sensor", 0, "device", "wrong") },
+			want: connackBadCredentials,
+		},
+		{
+			name: "Without_Credentials",
+			body: func() []byte { return connectBody("sensor", 0, "", "") },
+			want: connackNotAuthorized,
+		},
+		{
+			name: "Unacceptable_Protocol_Level",
+			body: func() []byte {
+				body := connectBody("sensor", 0, "", "")
+				body[6] = 3
+				return body
+			},
+			want: connackUnacceptableProtocol,
+		},
+		{
+			name: "Empty_Client_ID_Without_Clean_Session",
+			body: func() []byte {
+				body := connectBody("", 0, "", "")
+				body[7] = 0
+				return body
+			},
+			want: connackIdentifierRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := dial(t, m.Listener.Addr().String())
+			client.send(packetConnect, 0, tt.body())
+			assert.Equal(t, []byte{0, tt.want}, client.receive(packetConnack).body)
+		})
+	}
+}
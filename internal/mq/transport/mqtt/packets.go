@@ -0,0 +1,338 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// packet types of MQTT 3.1.1.
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+// CONNACK return codes.
+const (
+	connackAccepted             byte = 0x00
+	connackUnacceptableProtocol byte = 0x01
+	connackIdentifierRejected   byte = 0x02
+	connackServerUnavailable    byte = 0x03
+	connackBadCredentials       byte = 0x04
+	connackNotAuthorized        byte = 0x05
+)
+
+const (
+	protocolName  = "MQTT"
+	protocolLevel = 4
+
+	// subackFailure is the SUBACK return code of a refused subscription.
+	subackFailure byte = 0x80
+
+	// maxRemainingLength is the largest remaining length the variable length encoding can carry.
+	maxRemainingLength = 268435455
+)
+
+var (
+	// ErrMalformedPacket is returned when a packet does not follow the MQTT 3.1.1 encoding.
+	ErrMalformedPacket = errors.New("malformed mqtt packet")
+	// ErrPacketTooLarge is returned when a packet exceed the maximum packet size.
+	ErrPacketTooLarge = errors.New("mqtt packet too large")
+)
+
+// packet is a control packet, the fixed header split in its type and flags.
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// connectPacket is the content of a CONNECT packet.
+type connectPacket struct {
+	protocolName  string
+	protocolLevel byte
+	cleanSession  bool
+	keepAlive     uint16
+	clientID      string
+
+	willTopic   string
+	willMessage []byte
+	willQoS     byte
+	willRetain  bool
+	hasWill     bool
+
+	username    string
+	hasUsername bool
+	password    []byte
+	hasPassword bool
+}
+
+// publishPacket is the content of a PUBLISH packet.
+type publishPacket struct {
+	topic    string
+	packetID uint16
+	qos      byte
+	retain   bool
+	dup      bool
+	payload  []byte
+}
+
+// topicFilter is a topic filter of a SUBSCRIBE packet with its requested QoS.
+type topicFilter struct {
+	filter string
+	qos    byte
+}
+
+// readPacket read the next control packet, refusing packets larger than maxSize bytes.
+func readPacket(r *bufio.Reader, maxSize int) (*packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := readRemainingLength(r)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && length > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrPacketTooLarge, length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return &packet{kind: header >> 4, flags: header & 0x0F, body: body}, nil
+}
+
+func readRemainingLength(r io.ByteReader) (int, error) {
+	length, multiplier := 0, 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+
+	return 0, fmt.Errorf("%w: remaining length", ErrMalformedPacket)
+}
+
+// writePacket write a control packet with the given fixed header.
+func writePacket(w io.Writer, kind, flags byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return ErrPacketTooLarge
+	}
+
+	buf := make([]byte, 0, 5+len(body))
+	buf = append(buf, kind<<4|flags)
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	buf = append(buf, body...)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// decoder read the fields of a packet body.
+type decoder struct {
+	body []byte
+	err  error
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.body) < 2 {
+		d.err = ErrMalformedPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint16(d.body)
+	d.body = d.body[2:]
+
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.body) < 1 {
+		d.err = ErrMalformedPacket
+		return 0
+	}
+	v := d.body[0]
+	d.body = d.body[1:]
+
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := int(d.uint16())
+	if d.err != nil {
+		return nil
+	}
+	if len(d.body) < n {
+		d.err = ErrMalformedPacket
+		return nil
+	}
+	v := d.body[:n]
+	d.body = d.body[n:]
+
+	return v
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func parseConnect(body []byte) (*connectPacket, error) {
+	d := &decoder{body: body}
+	c := &connectPacket{
+		protocolName:  d.string(),
+		protocolLevel: d.byte(),
+	}
+
+	flags := d.byte()
+	c.keepAlive = d.uint16()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if flags&0x01 != 0 {
+		return nil, fmt.Errorf("%w: reserved connect flag", ErrMalformedPacket)
+	}
+
+	c.cleanSession = flags&0x02 != 0
+	c.hasWill = flags&0x04 != 0
+	c.willQoS = (flags >> 3) & 0x03
+	c.willRetain = flags&0x20 != 0
+	c.hasPassword = flags&0x40 != 0
+	c.hasUsername = flags&0x80 != 0
+
+	c.clientID = d.string()
+	if c.hasWill {
+		c.willTopic = d.string()
+		c.willMessage = d.bytes()
+	}
+	if c.hasUsername {
+		c.username = d.string()
+	}
+	if c.hasPassword {
+		c.password = d.bytes()
+	}
+
+	return c, d.err
+}
+
+func parsePublish(flags byte, body []byte) (*publishPacket, error) {
+	p := &publishPacket{
+		qos:    (flags >> 1) & 0x03,
+		retain: flags&0x01 != 0,
+		dup:    flags&0x08 != 0,
+	}
+	if p.qos > 2 {
+		return nil, fmt.Errorf("%w: qos %d", ErrMalformedPacket, p.qos)
+	}
+
+	d := &decoder{body: body}
+	p.topic = d.string()
+	if p.qos > 0 {
+		p.packetID = d.uint16()
+	}
+	p.payload = d.body
+
+	return p, d.err
+}
+
+func parseSubscribe(body []byte) (uint16, []topicFilter, error) {
+	d := &decoder{body: body}
+	packetID := d.uint16()
+
+	var filters []topicFilter
+	for d.err == nil && len(d.body) > 0 {
+		filters = append(filters, topicFilter{filter: d.string(), qos: d.byte()})
+	}
+	if d.err == nil && len(filters) == 0 {
+		d.err = fmt.Errorf("%w: subscribe without topic filter", ErrMalformedPacket)
+	}
+
+	return packetID, filters, d.err
+}
+
+func parseUnsubscribe(body []byte) (uint16, []string, error) {
+	d := &decoder{body: body}
+	packetID := d.uint16()
+
+	var filters []string
+	for d.err == nil && len(d.body) > 0 {
+		filters = append(filters, d.string())
+	}
+	if d.err == nil && len(filters) == 0 {
+		d.err = fmt.Errorf("%w: unsubscribe without topic filter", ErrMalformedPacket)
+	}
+
+	return packetID, filters, d.err
+}
+
+func parsePacketID(body []byte) (uint16, error) {
+	d := &decoder{body: body}
+	id := d.uint16()
+
+	return id, d.err
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+func encodePublish(p *publishPacket) (byte, []byte) {
+	flags := p.qos << 1
+	if p.retain {
+		flags |= 0x01
+	}
+	if p.dup {
+		flags |= 0x08
+	}
+
+	body := appendString(make([]byte, 0, 4+len(p.topic)+len(p.payload)), p.topic)
+	if p.qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, p.packetID)
+	}
+
+	return flags, append(body, p.payload...)
+}
+
+func encodePacketID(id uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, id)
+}
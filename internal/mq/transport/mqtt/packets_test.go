@@ -0,0 +1,180 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectBody encode the body of a CONNECT packet.
+func connectBody(clientID string, keepAlive uint16, username, password string) []byte {
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, protocolName)
+	body = append(body, protocolLevel, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+	}
+	if password != "" {
+		body = appendString(body, password)
+	}
+
+	return body
+}
+
+func TestPacket_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Empty", size: 0},
+		{name: "One_Length_Byte", size: 127},
+		{name: "Two_Length_Bytes", size: 128},
+		{name: "Three_Length_Bytes", size: 16384},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			body := bytes.Repeat([]byte{0xAB}, tt.size)
+
+			var buf bytes.Buffer
+			require.NoError(t, writePacket(&buf, packetPublish, 0x03, body))
+
+			p, err := readPacket(bufio.NewReader(&buf), 0)
+			require.NoError(t, err)
+			assert.Equal(t, packetPublish, p.kind)
+			assert.Equal(t, byte(0x03), p.flags)
+			assert.Equal(t, body, p.body)
+		})
+	}
+}
+
+func TestReadPacket_Too_Large(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, writePacket(&buf, packetPublish, 0, make([]byte, 64)))
+
+	_, err := readPacket(bufio.NewReader(&buf), 32)
+	assert.ErrorIs(t, err, ErrPacketTooLarge)
+}
+
+func TestParseConnect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    *connectPacket
+		wantErr bool
+	}{
+		{
+			name: "Credentials",
+			body: connectBody("sensor-1", 60, "device", "secret"),
+			want: &connectPacket{
+				protocolName:  protocolName,
+				protocolLevel: protocolLevel,
+				cleanSession:  true,
+				keepAlive:     60,
+				clientID:      "sensor-1",
+				username:      "device",
+				hasUsername:   true,
+				password:      []byte("secret"),
+				hasPassword:   true,
+			},
+		},
+		{
+			name: "Anonymous",
+			body: connectBody("sensor-2", 0, "", ""),
+			want: &connectPacket{
+				protocolName:  protocolName,
+				protocolLevel: protocolLevel,
+				cleanSession:  true,
+				clientID:      "sensor-2",
+			},
+		},
+		{
+			name:    "Truncated",
+			body:    connectBody("sensor-3", 0, "", "")[:8],
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseConnect(tt.body)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrMalformedPacket)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPublish_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		publish *publishPacket
+	}{
+		{
+			name:    "QoS0",
+			publish: &publishPacket{topic: "sensors/kitchen", payload: []byte("21.5")},
+		},
+		{
+			name:    "QoS1_Retained",
+			publish: &publishPacket{topic: "sensors/kitchen", packetID: 42, qos: 1, retain: true, payload: []byte("21.5")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags, body := encodePublish(tt.publish)
+
+			got, err := parsePublish(flags, body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.publish, got)
+		})
+	}
+}
+
+func TestParseSubscribe(t *testing.T) {
+	t.Parallel()
+
+	body := encodePacketID(7)
+	body = append(appendString(body, "sensors/+/temperature"), 1)
+	body = append(appendString(body, "alerts/#"), 2)
+
+	packetID, filters, err := parseSubscribe(body)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(7), packetID)
+	assert.Equal(t, []topicFilter{
+		{filter: "sensors/+/temperature", qos: 1},
+		{filter: "alerts/#", qos: 2},
+	}, filters)
+
+	_, _, err = parseSubscribe(encodePacketID(7))
+	assert.ErrorIs(t, err, ErrMalformedPacket)
+}
@@ -0,0 +1,507 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+const (
+	clientVersion = "mqtt/3.1.1"
+
+	// maxQoS is the highest QoS granted, QoS 2 subscriptions are downgraded.
+	maxQoS byte = 1
+
+	// maxInflight bound the QoS 1 messages waiting for a PUBACK, further messages are sent at QoS 0.
+	maxInflight = 1024
+
+	// HeaderQoS and HeaderRetain carry the PUBLISH flags in the message headers.
+	HeaderQoS    = "mqtt.qos"
+	HeaderRetain = "mqtt.retain"
+)
+
+// errSessionClosed end a session without error, e.g. on DISCONNECT.
+var errSessionClosed = errors.New("session closed")
+
+// outbound is a frame sent by the broker to the session.
+type outbound struct {
+	data   []byte
+	onSent func(err error)
+}
+
+// session translate the packets of an MQTT client to HopperMQ frames and back.
+//
+// The broker sees the session as a regular HopperMQ client: the Open frame is answered
+// with an Auth frame when the CONNECT carry credentials and a Connect frame, the Begin frame
+// is answered with a CONNACK. SUBSCRIBE, UNSUBSCRIBE and PUBLISH become Subscribe, Unsubscribe
+// and Message frames, the Message frames routed to the client become PUBLISH packets.
+type session struct {
+	handler *MQTT
+	conn    net.Conn
+	reader  *bufio.Reader
+	connect *connectPacket
+	// handshake answer the Open frame with the CONNECT credentials.
+	handshake protocol.ClientHandshake
+
+	clientID  domain.ID
+	connected bool
+	// disconnected is set on DISCONNECT, the will message is then discarded.
+	disconnected bool
+
+	subscriptions map[string]byte // granted qos by HopperMQ topic pattern
+	nextPacketID  uint16
+	inflight      map[uint16]struct{}
+
+	// pending hold the packets received before the CONNACK.
+	pending []*packet
+
+	frames chan outbound
+	done   chan struct{}
+}
+
+func newSession(handler *MQTT, conn net.Conn) *session {
+	return &session{
+		handler:       handler,
+		conn:          conn,
+		reader:        bufio.NewReader(conn),
+		subscriptions: make(map[string]byte),
+		inflight:      make(map[uint16]struct{}),
+		frames:        make(chan outbound, 64), //nolint:mnd
+		done:          make(chan struct{}),
+	}
+}
+
+// readConnect read the CONNECT packet opening the session, answering the refused ones with a CONNACK.
+func (s *session) readConnect() error {
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.handler.connectTimeout))
+	defer func() { _ = s.conn.SetReadDeadline(time.Time{}) }()
+
+	p, err := readPacket(s.reader, s.handler.maxPacketSize)
+	if err != nil {
+		return err
+	}
+	if p.kind != packetConnect {
+		return fmt.Errorf("%w: expected CONNECT, got %d", ErrMalformedPacket, p.kind)
+	}
+
+	connect, err := parseConnect(p.body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case connect.protocolName != protocolName || connect.protocolLevel != protocolLevel:
+		_ = s.writeConnack(connackUnacceptableProtocol)
+		return fmt.Errorf("%w: %s level %d", ErrMalformedPacket, connect.protocolName, connect.protocolLevel)
+	case connect.clientID == "" && !connect.cleanSession:
+		_ = s.writeConnack(connackIdentifierRejected)
+		return fmt.Errorf("%w: empty client identifier without clean session", ErrMalformedPacket)
+	case connect.hasPassword && !connect.hasUsername:
+		return fmt.Errorf("%w: password without username", ErrMalformedPacket)
+	}
+
+	s.connect = connect
+	s.handshake = protocol.ClientHandshake{
+		ClientVersion: clientVersion,
+		KeepAlive:     connect.keepAlive,
+		Capabilities:  domain.CapabilityLargeFrames,
+	}
+	if connect.hasUsername {
+		s.handshake.Mechanism = domain.AuthMechanismPlain
+		s.handshake.Response = []byte("\x00" + connect.username + "\x00" + string(connect.password))
+	}
+
+	return nil
+}
+
+// run serve the session until the client or the broker close it.
+func (s *session) run(ctx context.Context) error {
+	defer close(s.done)
+
+	packets := make(chan *packet)
+	readErr := make(chan error, 1)
+	go s.readLoop(ctx, packets, readErr)
+
+	handshake := time.NewTimer(s.handler.connectTimeout)
+	defer handshake.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-handshake.C:
+			if !s.connected {
+				_ = s.writeConnack(connackServerUnavailable)
+				return fmt.Errorf("handshake with the broker timed out")
+			}
+		case err = <-readErr:
+		case p := <-packets:
+			err = s.handlePacket(ctx, p)
+		case out := <-s.frames:
+			err = s.handleFrame(ctx, out.data)
+			if out.onSent != nil {
+				out.onSent(err)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLoop read the packets of the client, enforcing the keep alive and the throttling.
+func (s *session) readLoop(ctx context.Context, packets chan<- *packet, readErr chan<- error) {
+	var keepAlive time.Duration
+	if s.connect.keepAlive > 0 {
+		// the client is disconnected after one and a half keep alive without packet.
+		keepAlive = time.Duration(s.connect.keepAlive) * 1500 * time.Millisecond //nolint:mnd
+	}
+
+	for {
+		if !s.handler.throttler.Wait(ctx, s.conn) {
+			readErr <- ctx.Err()
+			return
+		}
+
+		if keepAlive > 0 {
+			_ = s.conn.SetReadDeadline(time.Now().Add(keepAlive))
+		}
+
+		p, err := readPacket(s.reader, s.handler.maxPacketSize)
+		if err != nil {
+			readErr <- err
+			return
+		}
+
+		select {
+		case packets <- p:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *session) handlePacket(ctx context.Context, p *packet) error {
+	if !s.connected && p.kind != packetDisconnect && p.kind != packetPingreq {
+		s.pending = append(s.pending, p)
+		return nil
+	}
+
+	switch p.kind {
+	case packetPublish:
+		return s.handlePublish(ctx, p)
+	case packetPuback:
+		id, err := parsePacketID(p.body)
+		if err != nil {
+			return err
+		}
+		delete(s.inflight, id)
+		return nil
+	case packetSubscribe:
+		return s.handleSubscribe(ctx, p)
+	case packetUnsubscribe:
+		return s.handleUnsubscribe(ctx, p)
+	case packetPingreq:
+		return s.write(packetPingresp, 0, nil)
+	case packetDisconnect:
+		s.disconnected = true
+		return errSessionClosed
+	default:
+		return fmt.Errorf("%w: unexpected packet type %d", ErrMalformedPacket, p.kind)
+	}
+}
+
+func (s *session) handlePublish(ctx context.Context, p *packet) error {
+	publish, err := parsePublish(p.flags, p.body)
+	if err != nil {
+		return err
+	}
+	if publish.qos > maxQoS {
+		return fmt.Errorf("%w: qos %d publish is not supported", ErrMalformedPacket, publish.qos)
+	}
+
+	if err := s.publish(ctx, publish.topic, publish.payload, publish.qos, publish.retain); err != nil {
+		return err
+	}
+
+	if publish.qos == 1 {
+		return s.write(packetPuback, 0, encodePacketID(publish.packetID))
+	}
+
+	return nil
+}
+
+// publish hand a message published by the client to the broker.
+func (s *session) publish(ctx context.Context, mqttTopic string, payload []byte, qos byte, retain bool) error {
+	topic, err := toHopperTopic(mqttTopic)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, mqttTopic)
+	}
+
+	headers := map[string]string{
+		HeaderQoS: strconv.Itoa(int(qos)),
+	}
+	if retain {
+		headers[HeaderRetain] = "1"
+	}
+
+	frame, err := frames.CreateMessageFrame(domain.DOFF4, topic, common.GenerateIdentifier(), payload, headers)
+	if err != nil {
+		return err
+	}
+
+	return s.sendFrame(ctx, frame)
+}
+
+func (s *session) handleSubscribe(ctx context.Context, p *packet) error {
+	if p.flags != 0x02 {
+		return fmt.Errorf("%w: subscribe flags", ErrMalformedPacket)
+	}
+
+	packetID, filters, err := parseSubscribe(p.body)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]byte, 0, len(filters))
+	for _, f := range filters {
+		topic, err := toHopperFilter(f.filter)
+		if err != nil || f.qos > 2 {
+			s.handler.logger.Warn("mqtt subscription refused", "filter", f.filter, "qos", f.qos)
+			codes = append(codes, subackFailure)
+			continue
+		}
+
+		qos := min(f.qos, maxQoS)
+		frame, err := frames.CreateSubscribeFrame(domain.DOFF4, topic, qos, "")
+		if err != nil {
+			return err
+		}
+		if err := s.sendFrame(ctx, frame); err != nil {
+			return err
+		}
+
+		s.subscriptions[topic] = qos
+		codes = append(codes, qos)
+	}
+
+	return s.write(packetSuback, 0, append(encodePacketID(packetID), codes...))
+}
+
+func (s *session) handleUnsubscribe(ctx context.Context, p *packet) error {
+	if p.flags != 0x02 {
+		return fmt.Errorf("%w: unsubscribe flags", ErrMalformedPacket)
+	}
+
+	packetID, filters, err := parseUnsubscribe(p.body)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range filters {
+		topic, err := toHopperFilter(filter)
+		if err != nil {
+			continue
+		}
+		if _, ok := s.subscriptions[topic]; !ok {
+			continue
+		}
+
+		frame, err := frames.CreateUnsubscribeFrame(domain.DOFF4, topic)
+		if err != nil {
+			return err
+		}
+		if err := s.sendFrame(ctx, frame); err != nil {
+			return err
+		}
+		delete(s.subscriptions, topic)
+	}
+
+	return s.write(packetUnsuback, 0, encodePacketID(packetID))
+}
+
+// handleFrame translate a frame sent by the broker.
+func (s *session) handleFrame(ctx context.Context, data []byte) error {
+	frame, err := s.handler.serializer.DeserializeFrame(data)
+	if err != nil {
+		return err
+	}
+
+	switch payload := frame.GetPayload().(type) {
+	case domain.OpenFramePayload:
+		return s.handleOpen(ctx, payload)
+	case domain.BeginFramePayload:
+		return s.handleBegin(ctx)
+	case domain.MessageFramePayload:
+		return s.deliver(payload)
+	case domain.ErrorFramePayload:
+		return s.handleError(payload)
+	case domain.CloseFramePayload:
+		s.handler.logger.Info("mqtt session closed by the broker", "client_id", s.clientID, "reason", payload.GetReason())
+		return errSessionClosed
+	}
+
+	return nil
+}
+
+// handleOpen authenticate the client with the CONNECT credentials, then connect it.
+func (s *session) handleOpen(ctx context.Context, payload domain.OpenFramePayload) error {
+	s.clientID = payload.GetSourceID()
+
+	return s.handshake.Answer(payload, func(frame domain.Frame) error {
+		return s.sendFrame(ctx, frame)
+	})
+}
+
+// handleBegin accept the CONNECT and process the packets received during the handshake.
+func (s *session) handleBegin(ctx context.Context) error {
+	if s.connected {
+		return nil
+	}
+
+	if err := s.writeConnack(connackAccepted); err != nil {
+		return err
+	}
+	s.connected = true
+
+	s.handler.logger.Info("mqtt client connected", "client_id", s.clientID, "mqtt_client_id", s.connect.clientID)
+
+	pending := s.pending
+	s.pending = nil
+	for _, p := range pending {
+		if err := s.handlePacket(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleError refuse the CONNECT when the broker reject the handshake, other errors are only logged
+// since MQTT 3.1.1 has no way to report them.
+func (s *session) handleError(payload domain.ErrorFramePayload) error {
+	code := domain.ErrorCode(payload.GetErrorCode())
+	if s.connected {
+		s.handler.logger.Warn("mqtt operation refused by the broker",
+			"client_id", s.clientID,
+			"error_code", code,
+			"reason", payload.GetErrorMessage())
+		return nil
+	}
+
+	if s.handshake.Tolerate(payload) {
+		s.handler.logger.Debug("mqtt credentials ignored, authentication is disabled", "client_id", s.clientID)
+		return nil
+	}
+
+	returnCode := connackServerUnavailable
+	switch code {
+	case domain.ErrorCodeUnauthorized:
+		returnCode = connackNotAuthorized
+		if s.connect.hasUsername {
+			returnCode = connackBadCredentials
+		}
+	case domain.ErrorCodeForbidden:
+		returnCode = connackNotAuthorized
+	case domain.ErrorCodeUnsupportedVersion:
+		returnCode = connackUnacceptableProtocol
+	}
+
+	_ = s.writeConnack(returnCode)
+
+	return fmt.Errorf("connection refused by the broker: %s", payload.GetErrorMessage())
+}
+
+// deliver send a message routed by the broker with the highest QoS granted to the matching subscriptions.
+func (s *session) deliver(payload domain.MessageFramePayload) error {
+	var qos byte
+	for pattern, granted := range s.subscriptions {
+		if domain.MatchTopic(pattern, payload.GetTopic()) {
+			qos = max(qos, granted)
+		}
+	}
+
+	publish := &publishPacket{
+		topic:   toMQTTTopic(payload.GetTopic()),
+		qos:     qos,
+		retain:  false,
+		payload: payload.GetContent(),
+	}
+	if qos > 0 {
+		if len(s.inflight) >= maxInflight {
+			publish.qos = 0
+		} else {
+			publish.packetID = s.allocatePacketID()
+			s.inflight[publish.packetID] = struct{}{}
+		}
+	}
+
+	flags, body := encodePublish(publish)
+
+	return s.write(packetPublish, flags, body)
+}
+
+func (s *session) allocatePacketID() uint16 {
+	for {
+		s.nextPacketID++
+		if s.nextPacketID == 0 {
+			continue
+		}
+		if _, used := s.inflight[s.nextPacketID]; !used {
+			return s.nextPacketID
+		}
+	}
+}
+
+// publishWill hand the will message to the broker when the client vanished without DISCONNECT.
+func (s *session) publishWill(ctx context.Context) {
+	if !s.connected || s.disconnected || s.connect == nil || !s.connect.hasWill {
+		return
+	}
+
+	qos := min(s.connect.willQoS, maxQoS)
+	if err := s.publish(ctx, s.connect.willTopic, s.connect.willMessage, qos, s.connect.willRetain); err != nil {
+		s.handler.logger.Warn("failed to publish will message", "client_id", s.clientID, "error", err)
+	}
+}
+
+// sendFrame serialize the frame and hand it to the broker as a frame received from the client.
+func (s *session) sendFrame(ctx context.Context, frame domain.Frame) error {
+	data, err := s.handler.serializer.SerializeFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	evt := &events.MessageReceivedEvent{
+		Conn:      s.conn,
+		Message:   data,
+		Transport: domain.TransportTypeMQTT,
+		BaseEvent: events.BaseEvent{
+			EventType: domain.EventTypeReceiveMessage,
+		},
+	}
+
+	return s.handler.eb.Publish(ctx, evt)
+}
+
+func (s *session) writeConnack(code byte) error {
+	return s.write(packetConnack, 0, []byte{0, code})
+}
+
+func (s *session) write(kind, flags byte, body []byte) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	return writePacket(s.conn, kind, flags, body)
+}
@@ -0,0 +1,50 @@
+package mqtt
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidTopic is returned when an MQTT topic or topic filter can not be mapped to a HopperMQ topic.
+var ErrInvalidTopic = errors.New("invalid mqtt topic")
+
+// MQTT separate the topic levels with "/" where HopperMQ use ".", the "." and the HopperMQ wildcard "*" are
+// therefore refused in MQTT topics so both sides address the same topics, e.g. "sensors/+/temperature" is "sensors.*.temperature".
+
+// toHopperTopic map an MQTT topic name, without wildcards, to a HopperMQ topic.
+func toHopperTopic(topic string) (string, error) {
+	if topic == "" || strings.ContainsAny(topic, "+#.*") {
+		return "", ErrInvalidTopic
+	}
+
+	return strings.ReplaceAll(topic, "/", "."), nil
+}
+
+// toHopperFilter map an MQTT topic filter to a HopperMQ topic pattern,
+// "+" matching a single level and a trailing "#" any number of levels.
+func toHopperFilter(filter string) (string, error) {
+	if filter == "" || strings.ContainsAny(filter, ".*") {
+		return "", ErrInvalidTopic
+	}
+
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		switch {
+		case level == "+":
+			levels[i] = "*"
+		case level == "#":
+			if i != len(levels)-1 {
+				return "", ErrInvalidTopic
+			}
+		case strings.ContainsAny(level, "+#"):
+			return "", ErrInvalidTopic
+		}
+	}
+
+	return strings.Join(levels, "."), nil
+}
+
+// toMQTTTopic map a HopperMQ topic to an MQTT topic name.
+func toMQTTTopic(topic string) string {
+	return strings.ReplaceAll(topic, ".", "/")
+}
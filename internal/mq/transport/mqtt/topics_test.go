@@ -0,0 +1,76 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHopperTopic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		topic   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Levels", topic: "sensors/kitchen/temperature", want: "sensors.kitchen.temperature"},
+		{name: "Single_Level", topic: "alerts", want: "alerts"},
+		{name: "Empty", topic: "", wantErr: true},
+		{name: "Wildcard", topic: "sensors/+/temperature", wantErr: true},
+		{name: "Hopper_Separator", topic: "sensors.kitchen", wantErr: true},
+		{name: "Hopper_Wildcard", topic: "sensors/*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := toHopperTopic(tt.topic)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTopic)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.topic, toMQTTTopic(got))
+		})
+	}
+}
+
+func TestToHopperFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{name: "Exact", filter: "sensors/kitchen", want: "sensors.kitchen"},
+		{name: "Single_Level_Wildcard", filter: "sensors/+/temperature", want: "sensors.*.temperature"},
+		{name: "Multi_Level_Wildcard", filter: "sensors/#", want: "sensors.#"},
+		{name: "Everything", filter: "#", want: "#"},
+		{name: "Multi_Level_Wildcard_Not_Last", filter: "sensors/#/temperature", wantErr: true},
+		{name: "Wildcard_Inside_Level", filter: "sensors/kit+", wantErr: true},
+		{name: "Hopper_Separator", filter: "sensors.kitchen", wantErr: true},
+		{name: "Empty", filter: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := toHopperFilter(tt.filter)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTopic)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,33 +2,51 @@
 package tcp
 
 import (
-	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"log/slog"
 	"net"
-	"sync"
 	"time"
 
-	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/internal/security"
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+)
+
+const (
+	// tlsHandshakeTimeout bound the time a client has to complete the TLS handshake.
+	tlsHandshakeTimeout = 10 * time.Second
+	// readTimeout is the read deadline of a frame, an idle connection stay open past the deadline.
+	readTimeout     = 50 * time.Second
+	shutdownTimeout = 10 * time.Second
 )
 
 // TCP is an TCP handler.
 type TCP struct {
-	Listener net.Listener
-	logger   *slog.Logger
+	Listeners    []net.Listener
+	logger       *slog.Logger
+	maxFrameSize int
+
+	throttler transport.Throttler
+	conns     transport.Conns
 
 	eb domain.IEventBus
 
 	cancel context.CancelFunc
-	wg     sync.WaitGroup
 }
 
 type config struct {
-	lconf  *net.ListenConfig
-	logger *slog.Logger
+	lconf        *net.ListenConfig
+	logger       *slog.Logger
+	maxFrameSize int
+	tlsConfig    *tls.Config
+
+	endpoints     []Endpoint
+	socketOptions *SocketOptions
+	limiter       *transport.Limiter
 }
 
 type Option func(*config) error
@@ -49,9 +67,60 @@ func WithListener(listenerConfig *net.ListenConfig) Option {
 	}
 }
 
+// WithMaxFrameSize set the maximum size in bytes of a frame read from a connection.
+func WithMaxFrameSize(size int) Option {
+	return func(c *config) error {
+		if size <= protocol.FrameHeaderSize {
+			return domain.ErrInvalidFrame
+		}
+		c.maxFrameSize = size
+
+		return nil
+	}
+}
+
+// WithTLS set the TLS configuration of the endpoints requiring TLS.
+// Without endpoints, TLS is terminated on the default listener.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		c.tlsConfig = tlsConfig
+
+		return nil
+	}
+}
+
+// WithEndpoints listen on the given endpoints instead of the default :5672.
+func WithEndpoints(endpoints ...Endpoint) Option {
+	return func(c *config) error {
+		c.endpoints = append(c.endpoints, endpoints...)
+
+		return nil
+	}
+}
+
+// WithSocketOptions apply the options to the TCP connections accepted by the listeners.
+func WithSocketOptions(options SocketOptions) Option {
+	return func(c *config) error {
+		c.socketOptions = &options
+
+		return nil
+	}
+}
+
+// WithLimiter admit the accepted connections through the limiter shared by the transports.
+func WithLimiter(limiter *transport.Limiter) Option {
+	return func(c *config) error {
+		c.limiter = limiter
+
+		return nil
+	}
+}
+
 // NewTCP return the new tcp handler.
 func NewTCP(ctx context.Context, opts ...Option) (*TCP, error) {
-	handlerConfig := &config{}
+	handlerConfig := &config{
+		maxFrameSize: protocol.DefaultMaxFrameSize,
+	}
 	for _, opt := range opts {
 		err := opt(handlerConfig)
 		if err != nil {
@@ -59,20 +128,53 @@ func NewTCP(ctx context.Context, opts ...Option) (*TCP, error) {
 		}
 	}
 
-	l, err := handlerConfig.lconf.Listen(ctx, "tcp", ":5672")
-	if err != nil {
-		return nil, err
+	if handlerConfig.lconf == nil {
+		handlerConfig.lconf = &net.ListenConfig{}
+	}
+
+	endpoints := handlerConfig.endpoints
+	if len(endpoints) == 0 {
+		endpoints = []Endpoint{{
+			Network: NetworkTCP,
+			Address: defaultAddress,
+			TLS:     handlerConfig.tlsConfig != nil,
+		}}
+	}
+
+	listeners := make([]net.Listener, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		l, err := handlerConfig.listen(ctx, endpoint)
+		if err != nil {
+			for _, opened := range listeners {
+				_ = opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
 	}
 
 	return &TCP{
-		Listener: l,
-		logger:   handlerConfig.logger,
+		Listeners:    listeners,
+		logger:       handlerConfig.logger,
+		maxFrameSize: handlerConfig.maxFrameSize,
 	}, nil
 }
 
+// HandleConnection accept the connections of every listener, it return once a listener stop accepting.
 func (t *TCP) HandleConnection(ctx context.Context) error {
+	errCh := make(chan error, len(t.Listeners))
+	for _, l := range t.Listeners {
+		go func(l net.Listener) {
+			errCh <- t.serve(ctx, l)
+		}(l)
+	}
+
+	return <-errCh
+}
+
+func (t *TCP) serve(ctx context.Context, l net.Listener) error {
 	for {
-		conn, err := t.Listener.Accept()
+		conn, err := l.Accept()
 		if err != nil {
 			select {
 			case <-ctx.Done():
@@ -83,110 +185,64 @@ func (t *TCP) HandleConnection(ctx context.Context) error {
 				return err
 			}
 		}
+
 		go t.processConnection(conn, ctx)
 	}
 }
 
 func (t *TCP) processConnection(conn domain.Connection, ctx context.Context) {
-	t.wg.Add(1)
-	defer t.wg.Done()
-
-	defer func(conn domain.Connection) {
-		err := conn.Close()
-		if err != nil {
+	identity, err := t.handshake(ctx, conn)
+	if err != nil {
+		t.logger.Warn("tls handshake failed", "error", err)
+		if err := conn.Close(); err != nil {
 			t.logger.Warn("failed to close connection", "error", err)
 		}
-	}(conn)
-
-	if t.eb == nil {
-		t.logger.Warn("EventBus not registered, skipping event publishing")
 		return
 	}
 
-	evt := &events.NewConnectionEvent{
-		Conn:      conn,
-		Transport: domain.TransportTypeTCP,
-		BaseEvent: events.BaseEvent{
-			EventType: domain.EventTypeNewConnection,
-		},
-	}
-
-	if err := t.eb.Publish(ctx, evt); err != nil {
-		t.logger.Warn("failed to publish new connection event", "error", err)
-		return
-	}
-
-	t.logger.Info(
-		"channel event published",
-		"publisher",
-		t.Name(),
-		"event",
-		evt.GetType(),
-		"transport",
-		evt.GetType(),
-	)
+	t.frames().Serve(ctx, t.eb, &t.conns, conn, identity)
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			err := t.receiveMsg(conn, ctx)
-			if err != nil {
-				return
-			}
-		}
+// frames return the frame server of the TCP connections.
+func (t *TCP) frames() transport.FrameServer {
+	return transport.FrameServer{
+		Transport:    domain.TransportTypeTCP,
+		Logger:       t.logger,
+		MaxFrameSize: t.maxFrameSize,
+		ReadTimeout:  readTimeout,
+		Throttler:    &t.throttler,
 	}
 }
 
-func (t *TCP) receiveMsg(conn domain.Connection, ctx context.Context) error {
-	reader := bufio.NewReader(conn)
-	if err := conn.SetReadDeadline(time.Now().Add(50 * time.Second)); err != nil {
-		t.logger.Warn("failed to set read deadline", "error", err)
-		return err
+// dispatcher return the dispatcher writing the frames sent by the broker to the TCP connections.
+func (t *TCP) dispatcher() transport.Dispatcher {
+	return transport.Dispatcher{
+		Transport: domain.TransportTypeTCP,
+		Sink:      t.frames().Sink,
+		Throttler: &t.throttler,
 	}
+}
 
-	msg, err := reader.ReadBytes('\n')
-	if err != nil {
-		if err == io.EOF {
-			t.logger.Info("client disconnected from tcp")
-			evt := &events.ClientDisconnectedEvent{
-				Transport: domain.TransportTypeTCP,
-				Conn:      conn,
-				BaseEvent: events.BaseEvent{
-					EventType: domain.EventTypeConnectionClosed,
-				},
-			}
-
-			if err := t.eb.Publish(ctx, evt); err != nil {
-				t.logger.Warn("failed to publish client disconnected event", "error", err)
-				return err
-			}
-
-			return err
-		}
+// handshake complete the TLS handshake of the connection, when TLS is enabled,
+// and return the subject of the client certificate.
+func (t *TCP) handshake(ctx context.Context, conn domain.Connection) (string, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return "", nil
 	}
 
-	t.logger.Info("new message receive", "content", msg)
-	if t.eb == nil {
-		t.logger.Warn("EventBus not registered, skipping event publishing")
-		return err
-	}
+	ctx, cancel := context.WithTimeout(ctx, tlsHandshakeTimeout)
+	defer cancel()
 
-	evt := &events.MessageReceivedEvent{
-		Message:   msg,
-		Transport: domain.TransportTypeTCP,
-		BaseEvent: events.BaseEvent{
-			EventType: domain.EventTypeReceiveMessage,
-		},
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return "", err
 	}
 
-	if err := t.eb.Publish(ctx, evt); err != nil {
-		t.logger.Warn("failed to publish message event", "error", err)
-		return err
-	}
+	return security.PeerIdentity(tlsConn.ConnectionState()), nil
+}
 
-	return nil
+func (t *TCP) receiveMsg(conn domain.Connection, reader io.Reader, ctx context.Context) error {
+	return t.frames().Receive(ctx, t.eb, conn, reader)
 }
 
 // Run wil start the tcp component.
@@ -195,14 +251,12 @@ func (t *TCP) Run(ctx context.Context) error {
 
 	ctx, t.cancel = context.WithCancel(ctx)
 
-	msgSenderCh := t.eb.Subscribe(string(domain.EventTypeSendMessage))
-
-	t.spawnHandler(ctx, func(ctx context.Context) {
-		t.handleMessageSending(ctx, msgSenderCh)
-	})
+	t.dispatcher().Run(ctx, t.eb, &t.conns)
 
 	go func() {
-		t.logger.Info("TCP server running", "port", t.Listener.Addr().String())
+		for _, l := range t.Listeners {
+			t.logger.Info("TCP server running", "network", l.Addr().Network(), "address", l.Addr().String())
+		}
 		if err := t.HandleConnection(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			t.logger.Warn("TCP Handler failed", "error", err)
 		}
@@ -219,20 +273,15 @@ func (t *TCP) Stop(ctx context.Context) error {
 		t.cancel()
 	}
 
-	if err := t.Listener.Close(); err != nil {
-		t.logger.Warn("error closing listener", "error", err)
+	for _, l := range t.Listeners {
+		if err := l.Close(); err != nil {
+			t.logger.Warn("error closing listener", "address", l.Addr().String(), "error", err)
+		}
 	}
 
-	done := make(chan struct{})
-	go func() {
-		t.wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
+	if t.conns.Close(shutdownTimeout) {
 		t.logger.Info("All connections closed gracefully")
-	case <-time.After(10 * time.Second):
+	} else {
 		t.logger.Warn("Timeout waiting for connections to close")
 	}
 
@@ -251,26 +300,5 @@ func (t *TCP) RegisterEventBus(eb domain.IEventBus) {
 }
 
 func (t *TCP) handleMessageSending(ctx context.Context, ch <-chan domain.Event) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case evt, ok := <-ch:
-			if !ok {
-				return
-			}
-			if c, ok := evt.(*events.SendMessageEvent); ok {
-				t.logger.Info("new message event received", "transport", c.Transport, "clientID", c.ClientID)
-				t.sendMessage(c.Message, c.Conn)
-			}
-		}
-	}
-}
-
-func (t *TCP) spawnHandler(ctx context.Context, eventHandler func(ctx2 context.Context)) {
-	t.wg.Add(1)
-	go func() {
-		defer t.wg.Done()
-		eventHandler(ctx)
-	}()
+	t.dispatcher().SendMessages(ctx, ch)
 }
@@ -1,7 +1,10 @@
 package tcp
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"io"
 	"log/slog"
@@ -10,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/transport"
 	"github.com/hoppermq/hopper/pkg/domain"
 	"github.com/hoppermq/hopper/pkg/domain/mocks"
 	"github.com/stretchr/testify/assert"
@@ -121,6 +125,17 @@ func TestTCP_Options(t *testing.T) {
 				return assert.NotNil(t, config.lconf)
 			},
 		},
+		{
+			name: "WithLimiter_Option",
+			args: args{
+				option: WithLimiter(&transport.Limiter{}),
+			},
+			want: true,
+			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
+				config := value.(*config)
+				return assert.NotNil(t, config.limiter)
+			},
+		},
 		{
 			name: "WithLogger_Nil_Option",
 			args: args{
@@ -232,9 +247,11 @@ func TestTCP_Stop(t *testing.T) {
 			name: "Stop_Without_Run",
 			args: args{
 				tcp: &TCP{
-					Listener: &mockListener{
-						acceptCh: make(chan acceptResult, 1),
-						closeCh:  make(chan struct{}),
+					Listeners: []net.Listener{
+						&mockListener{
+							acceptCh: make(chan acceptResult, 1),
+							closeCh:  make(chan struct{}),
+						},
 					},
 					logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
 					eb:     mocks.NewMockIEventBus(t),
@@ -253,9 +270,11 @@ func TestTCP_Stop(t *testing.T) {
 			name: "Stop_With_Nil_Cancel",
 			args: args{
 				tcp: &TCP{
-					Listener: &mockListener{
-						acceptCh: make(chan acceptResult, 1),
-						closeCh:  make(chan struct{}),
+					Listeners: []net.Listener{
+						&mockListener{
+							acceptCh: make(chan acceptResult, 1),
+							closeCh:  make(chan struct{}),
+						},
 					},
 					logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
 					cancel: nil,
@@ -303,9 +322,11 @@ func TestTCP_ComponentValidation(t *testing.T) {
 				tcp: &TCP{
 					logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
 					eb:     mocks.NewMockIEventBus(t),
-					Listener: &mockListener{
-						acceptCh: make(chan acceptResult, 1),
-						closeCh:  make(chan struct{}),
+					Listeners: []net.Listener{
+						&mockListener{
+							acceptCh: make(chan acceptResult, 1),
+							closeCh:  make(chan struct{}),
+						},
 					},
 				},
 			},
@@ -316,7 +337,7 @@ func TestTCP_ComponentValidation(t *testing.T) {
 				return assert.Equal(t, "tcp-handler", args.tcp.Name()) &&
 					assert.NotNil(t, args.tcp.logger) &&
 					assert.NotNil(t, args.tcp.eb) &&
-					assert.NotNil(t, args.tcp.Listener)
+					assert.NotEmpty(t, args.tcp.Listeners)
 			},
 		},
 		{
@@ -492,15 +513,14 @@ func TestTCP_ProcessConnection(t *testing.T) {
 				mockConn.On("SetReadDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
 				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
 					buf := args[0].([]byte)
-					data := []byte("hello world\n")
-					copy(buf, data)
-				}).Return(len("hello world\n"), nil).Once()
+					copy(buf, encodedFrame("hello world"))
+				}).Return(len(encodedFrame("hello world")), nil).Once()
 
 				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
 					msgEvt, ok := evt.(*events.MessageReceivedEvent)
 					return ok &&
 						msgEvt.Transport == domain.TransportTypeTCP &&
-						string(msgEvt.Message) == "hello world\n"
+						bytes.Equal(msgEvt.Message, encodedFrame("hello world"))
 				})).Return(nil).Once()
 
 				mockConn.On("SetReadDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
@@ -548,15 +568,14 @@ func TestTCP_ReceiveMessage(t *testing.T) {
 				mockConn.On("SetReadDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
 				mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
 					buf := args[0].([]byte)
-					data := []byte("hello world\n")
-					copy(buf, data)
-				}).Return(len("hello world\n"), nil).Once()
+					copy(buf, encodedFrame("hello world"))
+				}).Return(len(encodedFrame("hello world")), nil).Once()
 
 				mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
 					msgEvt, ok := evt.(*events.MessageReceivedEvent)
 					return ok &&
 						msgEvt.Transport == domain.TransportTypeTCP &&
-						string(msgEvt.Message) == "hello world\n"
+						bytes.Equal(msgEvt.Message, encodedFrame("hello world"))
 				})).Return(nil).Once()
 			},
 			expectError: false,
@@ -589,7 +608,7 @@ func TestTCP_ReceiveMessage(t *testing.T) {
 			}
 
 			tt.setupMocks(mockConn, mockEB)
-			err := tcp.receiveMsg(mockConn, context.Background())
+			err := tcp.receiveMsg(mockConn, bufio.NewReader(mockConn), context.Background())
 			if tt.expectError {
 				assert.Error(t, err)
 			} else {
@@ -599,6 +618,35 @@ func TestTCP_ReceiveMessage(t *testing.T) {
 	}
 }
 
+func TestTCP_ReceiveMessage_FrameTooLarge(t *testing.T) {
+	t.Parallel()
+
+	mockConn := mocks.NewMockConnection(t)
+	mockEB := mocks.NewMockIEventBus(t)
+
+	tcp := &TCP{
+		logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		eb:           mockEB,
+		maxFrameSize: 8,
+	}
+
+	mockConn.On("SetReadDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockConn.On("Read", mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
+		buf := args[0].([]byte)
+		copy(buf, encodedFrame("hello world"))
+	}).Return(len(encodedFrame("hello world")), nil).Once()
+
+	mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+		invalidEvt, ok := evt.(*events.InvalidFrameEvent)
+		return ok &&
+			invalidEvt.Conn == mockConn &&
+			errors.Is(invalidEvt.Err, domain.ErrFrameTooLarge)
+	})).Return(nil).Once()
+
+	err := tcp.receiveMsg(mockConn, bufio.NewReader(mockConn), context.Background())
+	assert.NoError(t, err)
+}
+
 type mockListener struct {
 	acceptCh chan acceptResult
 	closeCh  chan struct{}
@@ -633,3 +681,11 @@ func (m *mockListener) Addr() net.Addr {
 		Port: 9091,
 	}
 }
+
+func encodedFrame(payload string) []byte {
+	frame := make([]byte, 6, 6+len(payload))
+	binary.BigEndian.PutUint16(frame[0:2], uint16(len(payload)))
+	binary.BigEndian.PutUint16(frame[2:4], uint16(domain.DOFF4))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(domain.FrameTypeMessage))
+	return append(frame, payload...)
+}
@@ -0,0 +1,163 @@
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+const (
+	// NetworkTCP listen on a TCP address, e.g. "127.0.0.1:5672".
+	NetworkTCP = "tcp"
+	// NetworkUnix listen on a Unix domain socket path, e.g. "/var/run/hopper.sock".
+	NetworkUnix = "unix"
+
+	defaultAddress = ":5672"
+)
+
+var (
+	// ErrInvalidNetwork is returned when an endpoint network is neither tcp nor unix.
+	ErrInvalidNetwork = errors.New("invalid network")
+	// ErrMissingTLSConfig is returned when an endpoint require TLS without TLS configuration.
+	ErrMissingTLSConfig = errors.New("missing tls configuration")
+)
+
+// Endpoint describe an address the transport listen on.
+type Endpoint struct {
+	Network string
+	Address string
+	// TLS terminate TLS on the endpoint with the configuration given by WithTLS.
+	TLS bool
+}
+
+// SocketOptions hold the options applied to the accepted TCP connections.
+type SocketOptions struct {
+	KeepAlive       bool
+	KeepAlivePeriod time.Duration
+	NoDelay         bool
+	// Linger set SO_LINGER when positive, the OS default is kept otherwise.
+	Linger time.Duration
+	// ReadBufferSize and WriteBufferSize set the kernel buffers when positive.
+	ReadBufferSize  int
+	WriteBufferSize int
+}
+
+func (o *SocketOptions) apply(conn *net.TCPConn) error {
+	if err := conn.SetKeepAlive(o.KeepAlive); err != nil {
+		return err
+	}
+	if o.KeepAlive && o.KeepAlivePeriod > 0 {
+		if err := conn.SetKeepAlivePeriod(o.KeepAlivePeriod); err != nil {
+			return err
+		}
+	}
+	if err := conn.SetNoDelay(o.NoDelay); err != nil {
+		return err
+	}
+	if o.Linger > 0 {
+		if err := conn.SetLinger(int(o.Linger / time.Second)); err != nil {
+			return err
+		}
+	}
+	if o.ReadBufferSize > 0 {
+		if err := conn.SetReadBuffer(o.ReadBufferSize); err != nil {
+			return err
+		}
+	}
+	if o.WriteBufferSize > 0 {
+		if err := conn.SetWriteBuffer(o.WriteBufferSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// socketListener apply the socket options to every accepted TCP connection.
+// A connection the options can't be applied to is closed, the listener keep accepting.
+type socketListener struct {
+	net.Listener
+	options *SocketOptions
+	logger  *slog.Logger
+}
+
+func (l *socketListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		tcpConn, ok := conn.(*net.TCPConn)
+		if !ok {
+			return conn, nil
+		}
+
+		if err := l.options.apply(tcpConn); err != nil {
+			l.logger.Warn("failed to apply socket options, closing connection",
+				"remote_addr", conn.RemoteAddr().String(), "error", err)
+			_ = conn.Close()
+			continue
+		}
+
+		return conn, nil
+	}
+}
+
+// listen open the listener of the endpoint.
+func (c *config) listen(ctx context.Context, endpoint Endpoint) (net.Listener, error) {
+	switch endpoint.Network {
+	case NetworkTCP:
+	case NetworkUnix:
+		if err := removeStaleSocket(endpoint.Address); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidNetwork, endpoint.Network)
+	}
+
+	if endpoint.TLS && c.tlsConfig == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrMissingTLSConfig, endpoint.Network, endpoint.Address)
+	}
+
+	l, err := c.lconf.Listen(ctx, endpoint.Network, endpoint.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.socketOptions != nil && endpoint.Network == NetworkTCP {
+		l = &socketListener{Listener: l, options: c.socketOptions, logger: c.logger}
+	}
+
+	l = c.limiter.Listener(l, domain.TransportTypeTCP, c.logger)
+
+	if endpoint.TLS {
+		l = tls.NewListener(l, c.tlsConfig)
+	}
+
+	return l, nil
+}
+
+// removeStaleSocket remove the socket file left by a previous run, so the path can be bound again.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,168 @@
+package tcp
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTCP_Endpoints(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "hopper.sock")
+
+	tcp, err := NewTCP(
+		context.Background(),
+		WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))),
+		WithEndpoints(
+			Endpoint{Network: NetworkTCP, Address: "127.0.0.1:0"},
+			Endpoint{Network: NetworkUnix, Address: socket},
+		),
+		WithSocketOptions(SocketOptions{
+			KeepAlive:       true,
+			KeepAlivePeriod: 30 * time.Second,
+			NoDelay:         true,
+			Linger:          5 * time.Second,
+			ReadBufferSize:  8192,
+			WriteBufferSize: 8192,
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, tcp.Listeners, 2)
+	t.Cleanup(func() {
+		for _, l := range tcp.Listeners {
+			_ = l.Close()
+		}
+	})
+
+	for _, l := range tcp.Listeners {
+		accepted := make(chan error, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err == nil {
+				_ = conn.Close()
+			}
+			accepted <- err
+		}()
+
+		conn, err := net.Dial(l.Addr().Network(), l.Addr().String())
+		require.NoError(t, err)
+		_ = conn.Close()
+
+		assert.NoError(t, <-accepted, l.Addr().Network())
+	}
+}
+
+func TestNewTCP_Endpoints_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		wantErr  error
+	}{
+		{
+			name:     "Unknown_Network",
+			endpoint: Endpoint{Network: "udp", Address: "127.0.0.1:0"},
+			wantErr:  ErrInvalidNetwork,
+		},
+		{
+			name:     "TLS_Without_Config",
+			endpoint: Endpoint{Network: NetworkTCP, Address: "127.0.0.1:0", TLS: true},
+			wantErr:  ErrMissingTLSConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTCP(context.Background(), WithEndpoints(tt.endpoint))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// queueListener return the queued connections.
+type queueListener struct {
+	net.Listener
+	conns chan net.Conn
+}
+
+func (l *queueListener) Accept() (net.Conn, error) {
+	conn, ok := <-l.conns
+	if !ok {
+		return nil, net.ErrClosed
+	}
+
+	return conn, nil
+}
+
+func TestSocketListener_Accept_Skip_Failed_Options(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen(NetworkTCP, "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	accept := func() net.Conn {
+		client, err := net.Dial(NetworkTCP, l.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = client.Close() })
+
+		conn, err := l.Accept()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		return conn
+	}
+
+	// the options can't be applied to a closed connection.
+	closed := accept()
+	require.NoError(t, closed.Close())
+	open := accept()
+
+	queue := &queueListener{Listener: l, conns: make(chan net.Conn, 2)}
+	queue.conns <- closed
+	queue.conns <- open
+	close(queue.conns)
+
+	listener := &socketListener{
+		Listener: queue,
+		options:  &SocketOptions{NoDelay: true},
+		logger:   slog.New(slog.DiscardHandler),
+	}
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	assert.Same(t, open, conn)
+
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "stale.sock")
+
+	l, err := net.Listen(NetworkUnix, socket)
+	require.NoError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+
+	assert.NoError(t, removeStaleSocket(socket))
+	assert.NoFileExists(t, socket)
+
+	file := filepath.Join(dir, "regular")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	assert.Error(t, removeStaleSocket(file))
+}
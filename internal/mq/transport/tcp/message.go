@@ -1,17 +1,9 @@
 package tcp
 
 import (
-	"time"
-
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
-func (t *TCP) sendMessage(d []byte, conn domain.Connection) {
-	_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second)) //nolint:mnd
-	_, err := conn.Write(d)
-	if err != nil {
-		t.logger.Warn("error sending message", "error", err)
-		return
-	}
-	t.logger.Info("message has been delivered")
+func (t *TCP) sendMessage(d []byte, conn domain.Connection) error {
+	return t.frames().Send(conn, d)
 }
@@ -0,0 +1,61 @@
+// Package transport hold the helpers shared by the transport handlers.
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Throttler pause the reads of the connections throttled by the broker.
+// The zero value is ready to use.
+type Throttler struct {
+	mu       sync.Mutex
+	resumeAt map[domain.Connection]time.Time
+}
+
+// Throttle pause the reads of the connection for the given delay.
+func (t *Throttler) Throttle(conn domain.Connection, delay time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.resumeAt == nil {
+		t.resumeAt = make(map[domain.Connection]time.Time)
+	}
+
+	resumeAt := time.Now().Add(delay)
+	if resumeAt.After(t.resumeAt[conn]) {
+		t.resumeAt[conn] = resumeAt
+	}
+}
+
+// Forget drop the state held for the connection.
+func (t *Throttler) Forget(conn domain.Connection) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.resumeAt, conn)
+}
+
+// Wait block until the reads of the connection may resume, it return false when the context is done.
+func (t *Throttler) Wait(ctx context.Context, conn domain.Connection) bool {
+	t.mu.Lock()
+	resumeAt, ok := t.resumeAt[conn]
+	t.mu.Unlock()
+
+	if !ok {
+		return true
+	}
+
+	timer := time.NewTimer(time.Until(resumeAt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
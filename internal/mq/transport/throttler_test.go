@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestThrottler(t *testing.T) {
+	t.Parallel()
+
+	var throttler Throttler
+	conn := mocks.NewMockConnection(t)
+
+	assert.True(t, throttler.Wait(context.Background(), conn))
+
+	throttler.Throttle(conn, 50*time.Millisecond)
+	start := time.Now()
+	assert.True(t, throttler.Wait(context.Background(), conn))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	throttler.Throttle(conn, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, throttler.Wait(ctx, conn))
+
+	throttler.Forget(conn)
+	assert.Empty(t, throttler.resumeAt)
+}
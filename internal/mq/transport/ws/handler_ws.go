@@ -0,0 +1,335 @@
+// Package ws provides the WebSocket transport handler, carrying HopperMQ frames in binary messages.
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+)
+
+const (
+	defaultPath     = "/ws"
+	shutdownTimeout = 10 * time.Second
+	anyOrigin       = "*"
+)
+
+// ErrOriginNotAllowed is returned when a browser connect from an origin not allowed to.
+var ErrOriginNotAllowed = errors.New("origin not allowed")
+
+// WebSocket is a WebSocket handler.
+type WebSocket struct {
+	// server is nil when the handler is mounted on another HTTP server.
+	server         *http.Server
+	logger         *slog.Logger
+	path           string
+	allowedOrigins []string
+	maxFrameSize   int
+	limiter        *transport.Limiter
+
+	throttler transport.Throttler
+	conns     transport.Conns
+
+	eb domain.IEventBus
+
+	cancel context.CancelFunc
+}
+
+type config struct {
+	logger         *slog.Logger
+	address        string
+	path           string
+	allowedOrigins []string
+	maxFrameSize   int
+	tlsConfig      *tls.Config
+	limiter        *transport.Limiter
+}
+
+// Option configure the WebSocket handler.
+type Option func(*config) error
+
+// WithLogger inject the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+
+		return nil
+	}
+}
+
+// WithAddress serve the handler on a dedicated HTTP server listening on the address.
+// Without address the handler must be mounted on an existing HTTP server with Handler.
+func WithAddress(address string) Option {
+	return func(c *config) error {
+		c.address = address
+
+		return nil
+	}
+}
+
+// WithPath set the path the handler is served on, /ws by default.
+func WithPath(path string) Option {
+	return func(c *config) error {
+		if path != "" {
+			c.path = path
+		}
+
+		return nil
+	}
+}
+
+// WithAllowedOrigins set the origins browsers may connect from, * allow every origin.
+// Without allowed origins, browsers may only connect from the host serving the handler.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(c *config) error {
+		c.allowedOrigins = append(c.allowedOrigins, origins...)
+
+		return nil
+	}
+}
+
+// WithMaxFrameSize set the maximum size in bytes of a frame read from a connection.
+func WithMaxFrameSize(size int) Option {
+	return func(c *config) error {
+		if size <= protocol.FrameHeaderSize {
+			return domain.ErrInvalidFrame
+		}
+		c.maxFrameSize = size
+
+		return nil
+	}
+}
+
+// WithTLS terminate TLS on the dedicated HTTP server with the given configuration.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		c.tlsConfig = tlsConfig
+
+		return nil
+	}
+}
+
+// WithLimiter admit the connections through the limiter shared by the transports.
+func WithLimiter(limiter *transport.Limiter) Option {
+	return func(c *config) error {
+		c.limiter = limiter
+
+		return nil
+	}
+}
+
+// NewWebSocket return the new WebSocket handler.
+func NewWebSocket(opts ...Option) (*WebSocket, error) {
+	handlerConfig := &config{
+		path:         defaultPath,
+		maxFrameSize: protocol.DefaultMaxFrameSize,
+	}
+	for _, opt := range opts {
+		if err := opt(handlerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	w := &WebSocket{
+		logger:         handlerConfig.logger,
+		path:           handlerConfig.path,
+		allowedOrigins: handlerConfig.allowedOrigins,
+		maxFrameSize:   handlerConfig.maxFrameSize,
+		limiter:        handlerConfig.limiter,
+	}
+
+	if handlerConfig.address != "" {
+		mux := http.NewServeMux()
+		mux.Handle(w.path, w.upgrader())
+		w.server = &http.Server{
+			Addr:              handlerConfig.address,
+			Handler:           mux,
+			TLSConfig:         handlerConfig.tlsConfig,
+			ReadHeaderTimeout: 10 * time.Second, //nolint:mnd
+		}
+	}
+
+	return w, nil
+}
+
+// Path return the path the handler is served on.
+func (w *WebSocket) Path() string {
+	return w.path
+}
+
+// Handler return the HTTP handler upgrading the requests to WebSocket connections,
+// to mount on another HTTP server.
+func (w *WebSocket) Handler() http.Handler {
+	return w.limiter.Handler(w.upgrader(), domain.TransportTypeWebSocket, w.logger)
+}
+
+// upgrader upgrade the requests to WebSocket connections, the dedicated HTTP server limit its listener instead.
+func (w *WebSocket) upgrader() http.Handler {
+	return websocket.Server{
+		Handshake: w.checkOrigin,
+		Handler:   w.serveConn,
+	}
+}
+
+// checkOrigin accept the clients without Origin header, i.e. non-browser clients,
+// and the browsers connecting from an allowed origin.
+func (w *WebSocket) checkOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return nil
+	}
+	cfg.Origin = origin
+
+	if !w.originAllowed(origin, r.Host) {
+		w.logger.Warn("websocket origin refused", "origin", origin.String(), "remote_addr", r.RemoteAddr)
+		return ErrOriginNotAllowed
+	}
+
+	return nil
+}
+
+func (w *WebSocket) originAllowed(origin *url.URL, host string) bool {
+	if len(w.allowedOrigins) == 0 {
+		return strings.EqualFold(origin.Host, host)
+	}
+
+	for _, allowed := range w.allowedOrigins {
+		if allowed == anyOrigin || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin.Scheme+"://"+origin.Host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HandleConnection serve the dedicated HTTP server, or wait for the context when the handler is mounted elsewhere.
+func (w *WebSocket) HandleConnection(ctx context.Context) error {
+	if w.server == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	l, err := net.Listen("tcp", w.server.Addr)
+	if err != nil {
+		return err
+	}
+	l = w.limiter.Listener(l, domain.TransportTypeWebSocket, w.logger)
+
+	if w.server.TLSConfig != nil {
+		err = w.server.ServeTLS(l, "", "")
+	} else {
+		err = w.server.Serve(l)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+func (w *WebSocket) serveConn(conn *websocket.Conn) {
+	conn.PayloadType = websocket.BinaryFrame
+
+	identity := ""
+	if state := conn.Request().TLS; state != nil {
+		identity = security.PeerIdentity(*state)
+	}
+
+	w.logger.Info("websocket client connected", "remote_addr", conn.Request().RemoteAddr)
+	w.frames().Serve(conn.Request().Context(), w.eb, &w.conns, conn, identity)
+}
+
+// frames return the frame server of the WebSocket connections.
+func (w *WebSocket) frames() transport.FrameServer {
+	return transport.FrameServer{
+		Transport:    domain.TransportTypeWebSocket,
+		Logger:       w.logger,
+		MaxFrameSize: w.maxFrameSize,
+		Throttler:    &w.throttler,
+	}
+}
+
+// dispatcher return the dispatcher writing the frames sent by the broker to the WebSocket connections.
+func (w *WebSocket) dispatcher() transport.Dispatcher {
+	return transport.Dispatcher{
+		Transport: domain.TransportTypeWebSocket,
+		Sink:      w.frames().Sink,
+		Throttler: &w.throttler,
+	}
+}
+
+// Run wil start the WebSocket component.
+func (w *WebSocket) Run(ctx context.Context) error {
+	w.logger.Info("starting WebSocket component", "path", w.path)
+
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.dispatcher().Run(ctx, w.eb, &w.conns)
+
+	go func() {
+		if w.server != nil {
+			w.logger.Info("WebSocket server running", "address", w.server.Addr)
+		}
+		if err := w.HandleConnection(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			w.logger.Warn("WebSocket Handler failed", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop will shut down gracefully the WebSocket component.
+func (w *WebSocket) Stop(ctx context.Context) error {
+	w.logger.Info("stopping WebSocket Component")
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	if w.server != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+		defer cancel()
+		if err := w.server.Shutdown(shutdownCtx); err != nil {
+			w.logger.Warn("error shutting down websocket server", "error", err)
+		}
+	}
+
+	if w.conns.Close(shutdownTimeout) {
+		w.logger.Info("All websocket connections closed gracefully")
+	} else {
+		w.logger.Warn("Timeout waiting for websocket connections to close")
+	}
+
+	return nil
+}
+
+// Name will return the component name.
+func (w *WebSocket) Name() string {
+	return "websocket-handler"
+}
+
+// RegisterEventBus will attach the event bus to the component.
+func (w *WebSocket) RegisterEventBus(eb domain.IEventBus) {
+	w.eb = eb
+	w.logger.Info("EventBus registered with WebSocket", "service", w.Name())
+}
+
+func (w *WebSocket) handleMessageSending(ctx context.Context, ch <-chan domain.Event) {
+	w.dispatcher().SendMessages(ctx, ch)
+}
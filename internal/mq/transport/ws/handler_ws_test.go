@@ -0,0 +1,255 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+)
+
+func TestWebSocket_Options(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		option  Option
+		want    bool
+		wantErr assert.ValueAssertionFunc
+	}{
+		{
+			name:   "WithAddress_Option",
+			option: WithAddress(":8083"),
+			want:   true,
+			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
+				return assert.Equal(t, ":8083", value.(*config).address)
+			},
+		},
+		{
+			name:   "WithPath_Option",
+			option: WithPath("/hopper"),
+			want:   true,
+			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
+				return assert.Equal(t, "/hopper", value.(*config).path)
+			},
+		},
+		{
+			name:   "WithAllowedOrigins_Option",
+			option: WithAllowedOrigins("https://shop.example.com"),
+			want:   true,
+			wantErr: func(t assert.TestingT, value interface{}, msgAndArgs ...interface{}) bool {
+				return assert.Equal(t, []string{"https://shop.example.com"}, value.(*config).allowedOrigins)
+			},
+		},
+		{
+			name:   "WithMaxFrameSize_Too_Small_Option",
+			option: WithMaxFrameSize(2),
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &config{}
+			err := tt.option(config)
+
+			assert.Equal(t, tt.want, err == nil)
+
+			if tt.wantErr != nil {
+				tt.wantErr(t, config)
+			}
+		})
+	}
+}
+
+func TestWebSocket_OriginAllowed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		host    string
+		want    bool
+	}{
+		{
+			name:   "Same_Host_Without_Allowed_Origins",
+			origin: "https://broker.example.com",
+			host:   "broker.example.com",
+			want:   true,
+		},
+		{
+			name:   "Other_Host_Without_Allowed_Origins",
+			origin: "https://evil.example.com",
+			host:   "broker.example.com",
+			want:   false,
+		},
+		{
+			name:    "Allowed_Origin",
+			allowed: []string{"https://shop.example.com/"},
+			origin:  "https://shop.example.com",
+			host:    "broker.example.com",
+			want:    true,
+		},
+		{
+			name:    "Allowed_Origin_Other_Scheme",
+			allowed: []string{"https://shop.example.com"},
+			origin:  "http://shop.example.com",
+			host:    "broker.example.com",
+			want:    false,
+		},
+		{
+			name:    "Any_Origin",
+			allowed: []string{"*"},
+			origin:  "https://evil.example.com",
+			host:    "broker.example.com",
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			origin, err := url.Parse(tt.origin)
+			require.NoError(t, err)
+
+			w := &WebSocket{allowedOrigins: tt.allowed}
+			assert.Equal(t, tt.want, w.originAllowed(origin, tt.host))
+		})
+	}
+}
+
+func TestWebSocket_ServeConn(t *testing.T) {
+	t.Parallel()
+
+	mockEB := mocks.NewMockIEventBus(t)
+	w, err := NewWebSocket(WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))))
+	require.NoError(t, err)
+	w.RegisterEventBus(mockEB)
+
+	frame := encodedFrame("hello world")
+	received := make(chan struct{})
+	disconnected := make(chan struct{})
+
+	mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+		newConnEvt, ok := evt.(*events.NewConnectionEvent)
+		return ok && newConnEvt.Transport == domain.TransportTypeWebSocket
+	})).Return(nil).Once()
+	mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+		msgEvt, ok := evt.(*events.MessageReceivedEvent)
+		return ok && bytes.Equal(msgEvt.Message, frame)
+	})).Run(func(mock.Arguments) { close(received) }).Return(nil).Once()
+	mockEB.On("Publish", mock.Anything, mock.MatchedBy(func(evt domain.Event) bool {
+		_, ok := evt.(*events.ClientDisconnectedEvent)
+		return ok
+	})).Run(func(mock.Arguments) { close(disconnected) }).Return(nil).Once()
+
+	server := httptest.NewServer(w.Handler())
+	t.Cleanup(server.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, err := websocket.Dial(wsURL, "", server.URL)
+	require.NoError(t, err)
+	conn.PayloadType = websocket.BinaryFrame
+
+	// the frame is split over two messages, frames are read as a stream.
+	_, err = conn.Write(frame[:4])
+	require.NoError(t, err)
+	_, err = conn.Write(frame[4:])
+	require.NoError(t, err)
+
+	waitFor(t, received)
+	require.NoError(t, conn.Close())
+	waitFor(t, disconnected)
+}
+
+func TestWebSocket_Handshake_Origin_Refused(t *testing.T) {
+	t.Parallel()
+
+	w, err := NewWebSocket(
+		WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))),
+		WithAllowedOrigins("https://shop.example.com"),
+	)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(w.Handler())
+	t.Cleanup(server.Close)
+
+	_, err = websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", "https://evil.example.com")
+	var dialErr *websocket.DialError
+	require.ErrorAs(t, err, &dialErr)
+	assert.ErrorIs(t, dialErr.Err, websocket.ErrBadStatus)
+}
+
+func TestWebSocket_HandleMessageSending(t *testing.T) {
+	t.Parallel()
+
+	w := &WebSocket{
+		logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+	}
+
+	wsConn := mocks.NewMockConnection(t)
+	wsConn.On("SetWriteDeadline", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	wsConn.On("Write", []byte("ws message")).Return(10, nil).Once()
+
+	tcpConn := mocks.NewMockConnection(t)
+
+	ch := make(chan domain.Event, 2)
+	ch <- &events.SendMessageEvent{
+		Conn:      tcpConn,
+		Message:   []byte("tcp message"),
+		Transport: domain.TransportTypeTCP,
+		BaseEvent: events.BaseEvent{EventType: domain.EventTypeSendMessage},
+	}
+	ch <- &events.SendMessageEvent{
+		Conn:      wsConn,
+		Message:   []byte("ws message"),
+		Transport: domain.TransportTypeWebSocket,
+		BaseEvent: events.BaseEvent{EventType: domain.EventTypeSendMessage},
+	}
+	close(ch)
+
+	w.handleMessageSending(context.Background(), ch)
+}
+
+func TestWebSocket_Name(t *testing.T) {
+	t.Parallel()
+
+	w := &WebSocket{}
+	assert.Equal(t, "websocket-handler", w.Name())
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+}
+
+func encodedFrame(payload string) []byte {
+	frame := make([]byte, 6, 6+len(payload))
+	binary.BigEndian.PutUint16(frame[0:2], uint16(len(payload)))
+	binary.BigEndian.PutUint16(frame[2:4], uint16(domain.DOFF4))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(domain.FrameTypeMessage))
+	return append(frame, payload...)
+}
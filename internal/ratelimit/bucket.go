@@ -0,0 +1,51 @@
+// Package ratelimit meter the messages published by clients with token buckets.
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// bucket is a token bucket refilled at rate tokens per second up to its capacity.
+// The tokens may go negative when a reservation is forced, the debt is paid back by the refill.
+type bucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(rate, capacity float64, now time.Time) *bucket {
+	return &bucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// allow report whether n tokens are available, a full bucket always allow a request larger than its capacity.
+func (b *bucket) allow(n float64, now time.Time) bool {
+	b.refill(now)
+
+	return b.tokens >= math.Min(n, b.capacity)
+}
+
+// take remove n tokens and return how long it takes to pay back the debt, if any.
+func (b *bucket) take(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,245 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// ErrInvalidMode is returned when the rate limit mode is unknown.
+var ErrInvalidMode = errors.New("invalid rate limit mode")
+
+// Mode select how the limits are enforced.
+type Mode string
+
+const (
+	// ModeThrottle accept the message and pause the reads of the client until the buckets are refilled.
+	ModeThrottle Mode = "throttle"
+	// ModeReject refuse the message with ErrRateLimited.
+	ModeReject Mode = "reject"
+)
+
+// Limits hold the rates of a pair of buckets, a zero rate disable the bucket.
+// The message bucket hold Burst messages, the byte bucket hold one second of traffic.
+type Limits struct {
+	MessagesPerSecond float64 `koanf:"max_messages_per_second"`
+	BytesPerSecond    float64 `koanf:"max_bytes_per_second"`
+	Burst             int     `koanf:"burst_limit"`
+}
+
+// IdentityLimits override the client limits of an authenticated identity.
+type IdentityLimits struct {
+	Identity string `koanf:"identity"`
+	Limits   `koanf:",squash"`
+}
+
+// TopicLimits limit the messages published on the topics matching a pattern, across every client.
+type TopicLimits struct {
+	Topic  string `koanf:"topic"`
+	Limits `koanf:",squash"`
+}
+
+type buckets struct {
+	messages *bucket
+	bytes    *bucket
+}
+
+func newBuckets(limits Limits, now time.Time) *buckets {
+	b := &buckets{}
+	if limits.MessagesPerSecond > 0 {
+		burst := float64(limits.Burst)
+		if burst < 1 {
+			burst = limits.MessagesPerSecond
+		}
+		b.messages = newBucket(limits.MessagesPerSecond, burst, now)
+	}
+	if limits.BytesPerSecond > 0 {
+		b.bytes = newBucket(limits.BytesPerSecond, limits.BytesPerSecond, now)
+	}
+
+	return b
+}
+
+func (b *buckets) allow(size float64, now time.Time) bool {
+	return (b.messages == nil || b.messages.allow(1, now)) &&
+		(b.bytes == nil || b.bytes.allow(size, now))
+}
+
+func (b *buckets) take(size float64, now time.Time) time.Duration {
+	var delay time.Duration
+	if b.messages != nil {
+		delay = max(delay, b.messages.take(1, now))
+	}
+	if b.bytes != nil {
+		delay = max(delay, b.bytes.take(size, now))
+	}
+
+	return delay
+}
+
+// Limiter enforce token bucket rate limits per client and per topic.
+type Limiter struct {
+	mu sync.Mutex
+
+	mode       Mode
+	defaults   Limits
+	identities map[string]Limits
+	topics     []TopicLimits
+	now        func() time.Time
+
+	clients map[domain.ID]*buckets
+	// topicBuckets hold the buckets by configured pattern, shared by every topic matching it.
+	topicBuckets map[string]*buckets
+}
+
+// Option configure the limiter.
+type Option func(*Limiter) error
+
+// WithMode set how the limits are enforced, throttle or reject.
+func WithMode(mode string) Option {
+	return func(l *Limiter) error {
+		switch Mode(mode) {
+		case ModeThrottle, ModeReject:
+			l.mode = Mode(mode)
+		case "":
+			l.mode = ModeThrottle
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidMode, mode)
+		}
+
+		return nil
+	}
+}
+
+// WithClientLimits set the limits applied to every client without an identity override.
+func WithClientLimits(limits Limits) Option {
+	return func(l *Limiter) error {
+		l.defaults = limits
+
+		return nil
+	}
+}
+
+// WithIdentityLimits override the client limits of authenticated identities.
+func WithIdentityLimits(overrides ...IdentityLimits) Option {
+	return func(l *Limiter) error {
+		for _, override := range overrides {
+			l.identities[override.Identity] = override.Limits
+		}
+
+		return nil
+	}
+}
+
+// WithTopicLimits limit the topics matching the patterns, the first matching pattern apply.
+func WithTopicLimits(limits ...TopicLimits) Option {
+	return func(l *Limiter) error {
+		l.topics = append(l.topics, limits...)
+
+		return nil
+	}
+}
+
+// WithClock set the clock used to refill the buckets.
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) error {
+		l.now = now
+
+		return nil
+	}
+}
+
+// NewLimiter create a new limiter, throttling by default.
+func NewLimiter(opts ...Option) (*Limiter, error) {
+	l := &Limiter{
+		mode:         ModeThrottle,
+		identities:   make(map[string]Limits),
+		now:          time.Now,
+		clients:      make(map[domain.ID]*buckets),
+		topicBuckets: make(map[string]*buckets),
+	}
+
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Reserve account a message of size bytes published by the client on the topic.
+// In throttle mode it return how long the client must pause, in reject mode ErrRateLimited
+// when a bucket is empty, in which case nothing is taken.
+func (l *Limiter) Reserve(clientID domain.ID, identity string, topic string, size int) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	limited := []*buckets{l.clientBuckets(clientID, identity, now)}
+	if topicBuckets := l.topicBucketsFor(topic, now); topicBuckets != nil {
+		limited = append(limited, topicBuckets)
+	}
+
+	if l.mode == ModeReject {
+		for _, b := range limited {
+			if !b.allow(float64(size), now) {
+				return 0, fmt.Errorf("%w: on %q", domain.ErrRateLimited, topic)
+			}
+		}
+	}
+
+	var delay time.Duration
+	for _, b := range limited {
+		delay = max(delay, b.take(float64(size), now))
+	}
+
+	if l.mode == ModeReject {
+		return 0, nil
+	}
+
+	return delay, nil
+}
+
+// Release forget the buckets of the client.
+func (l *Limiter) Release(clientID domain.ID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.clients, clientID)
+}
+
+func (l *Limiter) clientBuckets(clientID domain.ID, identity string, now time.Time) *buckets {
+	b, ok := l.clients[clientID]
+	if !ok {
+		limits, override := l.identities[identity]
+		if !override || identity == "" {
+			limits = l.defaults
+		}
+		b = newBuckets(limits, now)
+		l.clients[clientID] = b
+	}
+
+	return b
+}
+
+func (l *Limiter) topicBucketsFor(topic string, now time.Time) *buckets {
+	for _, limits := range l.topics {
+		if !domain.MatchTopic(limits.Topic, topic) {
+			continue
+		}
+
+		b, ok := l.topicBuckets[limits.Topic]
+		if !ok {
+			b = newBuckets(limits.Limits, now)
+			l.topicBuckets[limits.Topic] = b
+		}
+
+		return b
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	t.Parallel()
+
+	type reservation struct {
+		clientID  domain.ID
+		identity  string
+		topic     string
+		size      int
+		advance   time.Duration
+		wantDelay time.Duration
+		wantErr   error
+	}
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		reservations []reservation
+	}{
+		{
+			name: "Reject_Once_Burst_Exhausted",
+			opts: []Option{
+				WithMode("reject"),
+				WithClientLimits(Limits{MessagesPerSecond: 10, Burst: 2}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", topic: "orders"},
+				{clientID: "c1", topic: "orders"},
+				{clientID: "c1", topic: "orders", wantErr: domain.ErrRateLimited},
+				{clientID: "c2", topic: "orders"},
+				{clientID: "c1", topic: "orders", advance: 100 * time.Millisecond},
+			},
+		},
+		{
+			name: "Throttle_Return_Debt_Delay",
+			opts: []Option{
+				WithClientLimits(Limits{MessagesPerSecond: 10, Burst: 1}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", topic: "orders"},
+				{clientID: "c1", topic: "orders", wantDelay: 100 * time.Millisecond},
+				{clientID: "c1", topic: "orders", wantDelay: 200 * time.Millisecond},
+			},
+		},
+		{
+			name: "Reject_Bytes",
+			opts: []Option{
+				WithMode("reject"),
+				WithClientLimits(Limits{BytesPerSecond: 100}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", topic: "orders", size: 60},
+				{clientID: "c1", topic: "orders", size: 60, wantErr: domain.ErrRateLimited},
+				{clientID: "c1", topic: "orders", size: 40},
+			},
+		},
+		{
+			name: "Full_Bytes_Bucket_Allow_Large_Message",
+			opts: []Option{
+				WithMode("reject"),
+				WithClientLimits(Limits{BytesPerSecond: 100}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", topic: "orders", size: 500},
+				{clientID: "c1", topic: "orders", size: 1, advance: time.Second, wantErr: domain.ErrRateLimited},
+			},
+		},
+		{
+			name: "Identity_Override",
+			opts: []Option{
+				WithMode("reject"),
+				WithClientLimits(Limits{MessagesPerSecond: 1, Burst: 1}),
+				WithIdentityLimits(IdentityLimits{Identity: "ingest"}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", identity: "ingest", topic: "orders"},
+				{clientID: "c1", identity: "ingest", topic: "orders"},
+				{clientID: "c2", identity: "alice", topic: "orders"},
+				{clientID: "c2", identity: "alice", topic: "orders", wantErr: domain.ErrRateLimited},
+			},
+		},
+		{
+			name: "Topic_Limit_Shared_By_Clients_And_Matching_Topics",
+			opts: []Option{
+				WithMode("reject"),
+				WithTopicLimits(TopicLimits{Topic: "audit.#", Limits: Limits{MessagesPerSecond: 1, Burst: 1}}),
+			},
+			reservations: []reservation{
+				{clientID: "c1", topic: "audit.login"},
+				{clientID: "c2", topic: "audit.login", wantErr: domain.ErrRateLimited},
+				{clientID: "c2", topic: "audit.logout", wantErr: domain.ErrRateLimited},
+				{clientID: "c2", topic: "orders"},
+				{clientID: "c2", topic: "audit.logout", advance: time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			limiter, err := NewLimiter(append(tt.opts, WithClock(clock.Now))...)
+			require.NoError(t, err)
+
+			for i, r := range tt.reservations {
+				clock.now = clock.now.Add(r.advance)
+
+				delay, err := limiter.Reserve(r.clientID, r.identity, r.topic, r.size)
+				assert.ErrorIs(t, err, r.wantErr, "reservation %d", i)
+				assert.Equal(t, r.wantDelay, delay, "reservation %d", i)
+			}
+		})
+	}
+}
+
+func TestLimiter_Release(t *testing.T) {
+	t.Parallel()
+
+	limiter, err := NewLimiter(
+		WithMode("reject"),
+		WithClientLimits(Limits{MessagesPerSecond: 1, Burst: 1}),
+	)
+	require.NoError(t, err)
+
+	_, err = limiter.Reserve("c1", "", "orders", 0)
+	require.NoError(t, err)
+
+	_, err = limiter.Reserve("c1", "", "orders", 0)
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+
+	limiter.Release("c1")
+	_, err = limiter.Reserve("c1", "", "orders", 0)
+	assert.NoError(t, err)
+}
+
+func TestLimiter_TopicBuckets_By_Pattern(t *testing.T) {
+	t.Parallel()
+
+	limiter, err := NewLimiter(WithTopicLimits(
+		TopicLimits{Topic: "audit.#", Limits: Limits{MessagesPerSecond: 100}},
+		TopicLimits{Topic: "metrics.*", Limits: Limits{MessagesPerSecond: 100}},
+	))
+	require.NoError(t, err)
+
+	for _, topic := range []string{"audit.login", "audit.logout", "audit.a.b", "metrics.cpu", "metrics.mem", "orders"} {
+		_, err := limiter.Reserve("c1", "", topic, 0)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, limiter.topicBuckets, 2)
+	assert.Contains(t, limiter.topicBuckets, "audit.#")
+	assert.Contains(t, limiter.topicBuckets, "metrics.*")
+}
+
+func TestNewLimiter_InvalidMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLimiter(WithMode("drop"))
+	assert.ErrorIs(t, err, ErrInvalidMode)
+}
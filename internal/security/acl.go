@@ -0,0 +1,180 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// ErrInvalidPermissions is returned when a permission string contains an unknown right.
+var ErrInvalidPermissions = errors.New("invalid permissions")
+
+// permissionLetters map the letters of a permission string to their right, r (subscribe), w (publish) and m (manage).
+var permissionLetters = map[rune]domain.Permission{
+	'r': domain.PermissionSubscribe,
+	'w': domain.PermissionPublish,
+	'm': domain.PermissionManage,
+}
+
+// Rule grant permissions on the topics matching a pattern.
+type Rule struct {
+	Topic       string `koanf:"topic"`
+	Permissions string `koanf:"permissions"`
+}
+
+// Role is a named set of rules shared by several users.
+type Role struct {
+	Rules []Rule `koanf:"rules"`
+}
+
+// UserACL hold the roles and the rules of a user.
+type UserACL struct {
+	Roles []string `koanf:"roles"`
+	Rules []Rule   `koanf:"rules"`
+}
+
+type grant struct {
+	pattern     string
+	permissions domain.Permission
+}
+
+// ACL authorize operations on topics from per-user and per-role rules.
+// The permissions of every rule matching the topic are merged,
+// the default permissions apply when no rule match the topic.
+// A wildcard pattern is only granted what every rule it overlap grant.
+type ACL struct {
+	defaults domain.Permission
+	grants   map[string][]grant
+}
+
+// ParsePermissions parse a permission string made of the letters r, w and m, e.g. "rw".
+func ParsePermissions(permissions string) (domain.Permission, error) {
+	var p domain.Permission
+	for _, letter := range permissions {
+		right, ok := permissionLetters[letter]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidPermissions, permissions)
+		}
+		p |= right
+	}
+
+	return p, nil
+}
+
+// NewFileACL create a new ACL from the given rules file.
+//
+//	[roles.producers]
+//	rules = [{ topic = "orders.#", permissions = "w" }]
+//
+//	[users.alice]
+//	roles = ["producers"]
+//	rules = [{ topic = "alice.*", permissions = "rwm" }]
+func NewFileACL(path string, defaults string) (*ACL, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		return nil, fmt.Errorf("failed to load acl file: %w", err)
+	}
+
+	var rules struct {
+		Roles map[string]Role    `koanf:"roles"`
+		Users map[string]UserACL `koanf:"users"`
+	}
+	if err := k.Unmarshal("", &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse acl file: %w", err)
+	}
+
+	return NewACL(defaults, rules.Roles, rules.Users)
+}
+
+// NewACL create a new ACL from the given roles and users.
+func NewACL(defaults string, roles map[string]Role, users map[string]UserACL) (*ACL, error) {
+	defaultPermissions, err := ParsePermissions(defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	acl := &ACL{
+		defaults: defaultPermissions,
+		grants:   make(map[string][]grant, len(users)),
+	}
+
+	for identity, user := range users {
+		rules := user.Rules
+		for _, name := range user.Roles {
+			role, ok := roles[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown role %q for user %q", name, identity)
+			}
+			rules = append(rules, role.Rules...)
+		}
+
+		for _, rule := range rules {
+			permissions, err := ParsePermissions(rule.Permissions)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule on %q for user %q: %w", rule.Topic, identity, err)
+			}
+			acl.grants[identity] = append(acl.grants[identity], grant{
+				pattern:     rule.Topic,
+				permissions: permissions,
+			})
+		}
+	}
+
+	return acl, nil
+}
+
+// Permissions return the permissions held by the identity on the topic.
+// For a wildcard pattern, they are the permissions held on every topic the pattern can match.
+func (a *ACL) Permissions(identity string, topic string) domain.Permission {
+	if domain.HasWildcards(topic) {
+		return a.patternPermissions(identity, topic)
+	}
+
+	var permissions domain.Permission
+	matched := false
+	for _, g := range a.grants[identity] {
+		if domain.MatchTopic(g.pattern, topic) {
+			permissions |= g.permissions
+			matched = true
+		}
+	}
+
+	if !matched {
+		return a.defaults
+	}
+
+	return permissions
+}
+
+// patternPermissions keep the permissions shared by every rule overlapping the pattern,
+// along with the defaults unless a single rule cover the whole pattern.
+func (a *ACL) patternPermissions(identity string, pattern string) domain.Permission {
+	permissions := domain.PermissionSubscribe | domain.PermissionPublish | domain.PermissionManage
+	covered := false
+	for _, g := range a.grants[identity] {
+		if domain.OverlapTopics(g.pattern, pattern) {
+			permissions &= g.permissions
+			covered = covered || domain.CoverTopic(g.pattern, pattern)
+		}
+	}
+
+	if !covered {
+		permissions &= a.defaults
+	}
+
+	return permissions
+}
+
+// Authorize return domain.ErrForbidden when the identity does not hold the permission on the topic.
+func (a *ACL) Authorize(identity string, topic string, permission domain.Permission) error {
+	if a.Permissions(identity, topic).Has(permission) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s on %q", domain.ErrForbidden, permission, topic)
+}
@@ -0,0 +1,130 @@
+package security
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func TestACL_Authorize(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "acl.toml")
+	writeFile(t, path, []byte(`
+[roles.producers]
+rules = [{ topic = "orders.#", permissions = "w" }]
+
+[users.alice]
+roles = ["producers"]
+rules = [{ topic = "alice.*", permissions = "rwm" }]
+
+[users.bob]
+rules = [{ topic = "orders.#", permissions = "r" }]
+`))
+
+	acl, err := NewFileACL(path, "r")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		identity   string
+		topic      string
+		permission domain.Permission
+		wantErr    error
+	}{
+		{
+			name:       "Role_Grant_Publish",
+			identity:   "alice",
+			topic:      "orders.eu",
+			permission: domain.PermissionPublish,
+		},
+		{
+			name:       "Matching_Rule_Exclude_Defaults",
+			identity:   "alice",
+			topic:      "orders.eu",
+			permission: domain.PermissionSubscribe,
+			wantErr:    domain.ErrForbidden,
+		},
+		{
+			name:       "User_Rule_Grant_Manage",
+			identity:   "alice",
+			topic:      "alice.inbox",
+			permission: domain.PermissionManage,
+		},
+		{
+			name:       "Read_Only_User",
+			identity:   "bob",
+			topic:      "orders.eu",
+			permission: domain.PermissionPublish,
+			wantErr:    domain.ErrForbidden,
+		},
+		{
+			name:       "Defaults_Without_Matching_Rule",
+			identity:   "bob",
+			topic:      "invoices",
+			permission: domain.PermissionSubscribe,
+		},
+		{
+			name:       "Wildcard_Overlapping_Restricted_Subtree",
+			identity:   "alice",
+			topic:      "#",
+			permission: domain.PermissionSubscribe,
+			wantErr:    domain.ErrForbidden,
+		},
+		{
+			name:       "Wildcard_Matching_Restricted_Subtree",
+			identity:   "alice",
+			topic:      "*.eu",
+			permission: domain.PermissionSubscribe,
+			wantErr:    domain.ErrForbidden,
+		},
+		{
+			name:       "Wildcard_Covered_By_Rule",
+			identity:   "alice",
+			topic:      "alice.*",
+			permission: domain.PermissionSubscribe,
+		},
+		{
+			name:       "Wildcard_Outside_Rules_Get_Defaults",
+			identity:   "alice",
+			topic:      "invoices.#",
+			permission: domain.PermissionSubscribe,
+		},
+		{
+			name:       "Wildcard_Within_Read_Rule",
+			identity:   "bob",
+			topic:      "orders.*",
+			permission: domain.PermissionSubscribe,
+		},
+		{
+			name:       "Anonymous_Denied_Publish",
+			identity:   "",
+			topic:      "invoices",
+			permission: domain.PermissionPublish,
+			wantErr:    domain.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := acl.Authorize(tt.identity, tt.topic, tt.permission)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewACL_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewACL("rx", nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidPermissions)
+
+	_, err = NewACL("r", nil, map[string]UserACL{"alice": {Roles: []string{"missing"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,19 @@
+package security
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// OpenAuditLog open the audit log file in append mode and return a JSON logger writing to it.
+// The returned closer must be called once the logger is no longer used.
+func OpenAuditLog(path string) (*slog.Logger, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return slog.New(slog.NewJSONHandler(f, nil)), f, nil
+}
@@ -0,0 +1,147 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// ErrInvalidPlainResponse is returned when a PLAIN response is not made of three NUL separated fields.
+var ErrInvalidPlainResponse = errors.New("invalid PLAIN response")
+
+// dummyHash is compared against when the username is unknown so lookups take the same time for every username.
+var dummyHash = []byte("$2a$10$Cc3XXX50hc5vMQACQAK4U.VClCiGtu8TU/qGyk2as/WjJt6Py0KUu")
+
+// User represent an entry of the user store.
+type User struct {
+	Username string `koanf:"username"`
+	// PasswordHash is the bcrypt hash of the password.
+	PasswordHash string `koanf:"password_hash"`
+	// Tokens are the hex encoded SHA-256 hashes of the tokens authenticating the user.
+	Tokens []string `koanf:"tokens"`
+}
+
+// ParsePlainResponse split a PLAIN response (RFC 4616) in its authorization identity, username and password.
+func ParsePlainResponse(response []byte) (authzid, username, password string, err error) {
+	fields := bytes.Split(response, []byte{0})
+	if len(fields) != 3 || len(fields[1]) == 0 {
+		return "", "", "", ErrInvalidPlainResponse
+	}
+
+	return string(fields[0]), string(fields[1]), string(fields[2]), nil
+}
+
+// HashToken return the hex encoded SHA-256 hash of a token as stored in the user store.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileAuthenticator authenticate clients against a TOML user store.
+//
+//	[[users]]
+//	username = "alice"
+//	password_hash = "$2a$10$..." # bcrypt
+//	tokens = ["9f86d081..."]     # sha256 hex
+type FileAuthenticator struct {
+	users map[string]User
+}
+
+// NewFileAuthenticator create a new authenticator from the given user store file.
+func NewFileAuthenticator(path string) (*FileAuthenticator, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		return nil, fmt.Errorf("failed to load user store: %w", err)
+	}
+
+	var store struct {
+		Users []User `koanf:"users"`
+	}
+	if err := k.Unmarshal("", &store); err != nil {
+		return nil, fmt.Errorf("failed to parse user store: %w", err)
+	}
+
+	return NewAuthenticator(store.Users...), nil
+}
+
+// NewAuthenticator create a new authenticator from the given users.
+func NewAuthenticator(users ...User) *FileAuthenticator {
+	a := &FileAuthenticator{
+		users: make(map[string]User, len(users)),
+	}
+	for _, user := range users {
+		a.users[user.Username] = user
+	}
+
+	return a
+}
+
+// Mechanisms return the mechanisms supported by the authenticator.
+func (a *FileAuthenticator) Mechanisms() []domain.AuthMechanism {
+	return []domain.AuthMechanism{domain.AuthMechanismPlain, domain.AuthMechanismToken}
+}
+
+// Authenticate return the username matching the credentials.
+func (a *FileAuthenticator) Authenticate(_ context.Context, mechanism domain.AuthMechanism, response []byte) (string, error) {
+	switch mechanism {
+	case domain.AuthMechanismPlain:
+		return a.authenticatePlain(response)
+	case domain.AuthMechanismToken:
+		return a.authenticateToken(response)
+	default:
+		return "", fmt.Errorf("%w: unsupported mechanism %q", domain.ErrUnauthorized, mechanism)
+	}
+}
+
+func (a *FileAuthenticator) authenticatePlain(response []byte) (string, error) {
+	authzid, username, password, err := ParsePlainResponse(response)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+
+	if authzid != "" && authzid != username {
+		return "", fmt.Errorf("%w: %s can not act as %s", domain.ErrUnauthorized, username, authzid)
+	}
+
+	hash := dummyHash
+	user, ok := a.users[username]
+	if ok {
+		hash = []byte(user.PasswordHash)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return "", fmt.Errorf("%w: invalid credentials", domain.ErrUnauthorized)
+	}
+
+	return username, nil
+}
+
+func (a *FileAuthenticator) authenticateToken(response []byte) (string, error) {
+	hash := []byte(HashToken(string(response)))
+
+	identity := ""
+	for _, user := range a.users {
+		for _, token := range user.Tokens {
+			if subtle.ConstantTimeCompare(hash, []byte(token)) == 1 {
+				identity = user.Username
+			}
+		}
+	}
+
+	if len(response) == 0 || identity == "" {
+		return "", fmt.Errorf("%w: invalid token", domain.ErrUnauthorized)
+	}
+
+	return identity, nil
+}
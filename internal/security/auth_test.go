@@ -0,0 +1,137 @@
+package security
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func plainResponse(authzid, username, password string) []byte {
+	return []byte(authzid + "\x00" + username + "\x00" + password)
+}
+
+func TestParsePlainResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		response     []byte
+		wantUsername string
+		wantPassword string
+		wantErr      error
+	}{
+		{
+			name:         "Valid_Response",
+			response:     plainResponse("", "alice", "secret"),
+			wantUsername: "alice",
+			wantPassword: "secret",
+		},
+		{
+			name:     "Missing_Separator",
+			response: []byte("alice\x00secret"),
+			wantErr:  ErrInvalidPlainResponse,
+		},
+		{
+			name:     "Empty_Username",
+			response: plainResponse("", "", "secret"),
+			wantErr:  ErrInvalidPlainResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, username, password, err := ParsePlainResponse(tt.response)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantUsername, username)
+			assert.Equal(t, tt.wantPassword, password)
+		})
+	}
+}
+
+func TestFileAuthenticator_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.toml")
+	writeFile(t, path, []byte(`
+[[users]]
+username = "alice"
+password_hash = "`+string(hash)+`"
+tokens = ["`+HashToken("alice-token")+`"]
+`))
+
+	authenticator, err := NewFileAuthenticator(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		mechanism    domain.AuthMechanism
+		response     []byte
+		wantIdentity string
+		wantErr      error
+	}{
+		{
+			name:         "Plain_Valid_Credentials",
+			mechanism:    domain.AuthMechanismPlain,
+			response:     plainResponse("", "alice", "secret"),
+			wantIdentity: "alice",
+		},
+		{
+			name:      "Plain_Wrong_Password",
+			mechanism: domain.AuthMechanismPlain,
+			response:  plainResponse("", "alice", "wrong"),
+			wantErr:   domain.ErrUnauthorized,
+		},
+		{
+			name:      "Plain_Unknown_User",
+			mechanism: domain.AuthMechanismPlain,
+			response:  plainResponse("", "bob", "secret"),
+			wantErr:   domain.ErrUnauthorized,
+		},
+		{
+			name:      "Plain_Impersonation",
+			mechanism: domain.AuthMechanismPlain,
+			response:  plainResponse("bob", "alice", "secret"),
+			wantErr:   domain.ErrUnauthorized,
+		},
+		{
+			name:         "Token_Valid",
+			mechanism:    domain.AuthMechanismToken,
+			response:     []byte("alice-token"),
+			wantIdentity: "alice",
+		},
+		{
+			name:      "Token_Invalid",
+			mechanism: domain.AuthMechanismToken,
+			response:  []byte("bob-token"),
+			wantErr:   domain.ErrUnauthorized,
+		},
+		{
+			name:      "Unsupported_Mechanism",
+			mechanism: "SCRAM-SHA-256",
+			response:  []byte("alice"),
+			wantErr:   domain.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			identity, err := authenticator.Authenticate(context.Background(), tt.mechanism, tt.response)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantIdentity, identity)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrInvalidCIDR is returned when an entry of an ip list is neither an ip nor a CIDR block.
+var ErrInvalidCIDR = errors.New("invalid ip or CIDR")
+
+// IPFilter decide whether a remote ip may connect from allow and deny lists.
+// The deny list always wins, an empty allow list accept every ip not denied.
+type IPFilter struct {
+	allowed []*net.IPNet
+	blocked []*net.IPNet
+}
+
+// NewIPFilter create a new ip filter from the given lists of ips or CIDR blocks, e.g. "10.0.0.0/8" or "::1".
+func NewIPFilter(allowed, blocked []string) (*IPFilter, error) {
+	allowedNets, err := parseNetworks(allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedNets, err := parseNetworks(blocked)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IPFilter{
+		allowed: allowedNets,
+		blocked: blockedNets,
+	}, nil
+}
+
+// Allowed report whether the ip may connect.
+func (f *IPFilter) Allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	if containsIP(f.blocked, ip) {
+		return false
+	}
+
+	return len(f.allowed) == 0 || containsIP(f.allowed, ip)
+}
+
+func parseNetworks(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, entry)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, entry)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
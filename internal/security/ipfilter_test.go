@@ -0,0 +1,79 @@
+package security
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilter_Allowed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		blocked []string
+		ip      string
+		want    bool
+	}{
+		{
+			name: "Empty_Lists_Allow_All",
+			ip:   "203.0.113.7",
+			want: true,
+		},
+		{
+			name:    "Blocked_CIDR",
+			blocked: []string{"203.0.113.0/24"},
+			ip:      "203.0.113.7",
+			want:    false,
+		},
+		{
+			name:    "Allowed_Plain_IP",
+			allowed: []string{"127.0.0.1", "::1"},
+			ip:      "::1",
+			want:    true,
+		},
+		{
+			name:    "Not_In_Allow_List",
+			allowed: []string{"10.0.0.0/8"},
+			ip:      "192.168.1.1",
+			want:    false,
+		},
+		{
+			name:    "Blocked_Wins_Over_Allowed",
+			allowed: []string{"10.0.0.0/8"},
+			blocked: []string{"10.1.2.3"},
+			ip:      "10.1.2.3",
+			want:    false,
+		},
+		{
+			name:    "IPv4_Mapped_IPv6",
+			allowed: []string{"127.0.0.1"},
+			ip:      "::ffff:127.0.0.1",
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := NewIPFilter(tt.allowed, tt.blocked)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, filter.Allowed(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestNewIPFilter_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewIPFilter([]string{"10.0.0.0/33"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidCIDR)
+
+	_, err = NewIPFilter(nil, []string{"localhost"})
+	assert.ErrorIs(t, err, ErrInvalidCIDR)
+}
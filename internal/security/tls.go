@@ -0,0 +1,286 @@
+// Package security provides the security primitives of the broker listeners.
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is the default minimum interval between two checks of the certificate files.
+const DefaultReloadInterval = 30 * time.Second
+
+var (
+	// ErrInvalidTLSVersion is returned when the configured minimum TLS version is unknown.
+	ErrInvalidTLSVersion = errors.New("invalid tls version")
+
+	// ErrInvalidClientAuth is returned when the configured client authentication mode is unknown.
+	ErrInvalidClientAuth = errors.New("invalid client auth mode")
+
+	// ErrMissingCertificate is returned when the certificate or the key file is not configured.
+	ErrMissingCertificate = errors.New("missing tls certificate or key file")
+
+	// ErrInvalidCA is returned when the CA file does not contain any PEM certificate.
+	ErrInvalidCA = errors.New("no certificate found in ca file")
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthModes = map[string]tls.ClientAuthType{
+	"":         tls.NoClientCert,
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"required": tls.RequireAndVerifyClientCert,
+}
+
+// ParseTLSVersion return the tls version matching the given version, TLS 1.2 when empty.
+func ParseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTLSVersion, version)
+	}
+
+	return v, nil
+}
+
+// ParseClientAuth return the client authentication type matching the given mode (none, optional, required).
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	auth, ok := clientAuthModes[mode]
+	if !ok {
+		return tls.NoClientCert, fmt.Errorf("%w: %q", ErrInvalidClientAuth, mode)
+	}
+
+	return auth, nil
+}
+
+// PeerIdentity return the subject of the verified client certificate, empty when the client did not present one.
+func PeerIdentity(state tls.ConnectionState) string {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	return state.VerifiedChains[0][0].Subject.String()
+}
+
+// CertReloader serve the listener certificate and client CAs, reloading them when the files change on disk.
+// A failed reload keep serving the previously loaded material.
+type CertReloader struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+	interval   time.Duration
+	logger     *slog.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// ReloaderOption type is a function that modify the cert reloader.
+type ReloaderOption func(*CertReloader) error
+
+// WithCertificate set the certificate and private key files.
+func WithCertificate(certFile, keyFile string) ReloaderOption {
+	return func(r *CertReloader) error {
+		r.certFile = certFile
+		r.keyFile = keyFile
+
+		return nil
+	}
+}
+
+// WithClientCA set the CA file used to verify client certificates and the client authentication mode.
+func WithClientCA(caFile string, mode string) ReloaderOption {
+	return func(r *CertReloader) error {
+		auth, err := ParseClientAuth(mode)
+		if err != nil {
+			return err
+		}
+		r.caFile = caFile
+		r.clientAuth = auth
+
+		return nil
+	}
+}
+
+// WithMinVersion set the minimum TLS version accepted by the listener.
+func WithMinVersion(version string) ReloaderOption {
+	return func(r *CertReloader) error {
+		v, err := ParseTLSVersion(version)
+		if err != nil {
+			return err
+		}
+		r.minVersion = v
+
+		return nil
+	}
+}
+
+// WithReloadInterval set the minimum interval between two checks of the files.
+func WithReloadInterval(interval time.Duration) ReloaderOption {
+	return func(r *CertReloader) error {
+		if interval > 0 {
+			r.interval = interval
+		}
+
+		return nil
+	}
+}
+
+// WithLogger set the logger of the reloader.
+func WithLogger(logger *slog.Logger) ReloaderOption {
+	return func(r *CertReloader) error {
+		r.logger = logger
+
+		return nil
+	}
+}
+
+// NewCertReloader create a new cert reloader and load the certificate files.
+func NewCertReloader(opts ...ReloaderOption) (*CertReloader, error) {
+	r := &CertReloader{
+		minVersion: tls.VersionTLS12,
+		interval:   DefaultReloadInterval,
+		logger:     slog.Default(),
+		modTimes:   make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.certFile == "" || r.keyFile == "" {
+		return nil, ErrMissingCertificate
+	}
+
+	if r.clientAuth != tls.NoClientCert && r.caFile == "" {
+		return nil, fmt.Errorf("%w: client auth require a ca file", ErrInvalidClientAuth)
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload load the certificate, key and CA files from disk.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read ca file: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return ErrInvalidCA
+		}
+	}
+
+	modTimes := r.statFiles()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+
+	return nil
+}
+
+// TLSConfig return the tls configuration of the listener.
+// The certificate and client CAs are resolved on each handshake so reloaded files apply to new connections.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.reloadIfChanged()
+
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   r.minVersion,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCAs,
+				ClientAuth:   r.clientAuth,
+			}, nil
+		},
+	}
+}
+
+func (r *CertReloader) reloadIfChanged() {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	modTimes := r.statFiles()
+
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	changed := false
+	for file, modTime := range modTimes {
+		if !r.modTimes[file].Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err := r.Reload(); err != nil {
+		r.logger.Warn("failed to reload tls certificates, keeping previous ones", "error", err)
+		return
+	}
+
+	r.logger.Info("tls certificates reloaded", "cert_file", r.certFile)
+}
+
+func (r *CertReloader) statFiles() map[string]time.Time {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+
+	return modTimes
+}
@@ -0,0 +1,284 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, commonName string, parent *testCert, serial int64) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"hopper"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version string
+		want    uint16
+		wantErr error
+	}{
+		{name: "Default_To_TLS12", version: "", want: tls.VersionTLS12},
+		{name: "TLS13", version: "1.3", want: tls.VersionTLS13},
+		{name: "Unknown_Version", version: "2.0", wantErr: ErrInvalidTLSVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseTLSVersion(tt.version)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewCertReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "hopper-ca", nil, 1)
+	server := newTestCert(t, "localhost", ca, 2)
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.certPEM)
+	writeFile(t, filepath.Join(dir, "cert.pem"), server.certPEM)
+	writeFile(t, filepath.Join(dir, "key.pem"), server.keyPEM)
+
+	tests := []struct {
+		name    string
+		opts    []ReloaderOption
+		wantErr error
+	}{
+		{
+			name: "Valid_Certificate",
+			opts: []ReloaderOption{
+				WithCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")),
+			},
+		},
+		{
+			name:    "Missing_Certificate",
+			opts:    []ReloaderOption{},
+			wantErr: ErrMissingCertificate,
+		},
+		{
+			name: "Client_Auth_Without_CA",
+			opts: []ReloaderOption{
+				WithCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")),
+				WithClientCA("", "required"),
+			},
+			wantErr: ErrInvalidClientAuth,
+		},
+		{
+			name: "Invalid_CA",
+			opts: []ReloaderOption{
+				WithCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")),
+				WithClientCA(filepath.Join(dir, "key.pem"), "required"),
+			},
+			wantErr: ErrInvalidCA,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewCertReloader(tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+		})
+	}
+}
+
+// connPair return two connected loopback connections, unlike net.Pipe writes are buffered by the kernel.
+func connPair(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := l.Accept()
+		accepted <- conn
+	}()
+
+	clientConn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	serverConn := <-accepted
+	require.NotNil(t, serverConn)
+
+	return serverConn, clientConn
+}
+
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) (tls.ConnectionState, error) {
+	t.Helper()
+
+	serverConn, clientConn := connPair(t)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	clientErr := make(chan error, 1)
+	go func() {
+		c := tls.Client(clientConn, clientConfig)
+		clientErr <- c.Handshake()
+	}()
+
+	s := tls.Server(serverConn, serverConfig)
+	err := s.Handshake()
+	if err != nil {
+		clientConn.Close()
+	}
+	<-clientErr
+
+	return s.ConnectionState(), err
+}
+
+func TestCertReloader_MutualTLS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "hopper-ca", nil, 1)
+	server := newTestCert(t, "localhost", ca, 2)
+	client := newTestCert(t, "publisher-1", ca, 3)
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.certPEM)
+	writeFile(t, filepath.Join(dir, "cert.pem"), server.certPEM)
+	writeFile(t, filepath.Join(dir, "key.pem"), server.keyPEM)
+
+	reloader, err := NewCertReloader(
+		WithCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")),
+		WithClientCA(filepath.Join(dir, "ca.pem"), "required"),
+	)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert, err := tls.X509KeyPair(client.certPEM, client.keyPEM)
+	require.NoError(t, err)
+
+	state, err := handshake(t, reloader.TLSConfig(), &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{clientCert},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CN=publisher-1,O=hopper", PeerIdentity(state))
+
+	_, err = handshake(t, reloader.TLSConfig(), &tls.Config{
+		RootCAs:    roots,
+		ServerName: "localhost",
+	})
+	assert.Error(t, err, "handshake without client certificate should fail")
+}
+
+func TestCertReloader_ReloadOnChange(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "hopper-ca", nil, 1)
+	first := newTestCert(t, "localhost", ca, 2)
+	second := newTestCert(t, "localhost", ca, 3)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeFile(t, certFile, first.certPEM)
+	writeFile(t, keyFile, first.keyPEM)
+
+	reloader, err := NewCertReloader(
+		WithCertificate(certFile, keyFile),
+		WithReloadInterval(time.Nanosecond),
+	)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+
+	serial := func() int64 {
+		serverConn, clientConn := connPair(t)
+		defer serverConn.Close()
+		defer clientConn.Close()
+
+		go func() { _ = tls.Server(serverConn, reloader.TLSConfig()).Handshake() }()
+
+		c := tls.Client(clientConn, clientConfig)
+		require.NoError(t, c.Handshake())
+		return c.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(2), serial())
+
+	writeFile(t, certFile, second.certPEM)
+	writeFile(t, keyFile, second.keyPEM)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	assert.Equal(t, int64(3), serial())
+}
@@ -2,15 +2,18 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"log/slog"
 	"net"
 	"os"
+	"strconv"
 
 	"github.com/gin-gonic/gin" // should not exist here
 	"github.com/hoppermq/hopper/internal/ui"
 
 	"github.com/hoppermq/hopper/internal/events"
 	httpService "github.com/hoppermq/hopper/internal/http"
+	"github.com/hoppermq/hopper/internal/http/routes"
 
 	"github.com/zixyos/glog"
 
@@ -18,7 +21,16 @@ import (
 	"github.com/hoppermq/hopper/internal/config"
 	"github.com/hoppermq/hopper/internal/mq"
 	"github.com/hoppermq/hopper/internal/mq/core"
+	"github.com/hoppermq/hopper/internal/mq/core/retention"
+	"github.com/hoppermq/hopper/internal/mq/core/subscription"
+	"github.com/hoppermq/hopper/internal/mq/transport"
+	"github.com/hoppermq/hopper/internal/mq/transport/amqp"
+	"github.com/hoppermq/hopper/internal/mq/transport/mqtt"
 	handler "github.com/hoppermq/hopper/internal/mq/transport/tcp"
+	"github.com/hoppermq/hopper/internal/mq/transport/ws"
+	"github.com/hoppermq/hopper/internal/ratelimit"
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/domain"
 )
 
 const (
@@ -50,38 +62,268 @@ func main() {
 	logger.Info("welcome to " + appName)
 
 	eventBus := events.NewEventBus(maxBufferSize)
-	// TODO : HOP-000 should use app config directly
 	conf := &net.ListenConfig{}
-	tcpTransport, err := handler.NewTCP(
-		ctx,
+	tcpOpts := []handler.Option{
 		handler.WithListener(conf),
 		handler.WithLogger(logger),
-	)
+	}
+
+	if cfg != nil {
+		tcpCfg := cfg.Transport.TCP
+		endpoints := []handler.Endpoint{{
+			Network: handler.NetworkTCP,
+			Address: net.JoinHostPort(cfg.Server.Host, strconv.Itoa(int(cfg.Server.Port))),
+			TLS:     cfg.Security.TLSEnabled,
+		}}
+		if len(tcpCfg.Listeners) > 0 {
+			endpoints = endpoints[:0]
+			for _, l := range tcpCfg.Listeners {
+				endpoints = append(endpoints, handler.Endpoint{
+					Network: l.Network,
+					Address: l.Address,
+					TLS:     l.TLS,
+				})
+			}
+		}
+
+		tcpOpts = append(tcpOpts,
+			handler.WithEndpoints(endpoints...),
+			handler.WithSocketOptions(handler.SocketOptions{
+				KeepAlive:       tcpCfg.KeepAlive,
+				KeepAlivePeriod: tcpCfg.KeepAlivePeriod,
+				NoDelay:         tcpCfg.NoDelay,
+				Linger:          tcpCfg.LingerTimeout,
+				ReadBufferSize:  tcpCfg.ReadBufferSize,
+				WriteBufferSize: tcpCfg.WriteBufferSize,
+			}),
+		)
+		if tcpCfg.MaxMessageSize > 0 {
+			tcpOpts = append(tcpOpts, handler.WithMaxFrameSize(tcpCfg.MaxMessageSize))
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if cfg != nil && cfg.Security.TLSEnabled {
+		reloader, err := security.NewCertReloader(
+			security.WithCertificate(cfg.Security.CertFile, cfg.Security.KeyFile),
+			security.WithClientCA(cfg.Security.CAFile, cfg.Security.ClientAuth),
+			security.WithMinVersion(cfg.Security.MinTLSVersion),
+			security.WithReloadInterval(cfg.Security.CertReloadInterval),
+			security.WithLogger(logger),
+		)
+		if err != nil {
+			logger.Error("failed to load tls configuration", "error", err)
+			os.Exit(1)
+		}
+		tlsConfig = reloader.TLSConfig()
+		tcpOpts = append(tcpOpts, handler.WithTLS(tlsConfig))
+	}
+
+	// the limiter is shared by the transports so the connection caps apply to the whole broker.
+	var limiter *transport.Limiter
+	if cfg != nil {
+		var allowed []string
+		if cfg.Security.EnableIPWhitelist {
+			allowed = cfg.Security.AllowedIPs
+		}
+		ipFilter, err := security.NewIPFilter(allowed, cfg.Security.BlockedIPs)
+		if err != nil {
+			logger.Error("failed to load ip filter", "error", err)
+			os.Exit(1)
+		}
+
+		limiter, err = transport.NewLimiter(cfg.Server.MaxConnections, cfg.Server.MaxConnectionsPerIP, ipFilter)
+		if err != nil {
+			logger.Error("failed to create connection limiter", "error", err)
+			os.Exit(1)
+		}
+		tcpOpts = append(tcpOpts, handler.WithLimiter(limiter))
+	}
+
+	tcpTransport, err := handler.NewTCP(ctx, tcpOpts...)
 	if err != nil {
 		logger.Error("failed to create transport", "error", err)
 		os.Exit(1)
 	}
 
 	// TODO: HOP-000 should use composite pattern func.
+	transports := []domain.Transport{tcpTransport}
+
+	var wsTransport *ws.WebSocket
+	if cfg != nil && cfg.Transport.WebSocket.Enabled {
+		wsCfg := cfg.Transport.WebSocket
+		wsOpts := []ws.Option{
+			ws.WithLogger(logger),
+			ws.WithPath(wsCfg.Path),
+			ws.WithAllowedOrigins(wsCfg.AllowedOrigins...),
+			ws.WithLimiter(limiter),
+		}
+		if wsCfg.Address != "" {
+			wsOpts = append(wsOpts, ws.WithAddress(wsCfg.Address), ws.WithTLS(tlsConfig))
+		}
+		if cfg.Transport.TCP.MaxMessageSize > 0 {
+			wsOpts = append(wsOpts, ws.WithMaxFrameSize(cfg.Transport.TCP.MaxMessageSize))
+		}
+
+		wsTransport, err = ws.NewWebSocket(wsOpts...)
+		if err != nil {
+			logger.Error("failed to create websocket transport", "error", err)
+			os.Exit(1)
+		}
+		transports = append(transports, wsTransport)
+	}
+
+	if cfg != nil && cfg.Transport.MQTT.Enabled {
+		mqttCfg := cfg.Transport.MQTT
+		mqttOpts := []mqtt.Option{
+			mqtt.WithLogger(logger),
+			mqtt.WithAddress(mqttCfg.Address),
+			mqtt.WithConnectTimeout(mqttCfg.ConnectTimeout),
+			mqtt.WithLimiter(limiter),
+		}
+		if mqttCfg.TLS {
+			// without the [security] certificates the listener would silently serve plaintext.
+			if tlsConfig == nil {
+				logger.Error("mqtt tls requires security.tls_enabled", "error", mqtt.ErrMissingTLSConfig)
+				os.Exit(1)
+			}
+			mqttOpts = append(mqttOpts, mqtt.WithTLS(tlsConfig))
+		}
+		if mqttCfg.MaxPacketSize > 0 {
+			mqttOpts = append(mqttOpts, mqtt.WithMaxPacketSize(mqttCfg.MaxPacketSize))
+		}
+
+		mqttTransport, err := mqtt.NewMQTT(mqttOpts...)
+		if err != nil {
+			logger.Error("failed to create mqtt transport", "error", err)
+			os.Exit(1)
+		}
+		transports = append(transports, mqttTransport)
+	}
+
+	if cfg != nil && cfg.Transport.AMQP.Enabled {
+		amqpCfg := cfg.Transport.AMQP
+		amqpOpts := []amqp.Option{
+			amqp.WithLogger(logger),
+			amqp.WithAddress(amqpCfg.Address),
+			amqp.WithIdleTimeout(amqpCfg.IdleTimeout),
+			amqp.WithConnectTimeout(amqpCfg.ConnectTimeout),
+			amqp.WithLimiter(limiter),
+		}
+		if amqpCfg.TLS {
+			// without the [security] certificates the listener would silently serve plaintext.
+			if tlsConfig == nil {
+				logger.Error("amqp tls requires security.tls_enabled", "error", amqp.ErrMissingTLSConfig)
+				os.Exit(1)
+			}
+			amqpOpts = append(amqpOpts, amqp.WithTLS(tlsConfig))
+		}
+		if amqpCfg.MaxFrameSize > 0 {
+			amqpOpts = append(amqpOpts, amqp.WithMaxFrameSize(amqpCfg.MaxFrameSize))
+		}
+		if amqpCfg.MaxMessageSize > 0 {
+			amqpOpts = append(amqpOpts, amqp.WithMaxMessageSize(amqpCfg.MaxMessageSize))
+		}
+
+		amqpTransport, err := amqp.NewAMQP(amqpOpts...)
+		if err != nil {
+			logger.Error("failed to create amqp transport", "error", err)
+			os.Exit(1)
+		}
+		transports = append(transports, amqpTransport)
+	}
+
 	broker := core.NewBroker(
 		logger,
 		eventBus,
-		tcpTransport,
+		transports...,
 	)
 
+	var authenticator domain.Authenticator
+	if cfg != nil && cfg.Clients.EnableAuth {
+		fileAuthenticator, err := security.NewFileAuthenticator(cfg.Clients.UsersFile)
+		if err != nil {
+			logger.Error("failed to load user store", "error", err)
+			os.Exit(1)
+		}
+		authenticator = fileAuthenticator
+		broker.RegisterAuthenticator(authenticator, cfg.Clients.MaxAuthAttempts)
+	}
+
+	if cfg != nil && cfg.Security.AuditLogFile != "" {
+		audit, closer, err := security.OpenAuditLog(cfg.Security.AuditLogFile)
+		if err != nil {
+			logger.Error("failed to open audit log", "error", err)
+			os.Exit(1)
+		}
+		defer closer.Close()
+		broker.RegisterAuditLog(audit)
+	}
+
+	var authorizer domain.Authorizer
+	if cfg != nil && cfg.Security.EnableACL {
+		acl, err := security.NewFileACL(cfg.Security.ACLFile, cfg.Security.DefaultPermissions)
+		if err != nil {
+			logger.Error("failed to load acl", "error", err)
+			os.Exit(1)
+		}
+		authorizer = acl
+		broker.RegisterAuthorizer(authorizer)
+	}
+
+	if cfg != nil {
+		rateLimiter, err := ratelimit.NewLimiter(
+			ratelimit.WithMode(cfg.Clients.RateLimitMode),
+			ratelimit.WithClientLimits(ratelimit.Limits{
+				MessagesPerSecond: cfg.Clients.MaxMessagesPerSecond,
+				BytesPerSecond:    cfg.Clients.MaxBytesPerSecond,
+				Burst:             cfg.Clients.BurstLimit,
+			}),
+			ratelimit.WithIdentityLimits(cfg.Clients.RateLimitOverrides...),
+			ratelimit.WithTopicLimits(cfg.Clients.TopicRateLimits...),
+		)
+		if err != nil {
+			logger.Error("failed to create rate limiter", "error", err)
+			os.Exit(1)
+		}
+		broker.RegisterRateLimiter(rateLimiter)
+
+		broker.RegisterSubscriptionManager(subscription.NewManager(
+			subscription.WithAckTimeout(cfg.Gateway.AckTimeout),
+			subscription.WithMaxPending(cfg.Gateway.MaxPending),
+		))
+		if cfg.Gateway.RetainedMessages > 0 {
+			broker.RegisterMessageLog(retention.NewLog(cfg.Gateway.RetainedMessages))
+		}
+	}
+
 	hopperMQService := mq.New(
 		mq.WithLogger(logger),
 		mq.WithBroker(broker),
-		mq.WithTransport(tcpTransport),
+		mq.WithTransport(transports...),
 		mq.WithEventBus(eventBus),
 	)
 
 	httpEngine := gin.New()
-	httpServer := httpService.NewHTTPServer(
+	httpOpts := []httpService.Option{
 		httpService.WithLogger(logger),
 		httpService.WithEngine(httpEngine),
 		httpService.WithConfiguration(cfg),
-	)
+	}
+	if wsTransport != nil && cfg.Transport.WebSocket.Address == "" {
+		httpOpts = append(httpOpts, httpService.WithWebSocket(wsTransport.Path(), wsTransport.Handler()))
+	}
+	if cfg != nil && cfg.Gateway.Enabled {
+		httpOpts = append(httpOpts, httpService.WithGateway(broker, authenticator, routes.GatewayOptions{
+			MaxBodySize:     cfg.Gateway.MaxBodySize,
+			MaxPullWait:     cfg.Gateway.MaxPullWait,
+			StreamKeepAlive: cfg.Gateway.StreamKeepAlive,
+		}))
+	}
+	if cfg != nil && cfg.API.EnableClientManagement {
+		httpOpts = append(httpOpts, httpService.WithAdmin(broker, authenticator, authorizer))
+	}
+	httpServer := httpService.NewHTTPServer(httpOpts...)
 
 	uiEngine := gin.New()
 	uiService := ui.NewHTTPServer(
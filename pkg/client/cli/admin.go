@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// adminPath is the path of the management API.
+const adminPath = "/api/v1/admin/"
+
+// admin list the topics, clients or containers of the broker through the management API.
+func (a *App) admin(ctx context.Context, args []string) error {
+	fs := a.flagSet("admin", "admin [-url <url>] [-json] topics|clients|containers")
+	var conn connection
+	conn.register(fs)
+	url := fs.String("url", "", "management API address, the configured admin url when empty")
+	raw := fs.Bool("json", false, "print the JSON response")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("%w: expected one of topics, clients or containers", ErrUsage)
+	}
+
+	resource := fs.Arg(0)
+	switch resource {
+	case "topics", "clients", "containers":
+	default:
+		fs.Usage()
+		return fmt.Errorf("%w: unknown resource %q", ErrUsage, resource)
+	}
+
+	if *url == "" {
+		cfg, err := conn.load()
+		if err != nil {
+			return err
+		}
+		*url = cfg.Admin.URL
+	}
+
+	body, err := a.get(ctx, strings.TrimSuffix(*url, "/")+adminPath+resource, conn)
+	if err != nil {
+		return err
+	}
+
+	if *raw {
+		_, err := a.stdout.Write(body)
+		return err
+	}
+
+	return printResource(a.stdout, resource, body)
+}
+
+// get return the body of the management API response.
+func (a *App) get(ctx context.Context, url string, conn connection) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if conn.username != "" {
+		req.SetBasicAuth(conn.username, conn.password)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the management API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("management API: %s: %s", resp.Status, apiErr.Error)
+		}
+		return nil, fmt.Errorf("management API: %s", resp.Status)
+	}
+
+	return body, nil
+}
+
+// printResource write the resource listed in the body as a table.
+func printResource(out io.Writer, resource string, body []byte) error {
+	var list struct {
+		Topics     []domain.TopicInfo     `json:"topics"`
+		Clients    []domain.ClientInfo    `json:"clients"`
+		Containers []domain.ContainerInfo `json:"containers"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return fmt.Errorf("invalid management API response: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	switch resource {
+	case "topics":
+		fmt.Fprintln(w, "TOPIC\tSUBSCRIBERS")
+		for _, topic := range list.Topics {
+			fmt.Fprintf(w, "%s\t%d\n", topic.Topic, topic.Subscribers)
+		}
+	case "clients":
+		fmt.Fprintln(w, "ID\tCONTAINER\tIDENTITY\tTRANSPORT")
+		for _, c := range list.Clients {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.ID, c.ContainerID, orDash(c.Identity), c.Transport)
+		}
+	case "containers":
+		fmt.Fprintln(w, "ID\tCLIENT\tSTATE\tVERSION\tCAPABILITIES\tTOPICS")
+		for _, c := range list.Containers {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				c.ID, c.ClientID, c.State, c.ProtocolVersion, orDash(c.Capabilities), orDash(strings.Join(c.Topics, ",")))
+		}
+	}
+
+	return w.Flush()
+}
+
+// orDash return "-" for the empty values of a table.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
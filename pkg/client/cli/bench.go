@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/client"
+)
+
+// headerBenchSent carry the publish time of a bench message, in unix nanoseconds.
+const headerBenchSent = "x-bench-sent"
+
+// benchReport is the outcome of a bench run.
+type benchReport struct {
+	published int
+	failed    int
+	received  int
+	size      int
+
+	publishDuration time.Duration
+	receiveDuration time.Duration
+	latencies       []time.Duration
+}
+
+// bench publish messages to a topic consumed by a second client, and report the throughput and the delivery latency.
+func (a *App) bench(ctx context.Context, args []string) error {
+	fs := a.flagSet("bench", "bench [-topic <topic>] [-messages n] [-size bytes] [-batch n] [-timeout d]")
+	var conn connection
+	conn.register(fs)
+	topic := fs.String("topic", "bench.test", "topic to publish and consume the messages on")
+	messages := fs.Int("messages", 10000, "number of messages to publish")
+	size := fs.Int("size", 128, "content size of the messages in bytes")
+	batch := fs.Int("batch", 0, "messages per batch, the configured batch size when 0")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum duration of the run")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *messages <= 0 || *size < 0 || *batch < 0 {
+		fs.Usage()
+		return fmt.Errorf("%w: -messages must be positive, -size and -batch not negative", ErrUsage)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	consumer, stopConsumer, err := a.connect(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer stopConsumer()
+
+	producer, stopProducer, err := a.connect(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer stopProducer()
+
+	report := benchReport{size: *size, latencies: make([]time.Duration, 0, *messages)}
+	var (
+		mu    sync.Mutex
+		first time.Time
+		// expected is the number of messages to receive, the published messages once the publishes are settled.
+		expected = *messages
+		received = make(chan struct{})
+		done     sync.Once
+	)
+	_, err = consumer.Subscribe(ctx, *topic, func(_ context.Context, msg *client.Message) error {
+		now := time.Now()
+		sent, err := strconv.ParseInt(msg.Headers[headerBenchSent], 10, 64)
+		if err != nil {
+			// the probe of the subscription.
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if report.received == 0 {
+			first = now
+		}
+		report.latencies = append(report.latencies, now.Sub(time.Unix(0, sent)))
+		report.received++
+		report.receiveDuration = now.Sub(first)
+		if report.received >= expected {
+			done.Do(func() { close(received) })
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the broker handle the frames of a connection in order, the subscription is in place once the probe is confirmed.
+	if err := consumer.PublishAndWait(ctx, *topic, nil, nil); err != nil {
+		return fmt.Errorf("failed to probe the subscription: %w", err)
+	}
+
+	content := make([]byte, *size)
+	_, _ = rand.Read(content)
+
+	var opts []client.BatchOption
+	if *batch > 0 {
+		opts = append(opts, client.WithBatchSize(*batch))
+	}
+	publisher := producer.NewBatchPublisher(opts...)
+
+	start := time.Now()
+	results := make([]*client.PublishResult, 0, *messages)
+	for range *messages {
+		headers := map[string]string{headerBenchSent: strconv.FormatInt(time.Now().UnixNano(), 10)}
+		results = append(results, publisher.Publish(ctx, *topic, content, headers))
+	}
+	publisher.Close()
+	for _, result := range results {
+		if err := result.Wait(ctx); err != nil {
+			report.failed++
+		}
+	}
+	report.publishDuration = time.Since(start)
+
+	mu.Lock()
+	report.published = len(results) - report.failed
+	expected = report.published
+	if report.received >= expected {
+		done.Do(func() { close(received) })
+	}
+	mu.Unlock()
+
+	select {
+	case <-received:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	report.print(a)
+
+	if report.received < report.published {
+		return fmt.Errorf("received %d of the %d published messages: %w", report.received, report.published, ctx.Err())
+	}
+
+	return nil
+}
+
+// print write the report to the standard output.
+func (r *benchReport) print(a *App) {
+	rate := func(count int, d time.Duration) float64 {
+		if d <= 0 {
+			return 0
+		}
+		return float64(count) / d.Seconds()
+	}
+
+	publishRate := rate(r.published, r.publishDuration)
+	fmt.Fprintf(a.stdout, "published: %d messages of %d bytes in %s (%.0f msg/s, %.2f MB/s), %d failed\n",
+		r.published, r.size, r.publishDuration.Round(time.Millisecond), publishRate, publishRate*float64(r.size)/1e6, r.failed)
+	fmt.Fprintf(a.stdout, "received:  %d messages in %s (%.0f msg/s)\n",
+		r.received, r.receiveDuration.Round(time.Millisecond), rate(r.received, r.receiveDuration))
+
+	if len(r.latencies) == 0 {
+		return
+	}
+	slices.Sort(r.latencies)
+	fmt.Fprintf(a.stdout, "latency:   p50 %s, p95 %s, p99 %s, max %s\n",
+		percentile(r.latencies, 50), percentile(r.latencies, 95), percentile(r.latencies, 99), r.latencies[len(r.latencies)-1])
+}
+
+// percentile return the p-th percentile of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	index := (len(sorted)*p+99)/100 - 1
+
+	return sorted[max(index, 0)]
+}
@@ -0,0 +1,219 @@
+// Package cli represent the hopper-client command line, publishing, consuming and inspecting a broker.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/client"
+	"github.com/hoppermq/hopper/pkg/client/config"
+)
+
+// ErrUsage is returned for an unknown command or invalid flags.
+var ErrUsage = errors.New("invalid usage")
+
+const usage = `Usage: hopper-client <command> [flags]
+
+Commands:
+  publish     publish messages read from stdin or a file
+  consume     print the messages published on a topic pattern
+  bench       measure the publish throughput and the delivery latency
+  admin       list the topics, clients or containers of the broker
+
+Run "hopper-client <command> -h" for the flags of a command.
+The connection defaults to the client configuration, overridden by the HOPPER_* environment variables.
+`
+
+// App is the hopper-client command line.
+type App struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	clientOpts []client.Option
+	httpClient *http.Client
+}
+
+// Option configure the command line.
+type Option func(*App)
+
+// WithIO set the input and outputs of the commands, the process standard streams by default.
+func WithIO(stdin io.Reader, stdout, stderr io.Writer) Option {
+	return func(a *App) {
+		a.stdin = stdin
+		a.stdout = stdout
+		a.stderr = stderr
+	}
+}
+
+// WithClientOptions append the options to the clients connected by the commands, after the configuration.
+func WithClientOptions(opts ...client.Option) Option {
+	return func(a *App) {
+		a.clientOpts = append(a.clientOpts, opts...)
+	}
+}
+
+// WithHTTPClient set the client calling the management API.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(a *App) {
+		a.httpClient = httpClient
+	}
+}
+
+// New return the command line.
+func New(opts ...Option) *App {
+	a := &App{
+		stdin:      os.Stdin,
+		stdout:     os.Stdout,
+		stderr:     os.Stderr,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Run run the command of the arguments, without the program name.
+func (a *App) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, usage)
+		return ErrUsage
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "publish":
+		return a.publish(ctx, args)
+	case "consume":
+		return a.consume(ctx, args)
+	case "bench":
+		return a.bench(ctx, args)
+	case "admin":
+		return a.admin(ctx, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(a.stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(a.stderr, usage)
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, command)
+	}
+}
+
+// flagSet return the flag set of the command, the parse errors are returned rather than exiting.
+func (a *App) flagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: hopper-client %s\n\nFlags:\n", synopsis)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parse the arguments of the command, the flag errors are reported as ErrUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	return nil
+}
+
+// connection hold the flags shared by the commands to reach the broker.
+type connection struct {
+	config   string
+	brokers  string
+	username string
+	password string
+	verbose  bool
+}
+
+func (c *connection) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.config, "config", "", "client configuration file, the embedded defaults when empty")
+	fs.StringVar(&c.brokers, "brokers", "", "comma separated host:port broker addresses")
+	fs.StringVar(&c.username, "user", "", "username to authenticate with")
+	fs.StringVar(&c.password, "password", "", "password to authenticate with")
+	fs.BoolVar(&c.verbose, "v", false, "log the client activity")
+}
+
+// load the client configuration, overridden by the flags.
+func (c *connection) load() (*config.ClientConfiguration, error) {
+	var opts []config.Option
+	if c.brokers != "" {
+		opts = append(opts, config.WithBrokers(strings.Split(c.brokers, ",")...))
+	}
+	if c.username != "" {
+		opts = append(opts, config.WithCredentials(c.username, c.password))
+	}
+
+	if c.config != "" {
+		return config.LoadConfigFile(c.config, opts...)
+	}
+
+	return config.LoadConfig(opts...)
+}
+
+// connect run a client connected to the broker, it is stopped by the returned func.
+func (a *App) connect(ctx context.Context, conn connection, opts ...client.Option) (*client.Client, func(), error) {
+	cfg, err := conn.load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	level := slog.LevelWarn
+	if conn.verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(a.stderr, &slog.HandlerOptions{Level: level}))
+
+	opts = append([]client.Option{client.WithConfig(cfg), client.WithLogger(logger)}, append(a.clientOpts, opts...)...)
+	c := client.NewClient(opts...)
+	if err := c.Run(ctx); err != nil {
+		_ = c.Stop(context.Background())
+		return nil, nil, fmt.Errorf("failed to connect to the broker: %w", err)
+	}
+
+	stop := func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = c.Stop(stopCtx)
+	}
+
+	return c, stop, nil
+}
+
+// headers is a repeatable key=value flag.
+type headers map[string]string
+
+func (h headers) String() string {
+	pairs := make([]string, 0, len(h))
+	for key, value := range h {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (h headers) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("header %q is not key=value", pair)
+	}
+	h[key] = value
+
+	return nil
+}
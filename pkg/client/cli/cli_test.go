@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/client"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/embedded"
+)
+
+// syncBuffer is a buffer written by the command and read by the test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func startBroker(t *testing.T) *embedded.Broker {
+	t.Helper()
+
+	broker, err := embedded.New()
+	require.NoError(t, err)
+	require.NoError(t, broker.Start(context.Background()))
+	t.Cleanup(func() { _ = broker.Stop(context.Background()) })
+
+	return broker
+}
+
+func newApp(broker *embedded.Broker, stdin string, stdout, stderr *syncBuffer, opts ...Option) *App {
+	return New(append([]Option{
+		WithIO(strings.NewReader(stdin), stdout, stderr),
+		WithClientOptions(client.WithDialer(broker.DialContext)),
+	}, opts...)...)
+}
+
+func TestApp_Usage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "No_Command", args: nil, wantErr: ErrUsage},
+		{name: "Unknown_Command", args: []string{"produce"}, wantErr: ErrUsage},
+		{name: "Help", args: []string{"help"}},
+		{name: "Command_Help", args: []string{"publish", "-h"}, wantErr: flag.ErrHelp},
+		{name: "Unknown_Flag", args: []string{"consume", "-nope"}, wantErr: ErrUsage},
+		{name: "Publish_Without_Topic", args: []string{"publish"}, wantErr: ErrUsage},
+		{name: "Consume_Without_Topic", args: []string{"consume"}, wantErr: ErrUsage},
+		{name: "Consume_Unknown_Format", args: []string{"consume", "-topic", "a", "-format", "xml"}, wantErr: ErrUsage},
+		{name: "Bench_Without_Messages", args: []string{"bench", "-messages", "0"}, wantErr: ErrUsage},
+		{name: "Admin_Without_Resource", args: []string{"admin"}, wantErr: ErrUsage},
+		{name: "Admin_Unknown_Resource", args: []string{"admin", "queues"}, wantErr: ErrUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr syncBuffer
+			err := New(WithIO(strings.NewReader(""), &stdout, &stderr)).Run(context.Background(), tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, stdout.String(), "Usage: hopper-client")
+		})
+	}
+}
+
+func TestApp_Publish_Consume(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consumed, consumeLog syncBuffer
+	consumer := newApp(broker, "", &consumed, &consumeLog)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- consumer.Run(ctx, []string{"consume", "-topic", "orders.*", "-format", "json", "-count", "2"})
+	}()
+
+	// the consumer subscribe asynchronously, the lines are published until both are consumed.
+	var published, publishLog syncBuffer
+	require.Eventually(t, func() bool {
+		publisher := newApp(broker, "first\nsecond\n", &published, &publishLog)
+		args := []string{"publish", "-topic", "orders.created", "-lines", "-content-type", "text/plain", "-header", "source=cli"}
+		require.NoError(t, publisher.Run(ctx, args))
+
+		select {
+		case err := <-errs:
+			require.NoError(t, err)
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Contains(t, publishLog.String(), "published 2/2 message(s) to orders.created")
+
+	lines := strings.Split(strings.TrimSpace(consumed.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, content := range []string{"first", "second"} {
+		var msg jsonMessage
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &msg))
+		assert.Equal(t, "orders.created", msg.Topic)
+		assert.Equal(t, content, msg.Content)
+		assert.Equal(t, map[string]string{"source": "cli", client.HeaderContentType: "text/plain"}, msg.Headers)
+		assert.NotEmpty(t, msg.ID)
+	}
+}
+
+func TestNewPrinter(t *testing.T) {
+	t.Parallel()
+
+	msg := &client.Message{
+		ID:        "id-1",
+		Topic:     "orders.created",
+		Content:   []byte{0xff, 0xfe},
+		Headers:   map[string]string{"b": "2", "a": "1"},
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "Text",
+			format: FormatText,
+			want:   "2024-01-02T03:04:05Z orders.created id-1 a=1 b=2 //4=\n",
+		},
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			want: `{"id":"id-1","topic":"orders.created","timestamp":"2024-01-02T03:04:05Z",` +
+				`"headers":{"a":"1","b":"2"},"content":"//4=","encoding":"base64"}` + "\n",
+		},
+		{
+			name:   "Raw",
+			format: FormatRaw,
+			want:   "\xff\xfe\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			printer, err := newPrinter(tt.format, &out)
+			require.NoError(t, err)
+			require.NoError(t, printer(msg))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestApp_Bench(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	var stdout, stderr syncBuffer
+	app := newApp(broker, "", &stdout, &stderr)
+
+	err := app.Run(context.Background(), []string{"bench", "-messages", "50", "-size", "16", "-batch", "10", "-timeout", "10s"})
+	require.NoError(t, err, stderr.String())
+
+	assert.Contains(t, stdout.String(), "published: 50 messages of 16 bytes")
+	assert.Contains(t, stdout.String(), "received:  50 messages")
+	assert.Contains(t, stdout.String(), "latency:   p50")
+}
+
+func TestApp_Admin(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+			return
+		}
+
+		var body any
+		switch r.URL.Path {
+		case adminPath + "topics":
+			body = map[string]any{"topics": []domain.TopicInfo{{Topic: "orders.*", Subscribers: 2}}}
+		case adminPath + "clients":
+			body = map[string]any{"clients": []domain.ClientInfo{{ID: "c1", ContainerID: "k1", Transport: "tcp"}}}
+		case adminPath + "containers":
+			body = map[string]any{"containers": []domain.ContainerInfo{
+				{ID: "k1", ClientID: "c1", State: "open", ProtocolVersion: 1, Topics: []string{"a", "b"}},
+			}}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "Topics",
+			args: []string{"topics"},
+			want: []string{"TOPIC     SUBSCRIBERS", "orders.*  2"},
+		},
+		{
+			name: "Clients",
+			args: []string{"clients"},
+			want: []string{"ID  CONTAINER  IDENTITY  TRANSPORT", "c1  k1         -         tcp"},
+		},
+		{
+			name: "Containers",
+			args: []string{"containers"},
+			want: []string{"k1  c1      open   1        -             a,b"},
+		},
+		{
+			name: "JSON",
+			args: []string{"-json", "topics"},
+			want: []string{`{"topics":[{"topic":"orders.*","subscribers":2}]}`},
+		},
+		{
+			name:    "Unauthorized",
+			args:    []string{"-password", "wrong", "topics"},
+			wantErr: "unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr syncBuffer
+			app := New(WithIO(strings.NewReader(""), &stdout, &stderr), WithHTTPClient(server.Client()))
+			args := append([]string{"admin", "-url", server.URL, "-user", "admin", "-password", "secret"}, tt.args...)
+
+			err := app.Run(context.Background(), args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, stdout.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hoppermq/hopper/pkg/client"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Output formats of the consumed messages.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatRaw  = "raw"
+)
+
+// consume print the messages published on the topic pattern until interrupted or the count is reached.
+func (a *App) consume(ctx context.Context, args []string) error {
+	fs := a.flagSet("consume", "consume -topic <pattern> [-format text|json|raw] [-count n]")
+	var conn connection
+	conn.register(fs)
+	topic := fs.String("topic", "", `topic pattern to consume, e.g. "orders.*" or "orders.#" (required)`)
+	format := fs.String("format", FormatText, "output format: text, json or raw")
+	count := fs.Int("count", 0, "exit after printing count messages, 0 to consume until interrupted")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *topic == "" {
+		fs.Usage()
+		return fmt.Errorf("%w: -topic is required", ErrUsage)
+	}
+
+	printer, err := newPrinter(*format, a.stdout)
+	if err != nil {
+		return err
+	}
+
+	c, stop, err := a.connect(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	var (
+		mu      sync.Mutex
+		printed int
+		done    = make(chan struct{})
+	)
+	_, err = c.Subscribe(ctx, *topic, func(_ context.Context, msg *client.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if *count > 0 && printed >= *count {
+			return nil
+		}
+		if err := printer(msg); err != nil {
+			return err
+		}
+		printed++
+		if printed == *count {
+			close(done)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+// jsonMessage is the json output of a consumed message.
+type jsonMessage struct {
+	ID        domain.ID         `json:"id"`
+	Topic     string            `json:"topic"`
+	Timestamp time.Time         `json:"timestamp"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Content   string            `json:"content"`
+	// Encoding is "base64" for the contents which are not valid UTF-8.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// newPrinter return the func writing the messages to out in the format.
+func newPrinter(format string, out io.Writer) (func(msg *client.Message) error, error) {
+	switch format {
+	case FormatText:
+		return func(msg *client.Message) error {
+			content, _ := printable(msg.Content)
+			_, err := fmt.Fprintf(out, "%s %s %s%s %s\n",
+				msg.Timestamp.Format(time.RFC3339Nano), msg.Topic, msg.ID, formatHeaders(msg.Headers), content)
+			return err
+		}, nil
+	case FormatJSON:
+		encoder := json.NewEncoder(out)
+		return func(msg *client.Message) error {
+			content, encoding := printable(msg.Content)
+			return encoder.Encode(jsonMessage{
+				ID:        msg.ID,
+				Topic:     msg.Topic,
+				Timestamp: msg.Timestamp,
+				Headers:   msg.Headers,
+				Content:   content,
+				Encoding:  encoding,
+			})
+		}, nil
+	case FormatRaw:
+		return func(msg *client.Message) error {
+			_, err := fmt.Fprintf(out, "%s\n", msg.Content)
+			return err
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrUsage, format)
+	}
+}
+
+// printable return the content as text, base64 encoded when it is not valid UTF-8.
+func printable(content []byte) (string, string) {
+	if utf8.Valid(content) {
+		return string(content), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(content), "base64"
+}
+
+// formatHeaders return the headers as sorted " key=value" pairs.
+func formatHeaders(headers map[string]string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(headers)) {
+		fmt.Fprintf(&b, " %s=%s", key, headers[key])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/hoppermq/hopper/pkg/client"
+)
+
+// maxLineSize bound the size of a message read by line.
+const maxLineSize = 16 << 20
+
+// publish publish the input as a single message, or a message per line, and wait for the outcome of every message.
+func (a *App) publish(ctx context.Context, args []string) error {
+	fs := a.flagSet("publish", "publish -topic <topic> [-file <path>] [-lines] [-header key=value]...")
+	var conn connection
+	conn.register(fs)
+	topic := fs.String("topic", "", "topic to publish to (required)")
+	file := fs.String("file", "", "read the message from the file, stdin when empty")
+	lines := fs.Bool("lines", false, "publish every line of the input as a message")
+	contentType := fs.String("content-type", "", "content type of the messages, e.g. application/json")
+	msgHeaders := headers{}
+	fs.Var(msgHeaders, "header", "message header as key=value, repeatable")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *topic == "" {
+		fs.Usage()
+		return fmt.Errorf("%w: -topic is required", ErrUsage)
+	}
+	if *contentType != "" {
+		msgHeaders[client.HeaderContentType] = *contentType
+	}
+
+	input := a.stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	c, stop, err := a.connect(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	publisher := c.NewBatchPublisher()
+	var results []*client.PublishResult
+	send := func(content []byte) {
+		results = append(results, publisher.Publish(ctx, *topic, content, maps.Clone(msgHeaders)))
+	}
+
+	if *lines {
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			send(append([]byte(nil), scanner.Bytes()...))
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	} else {
+		content, err := io.ReadAll(input)
+		if err != nil {
+			return err
+		}
+		send(content)
+	}
+	publisher.Close()
+
+	var errs []error
+	for i, result := range results {
+		if err := result.Wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("message %d (%s): %w", i+1, result.MessageID, err))
+		}
+	}
+
+	fmt.Fprintf(a.stderr, "published %d/%d message(s) to %s\n", len(results)-len(errs), len(results), *topic)
+
+	return errors.Join(errs...)
+}
@@ -2,43 +2,188 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"log/slog"
+	"maps"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hoppermq/hopper/pkg/client/config"
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
 	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
+)
+
+const (
+	inboundQueueSize = 256
+	closeTimeout     = 2 * time.Second
+	topicWildcards   = "*#"
+	defaultQoS       = 0
+	noRoutingKey     = ""
+
+	defaultHandshakeTimeout   = 10 * time.Second
+	defaultOutboundBufferSize = 1024
+)
+
+var (
+	// ErrNotRunning is returned when publishing or subscribing before Run or after Stop.
+	ErrNotRunning = errors.New("hopper client not running")
+	// ErrInvalidTopic is returned for an empty topic, or a publish topic holding wildcards.
+	ErrInvalidTopic = errors.New("invalid topic")
+	// ErrNilHandler is returned when subscribing without handler.
+	ErrNilHandler = errors.New("nil message handler")
+	// ErrOutboundBufferFull is returned when publishing while disconnected with a full outbound buffer.
+	ErrOutboundBufferFull = errors.New("outbound buffer full")
 )
 
 type ClientState bool
 
 // Client represent the sdk client.
 type Client struct {
-	id    domain.ID
 	state ClientState
 
-	conn      domain.Connection
-	transport domain.Transport
+	transport  *tcp.Client
+	protocol   *hqp.Handler
+	serializer *serializer.Serializer
+
+	// handshake receive the outcome of the handshake, ready is closed once the session has begun.
+	handshake chan error
+	ready     chan struct{}
+	closed    chan struct{}
+	done      <-chan struct{}
+
+	subscriptions map[domain.ID]*Subscription
+	topics        map[string]int
+
+	// synced is set once the broker hold the subscriptions of the current session,
+	// the frames are sent directly while it is set and buffered or replayed otherwise.
+	synced atomic.Bool
+
+	outMu              sync.Mutex
+	outbound           []outgoingFrame
+	outboundBufferSize int
+
+	inboundQueue chan *Message
+	deliveries   chan delivery
+	credits      chan struct{}
+	// window is the capacity of the credits, read by Prefetch without synchronisation with Run.
+	window atomic.Int64
+
+	concurrency     int
+	prefetch        int
+	ackMode         AckMode
+	maxRedeliveries int
+
+	codecs map[string]Codec
+
+	// confirms hold the published messages awaiting a broker confirmation, by message ID.
+	// lostConns count the lost connections, a message written before the last one is never confirmed.
+	confirmMu     sync.Mutex
+	confirms      map[domain.ID]*pendingConfirm
+	lostConns     uint64
+	batchSize     int
+	flushInterval time.Duration
 
-	subscriptions     map[string]string
-	subscriptionsByID map[domain.ID]string
+	counters      counters
+	statsInterval time.Duration
+	statsHandler  StatsHandler
 
-	inboundQueue  chan string
-	outboundQueue chan string
+	publish            PublishFunc
+	publishMiddlewares []PublishMiddleware
+	consumeMiddlewares []ConsumeMiddleware
 
 	mu     sync.RWMutex
 	wg     sync.WaitGroup
 	cancel context.CancelFunc
 
-	logger *slog.Logger
+	logger       *slog.Logger
+	tlsConfig    *tls.Config
+	dialer       tcp.Dialer
+	protocolOpts []hqp.Option
+
+	addresses         []string
+	handshakeTimeout  time.Duration
+	reconnectInterval time.Duration
+	heartbeat         time.Duration
+	circuitFailures   int
+	circuitTimeout    time.Duration
+
+	// err hold the configuration error returned by Run.
+	err error
 }
 
 // Option type represent the injection function.
 type Option func(*Client)
 
-func WithConfig(cfg *config.ClientConfig) Option {
-	return func(c *Client) {}
+// WithConfig configure the client from a loaded configuration, see config.LoadConfig.
+// The options following it override the configuration.
+func WithConfig(cfg *config.ClientConfiguration) Option {
+	return func(c *Client) {
+		if cfg == nil {
+			return
+		}
+
+		if addresses := cfg.Addresses(); len(addresses) > 0 {
+			c.addresses = addresses
+		}
+		if cfg.Client.HandshakeTimeout > 0 {
+			c.handshakeTimeout = cfg.Client.HandshakeTimeout
+		}
+		if cfg.Client.OutboundBufferSize > 0 {
+			c.outboundBufferSize = cfg.Client.OutboundBufferSize
+		}
+		if cfg.Performance.BatchSize > 0 {
+			c.batchSize = cfg.Performance.BatchSize
+		}
+		if cfg.Performance.FlushInterval > 0 {
+			c.flushInterval = cfg.Performance.FlushInterval
+		}
+		if cfg.Transport.KeepAlive > 0 {
+			c.protocolOpts = append(c.protocolOpts, hqp.WithKeepAlive(uint16(cfg.Transport.KeepAlive.Seconds())))
+		}
+		if cfg.Transport.ReconnectInterval > 0 {
+			c.reconnectInterval = cfg.Transport.ReconnectInterval
+		}
+		if cfg.Transport.Heartbeat > 0 && cfg.Transport.CircuitFailures > 0 && cfg.Transport.CircuitTimeout > 0 {
+			c.heartbeat = cfg.Transport.Heartbeat
+			c.circuitFailures = cfg.Transport.CircuitFailures
+			c.circuitTimeout = cfg.Transport.CircuitTimeout
+		}
+		if cfg.Auth.Username != "" {
+			c.protocolOpts = append(c.protocolOpts, hqp.WithCredentials(cfg.Auth.Username, cfg.Auth.Password))
+		}
+
+		tlsConfig, err := cfg.TLSConfig()
+		if err != nil {
+			c.err = err
+			return
+		}
+		if tlsConfig != nil {
+			c.tlsConfig = tlsConfig
+		}
+	}
+}
+
+// WithCodec register the codec for its content type, replacing the default codec of the content type.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		c.codecs[codec.ContentType()] = codec
+	}
+}
+
+// WithBrokers set the "host:port" broker addresses, the client fail over to the next one when a broker is unreachable.
+func WithBrokers(addresses ...string) Option {
+	return func(c *Client) {
+		c.addresses = addresses
+	}
 }
 
 func WithLogger(logger *slog.Logger) Option {
@@ -47,61 +192,466 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithTLS enables TLS on the broker connection, see tcp.NewTLSConfig to build the configuration from files.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithDialer open the broker connection with the dialer, e.g. embedded.Broker.DialContext
+// to connect to a broker running in the same process without binding a port.
+func WithDialer(dialer tcp.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithOutboundBufferSize bound the number of messages published while disconnected,
+// they are sent once the session is restored. Publishing to a full buffer return ErrOutboundBufferFull.
+func WithOutboundBufferSize(size int) Option {
+	return func(c *Client) {
+		c.outboundBufferSize = size
+	}
+}
+
+// WithReconnectInterval set the interval between two reconnection attempts.
+func WithReconnectInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.reconnectInterval = interval
+	}
+}
+
+// WithCredentials authenticate the client with the PLAIN mechanism during the handshake.
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.protocolOpts = append(c.protocolOpts, hqp.WithCredentials(username, password))
+	}
+}
+
+// WithStateHandler notify the handler of the connection state transitions, e.g. to observe the handshake.
+func WithStateHandler(handler hqp.StateHandler) Option {
+	return func(c *Client) {
+		c.protocolOpts = append(c.protocolOpts, hqp.WithStateHandler(handler))
+	}
+}
+
+func withProtocol() Option {
+	return func(c *Client) {
+		opts := append([]hqp.Option{
+			hqp.WithStateHandler(c.handleStateChange),
+			hqp.WithMessageHandler(c.handleMessage),
+			hqp.WithConfirmHandler(c.handleConfirm),
+			hqp.WithErrorHandler(c.handleError),
+		}, c.protocolOpts...)
+		if c.prefetch > 0 {
+			opts = append(opts, hqp.WithIncomingWindow(uint32(c.prefetch)))
+		}
+		c.protocol = hqp.NewHandler(c.sendFrame, opts...)
+	}
+}
+
 func withTransport() Option {
 	return func(c *Client) {
-		tcpClient := tcp.NewTCPClient(
+		opts := []tcp.Option{
 			tcp.WithLogger(c.logger),
-		)
-		c.transport = tcpClient
+			tcp.WithTLS(c.tlsConfig),
+			tcp.WithDialer(c.dialer),
+			tcp.WithFrameHandler(c.handleFrame),
+			tcp.WithConnectionHandlers(c.protocol.Reset, c.protocol.Disconnected),
+		}
+		if len(c.addresses) > 0 {
+			opts = append(opts, tcp.WithAddresses(c.addresses...))
+		}
+		if c.reconnectInterval > 0 {
+			opts = append(opts, tcp.WithReconnectInterval(c.reconnectInterval))
+		}
+		if c.heartbeat > 0 {
+			opts = append(opts, tcp.WithHealthConfig(c.heartbeat, c.circuitFailures, c.circuitTimeout))
+		}
+		c.transport = tcp.NewTCPClient(opts...)
 	}
 }
 
 // NewClient create a new client.
 func NewClient(opts ...Option) *Client {
-	c := &Client{}
+	c := &Client{
+		serializer: serializer.NewSerializer(
+			common.NewPool(func() *bytes.Buffer {
+				return &bytes.Buffer{}
+			}),
+		),
+		handshake:     make(chan error, 1),
+		ready:         make(chan struct{}),
+		closed:        make(chan struct{}, 1),
+		subscriptions: make(map[domain.ID]*Subscription),
+		topics:        make(map[string]int),
+		inboundQueue:  make(chan *Message, inboundQueueSize),
+		codecs:        defaultCodecs(),
+		confirms:      make(map[domain.ID]*pendingConfirm),
+
+		concurrency:     defaultConcurrency,
+		maxRedeliveries: defaultMaxRedeliveries,
+
+		outboundBufferSize: defaultOutboundBufferSize,
+		handshakeTimeout:   defaultHandshakeTimeout,
+		batchSize:          defaultBatchSize,
+		flushInterval:      defaultFlushInterval,
+	}
 
-	opts = append(opts, withTransport())
 	for _, opts := range opts {
 		opts(c)
 	}
 
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+
+	withProtocol()(c)
+	withTransport()(c)
+	c.publish = chainPublish(c.sendMessage, c.publishMiddlewares)
+
 	return c
 }
 
-// Run start the client sdk workers.
+// Run connect the client to the broker, it return once the session has begun.
 func (c *Client) Run(ctx context.Context) error {
+	if c.err != nil {
+		return c.err
+	}
+
 	c.logger.Info("starting hopperMQ client")
 	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = ctx.Done()
 
-	c.setState(true)
 	if err := c.transport.Run(ctx); err != nil {
 		return err
 	}
 
-	<-ctx.Done()
+	timeout := time.NewTimer(c.handshakeTimeout)
+	defer timeout.Stop()
+
+	select {
+	case err := <-c.handshake:
+		if err != nil {
+			return err
+		}
+	case <-timeout.C:
+		return context.DeadlineExceeded
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.startConsumers(ctx)
+	if c.statsHandler != nil && c.statsInterval > 0 {
+		c.wg.Add(1)
+		go c.reportStats(ctx)
+	}
+	c.setState(true)
+	c.logger.Info("hopperMQ client connected", "client_id", c.ID(), "container_id", c.ContainerID(), "prefetch", c.Prefetch())
+
 	return nil
 }
 
 // Stop gracefully shutdown the client sdk.
 func (c *Client) Stop(ctx context.Context) error {
-	c.logger.Info("stopping hopperMQ client", "client_id", c.id)
+	c.logger.Info("stopping hopperMQ client", "client_id", c.ID())
 	c.setState(false)
+	c.close(ctx)
 
 	if c.cancel != nil {
 		c.cancel()
 	}
 
-	if c.conn != nil {
-		if err := c.conn.Close(); err != nil {
-			panic(err)
-		}
+	if err := c.transport.Stop(ctx); err != nil {
+		c.logger.Warn("failed to stop transport", "error", err)
 	}
 
 	c.wg.Wait()
+	// the messages still buffered are never sent.
+	c.failConfirms(ErrNotRunning, true)
 	c.logger.Info("hopperMQ client stopped successfully")
 	return nil
 }
 
+// close end the session with a Close frame and wait for the broker to acknowledge it.
+func (c *Client) close(ctx context.Context) {
+	if c.protocol.State() != hqp.StateConnected {
+		return
+	}
+
+	if err := c.protocol.Close(domain.CloseCodeNormal, "client stopping"); err != nil {
+		c.logger.Warn("failed to close session", "error", err)
+		return
+	}
+
+	timeout := time.NewTimer(closeTimeout)
+	defer timeout.Stop()
+
+	select {
+	case <-c.closed:
+	case <-timeout.C:
+		c.logger.Warn("broker did not acknowledge the Close frame")
+	case <-ctx.Done():
+	}
+}
+
+// ID return the client id assigned by the broker.
+func (c *Client) ID() domain.ID {
+	return c.protocol.SourceID()
+}
+
+// ContainerID return the container assigned by the broker.
+func (c *Client) ContainerID() domain.ID {
+	return c.protocol.ContainerID()
+}
+
+// State return the state of the broker connection.
+func (c *Client) State() hqp.State {
+	return c.protocol.State()
+}
+
+// Publish send the message to the topic through the publish middlewares, the topic must not hold wildcards.
+func (c *Client) Publish(ctx context.Context, topic string, content []byte, headers map[string]string) error {
+	return c.publish(ctx, c.newOutgoing(topic, content, headers))
+}
+
+// newOutgoing return a message to publish.
+func (c *Client) newOutgoing(topic string, content []byte, headers map[string]string) *Message {
+	msg := &Message{
+		ID:        common.GenerateIdentifier(),
+		Topic:     topic,
+		Content:   content,
+		Headers:   headers,
+		Timestamp: time.Now(),
+	}
+	// the middlewares may set headers, the caller headers are not modified.
+	if len(c.publishMiddlewares) > 0 {
+		msg.Headers = maps.Clone(headers)
+	}
+
+	return msg
+}
+
+// sendMessage send the message once the session has begun, it end the publish chain.
+func (c *Client) sendMessage(ctx context.Context, msg *Message) error {
+	data, err := c.encode(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	// the confirmation is expected before the frame is sent, the broker could answer first.
+	if msg.confirm != nil && !c.expectConfirm(msg.ID, msg.confirm) {
+		return ErrConfirmsUnsupported
+	}
+	if err := c.sendOrBuffer(outgoingFrame{id: msg.ID, data: data}); err != nil {
+		c.forgetConfirm(msg.ID)
+		return err
+	}
+
+	return nil
+}
+
+// encode validate the message and serialize its frame once the session has begun.
+func (c *Client) encode(ctx context.Context, msg *Message) ([]byte, error) {
+	if msg.Topic == "" || strings.ContainsAny(msg.Topic, topicWildcards) {
+		return nil, ErrInvalidTopic
+	}
+	if err := c.awaitReady(ctx); err != nil {
+		return nil, err
+	}
+
+	frame, err := frames.CreateMessageFrame(domain.DOFF4, msg.Topic, msg.ID, msg.Content, msg.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.serializer.SerializeFrame(frame)
+}
+
+// outgoingFrame is the encoded Message frame of a published message.
+type outgoingFrame struct {
+	id   domain.ID
+	data []byte
+}
+
+// sendOrBuffer send the encoded frames in a single write, or buffer them while the session is not restored.
+// The buffered frames are sent first to keep the publish order.
+func (c *Client) sendOrBuffer(frames ...outgoingFrame) error {
+	c.outMu.Lock()
+	defer c.outMu.Unlock()
+
+	if c.synced.Load() && len(c.outbound) == 0 {
+		err := c.write(frames...)
+		if err == nil || c.synced.Load() {
+			return err
+		}
+	}
+
+	if len(c.outbound)+len(frames) > c.outboundBufferSize {
+		return ErrOutboundBufferFull
+	}
+	c.outbound = append(c.outbound, frames...)
+
+	return nil
+}
+
+// flushOutbound send the frames buffered while disconnected, in order.
+func (c *Client) flushOutbound() {
+	c.outMu.Lock()
+	defer c.outMu.Unlock()
+
+	for len(c.outbound) > 0 {
+		if err := c.write(c.outbound[0]); err != nil {
+			c.logger.Warn("failed to flush outbound buffer", "pending", len(c.outbound), "error", err)
+			return
+		}
+		c.outbound[0] = outgoingFrame{}
+		c.outbound = c.outbound[1:]
+	}
+}
+
+// write send the frames in a single write, their confirmations are then awaited from the current connection.
+func (c *Client) write(frames ...outgoingFrame) error {
+	payload := frames[0].data
+	if len(frames) > 1 {
+		data := make([][]byte, len(frames))
+		for i, frame := range frames {
+			data[i] = frame.data
+		}
+		payload = bytes.Join(data, nil)
+	}
+
+	lostConns := c.lostConnections()
+	if err := c.transport.Send(payload); err != nil {
+		return err
+	}
+	c.counters.published.Add(uint64(len(frames)))
+	c.confirmWritten(lostConns, frames)
+
+	return nil
+}
+
+// Subscribe deliver the messages published on the topics matching the pattern to the handler.
+// A "*" segment match one segment and a "#" segment match zero or more segments, e.g. "orders.*".
+func (c *Client) Subscribe(ctx context.Context, topic string, handler MessageHandler) (*Subscription, error) {
+	if topic == "" {
+		return nil, ErrInvalidTopic
+	}
+	if handler == nil {
+		return nil, ErrNilHandler
+	}
+	if err := c.awaitReady(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the broker deliver a message once per client, the subscriptions sharing a topic share the broker subscription.
+	// While disconnected the broker subscription is created when the session is restored.
+	if c.topics[topic] == 0 && c.synced.Load() {
+		frame, err := frames.CreateSubscribeFrame(domain.DOFF4, topic, defaultQoS, noRoutingKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.sendFrame(frame); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{
+		ID:      common.GenerateIdentifier(),
+		Topic:   topic,
+		client:  c,
+		handler: chainConsume(handler, c.consumeMiddlewares),
+	}
+	c.topics[topic]++
+	c.subscriptions[sub.ID] = sub
+
+	return sub, nil
+}
+
+// unsubscribe remove the subscription, the broker subscription is removed with the last subscription of the topic.
+func (c *Client) unsubscribe(sub *Subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subscriptions[sub.ID]; !ok {
+		return nil
+	}
+	delete(c.subscriptions, sub.ID)
+
+	c.topics[sub.Topic]--
+	if c.topics[sub.Topic] > 0 {
+		return nil
+	}
+	delete(c.topics, sub.Topic)
+	if !c.synced.Load() {
+		return nil
+	}
+
+	frame, err := frames.CreateUnsubscribeFrame(domain.DOFF4, sub.Topic)
+	if err != nil {
+		return err
+	}
+
+	return c.sendFrame(frame)
+}
+
+// awaitReady wait for the session to begin.
+func (c *Client) awaitReady(ctx context.Context) error {
+	if c.done == nil {
+		return ErrNotRunning
+	}
+
+	select {
+	case <-c.done:
+		return ErrNotRunning
+	default:
+	}
+
+	select {
+	case <-c.ready:
+		return nil
+	case <-c.done:
+		return ErrNotRunning
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resubscribe restore the broker subscriptions of a new session.
+func (c *Client) resubscribe() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for topic := range c.topics {
+		frame, err := frames.CreateSubscribeFrame(domain.DOFF4, topic, defaultQoS, noRoutingKey)
+		if err != nil {
+			return err
+		}
+		if err := c.sendFrame(frame); err != nil {
+			return err
+		}
+	}
+	c.synced.Store(true)
+
+	return nil
+}
+
+func (c *Client) sendFrame(frame domain.Frame) error {
+	data, err := c.serializer.SerializeFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	return c.transport.Send(data)
+}
+
 func (c *Client) setState(state ClientState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.state = state
 }
@@ -1,2 +1,113 @@
 package client
 
+import (
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// handleFrame handle a frame read from the broker.
+func (c *Client) handleFrame(data []byte) {
+	frame, err := c.serializer.DeserializeFrame(data)
+	if err != nil {
+		c.logger.Warn("failed to deserialize frame", "error", err)
+		return
+	}
+
+	if err := c.protocol.HandleFrame(frame); err != nil {
+		c.logger.Warn("failed to handle frame", "type", frame.GetType(), "error", err)
+	}
+}
+
+// handleStateChange report the outcome of the first handshake to Run, and restore the session after a reconnection.
+func (c *Client) handleStateChange(change hqp.StateChange) {
+	c.logger.Debug("connection state changed", "from", change.From, "to", change.To, "error", change.Err)
+	if change.To != hqp.StateConnected {
+		c.synced.Store(false)
+		c.failConfirms(ErrConfirmLost, false)
+	}
+
+	switch change.To {
+	case hqp.StateConnected:
+		select {
+		case <-c.ready:
+			// the frames can not be sent from the transport reader, the broker could block writing its answers.
+			c.wg.Add(1)
+			go c.restore()
+		default:
+			c.synced.Store(true)
+			c.completeHandshake(nil)
+		}
+	case hqp.StateFailed:
+		select {
+		case <-c.ready:
+			// drop the connection, the transport retry the handshake on a new one.
+			c.logger.Warn("failed to restore session", "error", change.Err)
+			c.transport.HandleConnectionError(change.Err)
+		default:
+			c.completeHandshake(change.Err)
+		}
+	case hqp.StateClosed:
+		if change.Err != nil {
+			c.logger.Info("session closed by the broker", "error", change.Err)
+		}
+		select {
+		case c.closed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// restore re-subscribe the topics of the client on the new container the broker assigned,
+// then send the messages published while disconnected.
+func (c *Client) restore() {
+	defer c.wg.Done()
+
+	if err := c.resubscribe(); err != nil {
+		c.logger.Warn("failed to restore subscriptions", "error", err)
+		return
+	}
+
+	c.flushOutbound()
+	c.logger.Info("session restored", "container_id", c.ContainerID())
+}
+
+// handleError fail the publish of a rejected message, and log the other Error frames received once connected,
+// the broker keep the session open.
+func (c *Client) handleError(payload domain.ErrorFramePayload) {
+	err := NewProtocolError(payload)
+	if id := payload.GetDetails()[detailMessageID]; id != "" && c.completeConfirm(domain.ID(id), err) {
+		c.counters.rejected.Add(1)
+		return
+	}
+
+	c.logger.Warn("broker rejected a frame", "error", err)
+}
+
+// handleConfirm complete the publish of a message routed by the broker.
+func (c *Client) handleConfirm(payload domain.ConfirmFramePayload) {
+	if c.completeConfirm(payload.GetMessageID(), nil) {
+		c.counters.confirmed.Add(1)
+	}
+}
+
+// completeHandshake report the outcome of the handshake to Run, once.
+func (c *Client) completeHandshake(err error) {
+	select {
+	case c.handshake <- err:
+	default:
+		return
+	}
+
+	if err == nil {
+		close(c.ready)
+	}
+}
+
+// handleMessage queue the message for the dispatcher.
+func (c *Client) handleMessage(payload domain.MessageFramePayload) {
+	c.counters.received.Add(1)
+	select {
+	case c.inboundQueue <- newMessage(payload):
+	case <-c.done:
+	}
+}
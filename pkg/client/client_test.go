@@ -0,0 +1,375 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/hoppermq/hopper/internal/security"
+	"github.com/hoppermq/hopper/pkg/client/config"
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/embedded"
+)
+
+func newClient(broker *embedded.Broker, opts ...Option) *Client {
+	return NewClient(append([]Option{
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithDialer(broker.DialContext),
+	}, opts...)...)
+}
+
+func startClient(t *testing.T, broker *embedded.Broker, opts ...Option) *Client {
+	t.Helper()
+
+	c := newClient(broker, opts...)
+	require.NoError(t, c.Run(context.Background()))
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+
+	return c
+}
+
+func startBroker(t *testing.T, opts ...embedded.Option) *embedded.Broker {
+	t.Helper()
+
+	broker, err := embedded.New(opts...)
+	require.NoError(t, err)
+	require.NoError(t, broker.Start(context.Background()))
+	t.Cleanup(func() { _ = broker.Stop(context.Background()) })
+
+	return broker
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for message")
+		var zero T
+		return zero
+	}
+}
+
+func collect(ch chan<- *Message) MessageHandler {
+	return func(_ context.Context, msg *Message) error {
+		ch <- msg
+		return nil
+	}
+}
+
+func TestClient_Publish_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker)
+	ctx := context.Background()
+
+	created := make(chan *Message, 10)
+	all := make(chan *Message, 10)
+	sub, err := consumer.Subscribe(ctx, "orders.*", collect(created))
+	require.NoError(t, err)
+	_, err = consumer.Subscribe(ctx, "orders.#", collect(all))
+	require.NoError(t, err)
+
+	// the subscribe frames are handled before the message frame, they are read from the same connection.
+	require.NoError(t, producer.Publish(ctx, "orders.created", []byte(`{"id":1}`), map[string]string{"tenant": "acme"}))
+
+	msg := receive(t, created)
+	assert.Equal(t, "orders.created", msg.Topic)
+	assert.Equal(t, []byte(`{"id":1}`), msg.Content)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, msg.Headers)
+	assert.Equal(t, sub.ID, msg.SubscriptionID)
+	assert.NotEmpty(t, msg.ID)
+
+	assert.NotEqual(t, sub.ID, receive(t, all).SubscriptionID)
+
+	require.NoError(t, sub.Unsubscribe())
+	require.NoError(t, sub.Unsubscribe())
+	require.NoError(t, producer.Publish(ctx, "orders.shipped", []byte("2"), nil))
+
+	assert.Equal(t, "orders.shipped", receive(t, all).Topic)
+	assert.Empty(t, created)
+}
+
+func TestClient_Validation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	handler := func(context.Context, *Message) error { return nil }
+
+	idle := NewClient(WithLogger(slog.New(slog.DiscardHandler)))
+	assert.ErrorIs(t, idle.Publish(ctx, "orders.created", nil, nil), ErrNotRunning)
+	_, err := idle.Subscribe(ctx, "orders.*", handler)
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	c := startClient(t, startBroker(t))
+
+	tests := []struct {
+		name    string
+		publish func() error
+		want    error
+	}{
+		{
+			name:    "Publish_Empty_Topic",
+			publish: func() error { return c.Publish(ctx, "", nil, nil) },
+			want:    ErrInvalidTopic,
+		},
+		{
+			name:    "Publish_Wildcard_Topic",
+			publish: func() error { return c.Publish(ctx, "orders.*", nil, nil) },
+			want:    ErrInvalidTopic,
+		},
+		{
+			name: "Subscribe_Empty_Topic",
+			publish: func() error {
+				_, err := c.Subscribe(ctx, "", handler)
+				return err
+			},
+			want: ErrInvalidTopic,
+		},
+		{
+			name: "Subscribe_Nil_Handler",
+			publish: func() error {
+				_, err := c.Subscribe(ctx, "orders.*", nil)
+				return err
+			},
+			want: ErrNilHandler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.publish(), tt.want)
+		})
+	}
+}
+
+func TestClient_Handshake(t *testing.T) {
+	t.Parallel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	secured := startBroker(t, embedded.WithAuthenticator(
+		security.NewAuthenticator(security.User{Username: "app", PasswordHash: string(hash)}), 0,
+	))
+
+	tests := []struct {
+		name       string
+		broker     *embedded.Broker
+		opts       []Option
+		wantErr    error
+		wantStates []hqp.State
+	}{
+		{
+			name:       "Anonymous",
+			broker:     startBroker(t),
+			wantStates: []hqp.State{hqp.StateOpening, hqp.StateConnecting, hqp.StateConnected},
+		},
+		{
+			name:       "Credentials_Without_Authentication",
+			broker:     startBroker(t),
+			opts:       []Option{WithCredentials("app", "secret")},
+			wantStates: []hqp.State{hqp.StateOpening, hqp.StateConnecting, hqp.StateConnected},
+		},
+		{
+			name:       "Credentials",
+			broker:     secured,
+			opts:       []Option{WithCredentials("app", "secret")},
+			wantStates: []hqp.State{hqp.StateOpening, hqp.StateConnecting, hqp.StateConnected},
+		},
+		{
+			name:       "Bad_Credentials",
+			broker:     secured,
+			opts:       []Option{WithCredentials("app", "wrong")},
+			wantErr:    domain.ErrUnauthorized,
+			wantStates: []hqp.State{hqp.StateOpening, hqp.StateConnecting, hqp.StateFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				states []hqp.State
+			)
+			c := newClient(tt.broker, append(tt.opts, WithStateHandler(func(change hqp.StateChange) {
+				mu.Lock()
+				defer mu.Unlock()
+				states = append(states, change.To)
+			}))...)
+
+			err := c.Run(context.Background())
+			t.Cleanup(func() { _ = c.Stop(context.Background()) })
+
+			mu.Lock()
+			assert.Equal(t, tt.wantStates, states[:len(tt.wantStates)])
+			mu.Unlock()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, hqp.StateConnected, c.State())
+			assert.NotEmpty(t, c.ID())
+			assert.NotEmpty(t, c.ContainerID())
+
+			require.NoError(t, c.Stop(context.Background()))
+			assert.Equal(t, hqp.StateDisconnected, c.State())
+		})
+	}
+}
+
+// flakyDialer dial the broker and let the test drop the connections, the dials wait while the dialer is offline.
+type flakyDialer struct {
+	dial tcp.Dialer
+
+	mu     sync.Mutex
+	conns  []net.Conn
+	online chan struct{}
+}
+
+func newFlakyDialer(dial tcp.Dialer) *flakyDialer {
+	online := make(chan struct{})
+	close(online)
+
+	return &flakyDialer{dial: dial, online: online}
+}
+
+func (d *flakyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.mu.Lock()
+	online := d.online
+	d.mu.Unlock()
+
+	select {
+	case <-online:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	conn, err := d.dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns = append(d.conns, conn)
+
+	return conn, nil
+}
+
+// disconnect close the open connections and hold the next dials until reconnect is called.
+func (d *flakyDialer) disconnect() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.online = make(chan struct{})
+	for _, conn := range d.conns {
+		_ = conn.Close()
+	}
+	d.conns = nil
+}
+
+func (d *flakyDialer) reconnect() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	close(d.online)
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	observer := startClient(t, broker)
+	ctx := context.Background()
+
+	audits := make(chan *Message, 10)
+	_, err := observer.Subscribe(ctx, "audit.*", collect(audits))
+	require.NoError(t, err)
+
+	dialer := newFlakyDialer(broker.DialContext)
+	c := startClient(t, broker,
+		WithDialer(dialer.DialContext),
+		WithReconnectInterval(20*time.Millisecond),
+		WithOutboundBufferSize(2),
+	)
+	orders := make(chan *Message, 10)
+	_, err = c.Subscribe(ctx, "orders.*", collect(orders))
+	require.NoError(t, err)
+	previous := c.ContainerID()
+
+	dialer.disconnect()
+	require.Eventually(t, func() bool { return c.State() == hqp.StateDisconnected }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, c.Publish(ctx, "audit.first", []byte("1"), nil))
+	require.NoError(t, c.Publish(ctx, "audit.second", []byte("2"), nil))
+	assert.ErrorIs(t, c.Publish(ctx, "audit.third", []byte("3"), nil), ErrOutboundBufferFull)
+
+	inventory := make(chan *Message, 10)
+	_, err = c.Subscribe(ctx, "inventory.*", collect(inventory))
+	require.NoError(t, err)
+
+	dialer.reconnect()
+
+	// the buffered messages are published in order once the session is restored.
+	assert.Equal(t, "audit.first", receive(t, audits).Topic)
+	assert.Equal(t, "audit.second", receive(t, audits).Topic)
+	assert.Equal(t, hqp.StateConnected, c.State())
+	assert.NotEqual(t, previous, c.ContainerID())
+
+	// the subscriptions made before and during the disconnection are restored.
+	for topic, ch := range map[string]chan *Message{"orders.created": orders, "inventory.updated": inventory} {
+		require.NoError(t, producer.Publish(ctx, topic, nil, nil))
+		assert.Equal(t, topic, receive(t, ch).Topic)
+	}
+}
+
+func TestClient_Config(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+
+	var (
+		mu     sync.Mutex
+		dialed []string
+	)
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, address)
+		mu.Unlock()
+
+		if address == "broker-1:5672" {
+			return nil, errors.New("connection refused")
+		}
+		return broker.DialContext(ctx, network, address)
+	}
+
+	cfg, err := config.LoadConfig(config.WithBrokers("broker-1:5672", "broker-2:5672"))
+	require.NoError(t, err)
+
+	c := startClient(t, broker, WithConfig(cfg), WithDialer(dial))
+	assert.Equal(t, hqp.StateConnected, c.State())
+
+	mu.Lock()
+	assert.Equal(t, []string{"broker-1:5672", "broker-2:5672"}, dialed)
+	mu.Unlock()
+
+	cfg.Security.TLS = config.TLSConfig{Enabled: true, CAFile: "missing.pem"}
+	assert.Error(t, newClient(broker, WithConfig(cfg)).Run(context.Background()))
+}
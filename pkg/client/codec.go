@@ -0,0 +1,156 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderContentType is the message header holding the content type of the message content.
+const HeaderContentType = "content-type"
+
+const (
+	// ContentTypeJSON is the content type of the JSON codec, the default codec.
+	ContentTypeJSON = "application/json"
+	// ContentTypeProtobuf is the content type of the Protobuf codec.
+	ContentTypeProtobuf = "application/x-protobuf"
+	// ContentTypeMsgPack is the content type of the MessagePack codec.
+	ContentTypeMsgPack = "application/msgpack"
+)
+
+var (
+	// ErrUnknownContentType is returned when no codec is registered for the content type.
+	ErrUnknownContentType = errors.New("unknown content type")
+	// ErrUnsupportedType is returned when the codec can not encode or decode the value type.
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// Codec encode and decode the message contents of a content type.
+type Codec interface {
+	// ContentType return the content type set in the HeaderContentType header of the encoded messages.
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encode the message contents with encoding/json.
+type JSONCodec struct{}
+
+// ContentType return the JSON content type.
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal return the JSON encoding of v.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decode the JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtobufCodec encode the message contents with the Protobuf wire format, the values must be proto.Message.
+type ProtobufCodec struct{}
+
+// ContentType return the Protobuf content type.
+func (ProtobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+// Marshal return the Protobuf encoding of v.
+func (ProtobufCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedType, v)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Unmarshal decode the Protobuf data into v, a proto.Message or a pointer to a proto.Message which is allocated when nil.
+func (ProtobufCodec) Unmarshal(data []byte, v any) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedType, v)
+	}
+
+	target := rv.Elem()
+	if target.IsNil() {
+		target.Set(reflect.New(target.Type().Elem()))
+	}
+	msg, ok := target.Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedType, target.Interface())
+	}
+
+	return proto.Unmarshal(data, msg)
+}
+
+// MsgPackCodec encode the message contents with MessagePack.
+type MsgPackCodec struct{}
+
+// ContentType return the MessagePack content type.
+func (MsgPackCodec) ContentType() string {
+	return ContentTypeMsgPack
+}
+
+// Marshal return the MessagePack encoding of v.
+func (MsgPackCodec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal decode the MessagePack data into v.
+func (MsgPackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// defaultCodecs return the codecs registered on every client.
+func defaultCodecs() map[string]Codec {
+	codecs := make(map[string]Codec)
+	for _, codec := range []Codec{JSONCodec{}, ProtobufCodec{}, MsgPackCodec{}} {
+		codecs[codec.ContentType()] = codec
+	}
+
+	return codecs
+}
+
+// Codec return the codec registered for the content type, its parameters are ignored, e.g. "; charset=utf-8".
+func (c *Client) Codec(contentType string) (Codec, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	codec, ok := c.codecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownContentType, contentType)
+	}
+
+	return codec, nil
+}
+
+// Decode decode the message content into v with the codec of its content type,
+// the messages without content type are decoded as JSON.
+func (c *Client) Decode(msg *Message, v any) error {
+	contentType := msg.ContentType()
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	codec, err := c.Codec(contentType)
+	if err != nil {
+		return err
+	}
+
+	return codec.Unmarshal(msg.Content, v)
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type order struct {
+	ID    int    `json:"id" msgpack:"id"`
+	Item  string `json:"item" msgpack:"item"`
+	Price int    `json:"price" msgpack:"price"`
+}
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		codec  Codec
+		value  any
+		target func() any
+		want   any
+	}{
+		{
+			name:   "JSON",
+			codec:  JSONCodec{},
+			value:  order{ID: 1, Item: "book", Price: 12},
+			target: func() any { return &order{} },
+			want:   &order{ID: 1, Item: "book", Price: 12},
+		},
+		{
+			name:   "MsgPack",
+			codec:  MsgPackCodec{},
+			value:  order{ID: 1, Item: "book", Price: 12},
+			target: func() any { return &order{} },
+			want:   &order{ID: 1, Item: "book", Price: 12},
+		},
+		{
+			name:   "Protobuf",
+			codec:  ProtobufCodec{},
+			value:  wrapperspb.String("book"),
+			target: func() any { return &wrapperspb.StringValue{} },
+			want:   wrapperspb.String("book"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.codec.Marshal(tt.value)
+			require.NoError(t, err)
+
+			target := tt.target()
+			require.NoError(t, tt.codec.Unmarshal(data, target))
+
+			if msg, ok := tt.want.(proto.Message); ok {
+				assert.True(t, proto.Equal(msg, target.(proto.Message)))
+				return
+			}
+			assert.Equal(t, tt.want, target)
+		})
+	}
+
+	// the generic helpers decode into a nil pointer to a message.
+	data, err := ProtobufCodec{}.Marshal(wrapperspb.Int64(12))
+	require.NoError(t, err)
+	var v *wrapperspb.Int64Value
+	require.NoError(t, ProtobufCodec{}.Unmarshal(data, &v))
+	assert.Equal(t, int64(12), v.GetValue())
+
+	_, err = ProtobufCodec{}.Marshal(order{})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	assert.ErrorIs(t, ProtobufCodec{}.Unmarshal(nil, &order{}), ErrUnsupportedType)
+}
+
+func TestClient_Codec(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient(WithLogger(slog.New(slog.DiscardHandler)))
+
+	codec, err := c.Codec("application/json; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeJSON, codec.ContentType())
+
+	_, err = c.Codec("application/xml")
+	assert.ErrorIs(t, err, ErrUnknownContentType)
+
+	var v order
+	require.NoError(t, c.Decode(&Message{Content: []byte(`{"id":3}`)}, &v))
+	assert.Equal(t, 3, v.ID)
+}
+
+func TestClient_Typed(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker)
+	ctx := context.Background()
+
+	orders := make(chan order, 10)
+	_, err := SubscribeTyped(ctx, consumer, "orders.*", func(_ context.Context, msg *Message, v order) error {
+		orders <- v
+		return nil
+	})
+	require.NoError(t, err)
+
+	names := make(chan *wrapperspb.StringValue, 10)
+	_, err = SubscribeTyped(ctx, consumer, "names.*", func(_ context.Context, msg *Message, v *wrapperspb.StringValue) error {
+		names <- v
+		return nil
+	})
+	require.NoError(t, err)
+
+	raw := make(chan *Message, 10)
+	_, err = consumer.Subscribe(ctx, "orders.*", collect(raw))
+	require.NoError(t, err)
+
+	headers := map[string]string{"tenant": "acme"}
+	require.NoError(t, PublishJSON(ctx, producer, "orders.created", order{ID: 1, Item: "book"}, headers))
+	assert.Equal(t, map[string]string{"tenant": "acme"}, headers)
+	assert.Equal(t, order{ID: 1, Item: "book"}, receive(t, orders))
+	msg := receive(t, raw)
+	assert.Equal(t, ContentTypeJSON, msg.ContentType())
+	assert.Equal(t, "acme", msg.Headers["tenant"])
+
+	require.NoError(t, PublishTyped(ctx, producer, "orders.paid", order{ID: 2, Price: 12}, ContentTypeMsgPack, nil))
+	assert.Equal(t, order{ID: 2, Price: 12}, receive(t, orders))
+	assert.Equal(t, ContentTypeMsgPack, receive(t, raw).ContentType())
+
+	require.NoError(t, PublishTyped(ctx, producer, "names.created", wrapperspb.String("ada"), ContentTypeProtobuf, nil))
+	assert.Equal(t, "ada", receive(t, names).GetValue())
+
+	// the messages without content type are decoded as JSON.
+	require.NoError(t, producer.Publish(ctx, "orders.shipped", []byte(`{"id":3}`), nil))
+	assert.Equal(t, order{ID: 3}, receive(t, orders))
+
+	assert.ErrorIs(t, PublishTyped(ctx, producer, "orders.created", order{}, "application/xml", nil), ErrUnknownContentType)
+	assert.ErrorIs(t, PublishTyped(ctx, producer, "orders.created", order{}, ContentTypeProtobuf, nil), ErrUnsupportedType)
+	_, err = SubscribeTyped[order](ctx, consumer, "orders.*", nil)
+	assert.ErrorIs(t, err, ErrNilHandler)
+}
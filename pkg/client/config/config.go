@@ -2,53 +2,230 @@
 package config
 
 import (
+	"crypto/tls"
 	"embed"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/zixyos/goloader/config"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/env/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/providers/fs"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables overriding the configuration,
+	// e.g. HOPPER_TRANSPORT_BROKERS override transport.brokers.
+	EnvPrefix = "HOPPER_"
+
+	defaultsFile = "config.dev.toml"
 )
 
+// ErrInvalidConfig is returned when the loaded configuration can not drive the client.
+var ErrInvalidConfig = errors.New("invalid client configuration")
 
 //go:embed *.toml
 var configFs embed.FS
+
 type ClientConfig struct {
 	Environment string `koanf:"environment"`
 	Name        string `koanf:"name"`
 	Version     string `koanf:"version"`
-	MaxRetries  int    `koanf:"maxRetries"`
-	Timeout     int    `koanf:"timeout"`
+
+	// HandshakeTimeout bound the time Run wait for the session to begin.
+	HandshakeTimeout time.Duration `koanf:"handshake_timeout"`
+	// OutboundBufferSize bound the number of messages published while disconnected.
+	OutboundBufferSize int `koanf:"outbound_buffer_size"`
 }
 
 type TransportConfig struct {
-	Protocol  string `koanf:"protocol"`
-	Host      string `koanf:"host"`
-	Port      int    `koanf:"port"`
-	Keepalive string `koanf:"keepalive"`
-	Heartbeat string `koanf:"heartbeat"`
+	Protocol string `koanf:"protocol"`
+	Host     string `koanf:"host"`
+	Port     int    `koanf:"port"`
+	// Brokers list the "host:port" addresses tried in order, the client fail over to the next one
+	// when a broker is unreachable. Host and Port are used when it is empty.
+	Brokers []string `koanf:"brokers"`
+
+	KeepAlive time.Duration `koanf:"keep_alive"`
+	Heartbeat time.Duration `koanf:"heartbeat"`
+
+	ReconnectInterval time.Duration `koanf:"reconnect_interval"`
+	CircuitFailures   int           `koanf:"circuit_failures"`
+	CircuitTimeout    time.Duration `koanf:"circuit_timeout"`
 }
 
-type SecurityConfig struct{}
+type TLSConfig struct {
+	Enabled            bool   `koanf:"enabled"`
+	CAFile             string `koanf:"ca_file"`
+	CertFile           string `koanf:"cert_file"`
+	KeyFile            string `koanf:"key_file"`
+	ServerName         string `koanf:"server_name"`
+	InsecureSkipVerify bool   `koanf:"insecure_skip_verify"`
+}
+
+type SecurityConfig struct {
+	TLS TLSConfig `koanf:"tls"`
+}
+
+type AuthConfig struct {
+	Username string `koanf:"username"`
+	Password string `koanf:"password"`
+}
+
+type PerformanceConfig struct {
+	// BatchSize is the number of messages a batch publisher write at once.
+	BatchSize int `koanf:"batch_size"`
+	// FlushInterval bound the time a batch not full linger before being written.
+	FlushInterval time.Duration `koanf:"flush_interval"`
+}
 
-type AuthConfig struct{}
+type AdminConfig struct {
+	// URL is the base URL of the broker management API, e.g. http://localhost:8082.
+	URL string `koanf:"url"`
+}
 
 type ClientConfiguration struct {
-	Client    ClientConfig    `koanf:"client"`
-	Transport TransportConfig `koanf:"transport"`
-	Auth      AuthConfig      `koanf:"auth"`
-	Security  SecurityConfig  `koanf:"security"`
+	Client      ClientConfig      `koanf:"client"`
+	Transport   TransportConfig   `koanf:"transport"`
+	Auth        AuthConfig        `koanf:"auth"`
+	Security    SecurityConfig    `koanf:"security"`
+	Performance PerformanceConfig `koanf:"performance"`
+	Admin       AdminConfig       `koanf:"admin"`
 }
 
 type Option func(*ClientConfiguration)
 
+// WithBrokers override the broker addresses.
+func WithBrokers(addresses ...string) Option {
+	return func(c *ClientConfiguration) {
+		c.Transport.Brokers = addresses
+	}
+}
+
+// WithCredentials override the credentials.
+func WithCredentials(username, password string) Option {
+	return func(c *ClientConfiguration) {
+		c.Auth.Username = username
+		c.Auth.Password = password
+	}
+}
+
+// PostLoad validate the configuration.
 func (c *ClientConfiguration) PostLoad() error {
+	if c.Transport.Protocol != "" && c.Transport.Protocol != "tcp" {
+		return fmt.Errorf("%w: unsupported transport protocol %q", ErrInvalidConfig, c.Transport.Protocol)
+	}
+
+	for _, address := range c.Transport.Brokers {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return fmt.Errorf("%w: broker address %q: %w", ErrInvalidConfig, address, err)
+		}
+	}
+
+	if c.Transport.KeepAlive < 0 || c.Transport.KeepAlive.Seconds() > float64(^uint16(0)) {
+		return fmt.Errorf("%w: keep alive out of range: %s", ErrInvalidConfig, c.Transport.KeepAlive)
+	}
+
+	if c.Performance.BatchSize < 0 || c.Performance.FlushInterval < 0 {
+		return fmt.Errorf("%w: negative batch size or flush interval", ErrInvalidConfig)
+	}
+
+	if _, err := c.TLSConfig(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
 	return nil
 }
 
-func LoadConfig() (*ClientConfiguration, error) {
+// Addresses return the broker addresses, in failover order.
+func (c *ClientConfiguration) Addresses() []string {
+	if len(c.Transport.Brokers) > 0 {
+		return c.Transport.Brokers
+	}
+	if c.Transport.Host == "" {
+		return nil
+	}
+
+	return []string{net.JoinHostPort(c.Transport.Host, strconv.Itoa(c.Transport.Port))}
+}
+
+// TLSConfig return the TLS configuration of the broker connection, nil when TLS is disabled.
+func (c *ClientConfiguration) TLSConfig() (*tls.Config, error) {
+	if !c.Security.TLS.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig, err := tcp.NewTLSConfig(c.Security.TLS.CAFile, c.Security.TLS.CertFile, c.Security.TLS.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = c.Security.TLS.ServerName
+	tlsConfig.InsecureSkipVerify = c.Security.TLS.InsecureSkipVerify //nolint:gosec // opt-in for development brokers.
+
+	return tlsConfig, nil
+}
+
+// LoadConfig load the default configuration, overridden by the environment variables and the options.
+func LoadConfig(opts ...Option) (*ClientConfiguration, error) {
+	return load("", opts...)
+}
+
+// LoadConfigFile load the TOML file over the default configuration,
+// overridden by the environment variables and the options.
+func LoadConfigFile(path string, opts ...Option) (*ClientConfiguration, error) {
+	return load(path, opts...)
+}
+
+func load(path string, opts ...Option) (*ClientConfiguration, error) {
+	k := koanf.New(".")
+
+	if err := k.Load(fs.Provider(configFs, defaultsFile), toml.Parser()); err != nil {
+		return nil, fmt.Errorf("error loading default config: %w", err)
+	}
+
+	if path != "" {
+		if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+			return nil, fmt.Errorf("error loading config file: %w", err)
+		}
+	}
+
+	// the variables are matched against the known keys, a "_" can not tell a section from a word.
+	keys := make(map[string]string, len(k.Keys()))
+	for _, key := range k.Keys() {
+		keys[EnvPrefix+strings.ToUpper(strings.ReplaceAll(key, ".", "_"))] = key
+	}
+	if err := k.Load(env.Provider(".", env.Opt{
+		Prefix: EnvPrefix,
+		TransformFunc: func(name, value string) (string, any) {
+			key := keys[name]
+			// the lists are comma separated, e.g. HOPPER_TRANSPORT_BROKERS=broker-1:5672,broker-2:5672.
+			if _, ok := k.Get(key).([]any); ok {
+				return key, strings.Split(value, ",")
+			}
+
+			return key, value
+		},
+	}), nil); err != nil {
+		return nil, fmt.Errorf("error loading env variables: %w", err)
+	}
+
 	var cfg ClientConfiguration
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return nil, fmt.Errorf("error while unmarshalling config: %w", err)
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-	if err := config.Load(&cfg, config.WithFs(configFs)); err != nil {
-		fmt.Printf("error loading client config: %v\n", err)
+	if err := cfg.PostLoad(); err != nil {
 		return nil, err
 	}
 
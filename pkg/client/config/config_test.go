@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"localhost:5672"}, cfg.Addresses())
+	assert.Equal(t, 30*time.Second, cfg.Transport.KeepAlive)
+	assert.Equal(t, 10*time.Second, cfg.Client.HandshakeTimeout)
+	assert.Equal(t, 1024, cfg.Client.OutboundBufferSize)
+	assert.Equal(t, PerformanceConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, cfg.Performance)
+
+	tlsConfig, err := cfg.TLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfig(t, `
+[transport]
+brokers = ["broker-1:5672", "broker-2:5672"]
+keep_alive = "15s"
+
+[auth]
+username = "app"
+password = "secret"
+`)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		opts    []Option
+		want    func(t *testing.T, cfg *ClientConfiguration)
+		wantErr error
+	}{
+		{
+			name: "File",
+			want: func(t *testing.T, cfg *ClientConfiguration) {
+				assert.Equal(t, []string{"broker-1:5672", "broker-2:5672"}, cfg.Addresses())
+				assert.Equal(t, 15*time.Second, cfg.Transport.KeepAlive)
+				assert.Equal(t, AuthConfig{Username: "app", Password: "secret"}, cfg.Auth)
+				assert.Equal(t, 5*time.Second, cfg.Transport.ReconnectInterval)
+			},
+		},
+		{
+			name: "Env_Override_File",
+			env: map[string]string{
+				"HOPPER_TRANSPORT_BROKERS":           "broker-3:5672,broker-4:5672",
+				"HOPPER_AUTH_PASSWORD":               "from-env",
+				"HOPPER_CLIENT_OUTBOUND_BUFFER_SIZE": "8",
+				"HOPPER_UNKNOWN_KEY":                 "ignored",
+			},
+			want: func(t *testing.T, cfg *ClientConfiguration) {
+				assert.Equal(t, []string{"broker-3:5672", "broker-4:5672"}, cfg.Addresses())
+				assert.Equal(t, "from-env", cfg.Auth.Password)
+				assert.Equal(t, 8, cfg.Client.OutboundBufferSize)
+			},
+		},
+		{
+			name: "Options_Override_Env",
+			env:  map[string]string{"HOPPER_AUTH_USERNAME": "from-env"},
+			opts: []Option{WithBrokers("broker-5:5672"), WithCredentials("admin", "admin")},
+			want: func(t *testing.T, cfg *ClientConfiguration) {
+				assert.Equal(t, []string{"broker-5:5672"}, cfg.Addresses())
+				assert.Equal(t, AuthConfig{Username: "admin", Password: "admin"}, cfg.Auth)
+			},
+		},
+		{
+			name:    "Invalid_Broker_Address",
+			opts:    []Option{WithBrokers("broker-1")},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name:    "Invalid_Protocol",
+			env:     map[string]string{"HOPPER_TRANSPORT_PROTOCOL": "udp"},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "Missing_CA_File",
+			env: map[string]string{
+				"HOPPER_SECURITY_TLS_ENABLED": "true",
+				"HOPPER_SECURITY_TLS_CA_FILE": filepath.Join(t.TempDir(), "missing.pem"),
+			},
+			wantErr: ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := LoadConfigFile(path, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.want(t, cfg)
+		})
+	}
+
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.toml"))
+	assert.Error(t, err)
+}
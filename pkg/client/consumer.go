@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// AckMode tell how the delivered messages are settled.
+type AckMode int
+
+const (
+	// AckModeAuto ack the message once its handler returned without error.
+	AckModeAuto AckMode = iota
+	// AckModeManual leave the message unsettled until the handler call Message.Ack or Message.Nack.
+	AckModeManual
+)
+
+const (
+	defaultConcurrency     = 1
+	defaultPrefetch        = 256
+	defaultMaxRedeliveries = 3
+)
+
+var (
+	// ErrAlreadySettled is returned when acking or nacking a message more than once.
+	ErrAlreadySettled = errors.New("message already settled")
+	// ErrHandlerPanic is reported for the handlers which panicked, the message is nacked.
+	ErrHandlerPanic = errors.New("message handler panicked")
+)
+
+// delivery is a message delivered to a subscription.
+type delivery struct {
+	sub *Subscription
+	msg *Message
+}
+
+// WithConcurrency set the number of workers running the message handlers, 1 by default to keep the messages in order.
+func WithConcurrency(workers int) Option {
+	return func(c *Client) {
+		c.concurrency = workers
+	}
+}
+
+// WithPrefetch bound the number of delivered messages not yet settled, it is announced as the incoming window
+// of the Connect frame and capped by the outgoing window the broker answer in the Begin frame, which it default to.
+// The messages are not read from the broker while the credits are exhausted.
+func WithPrefetch(prefetch int) Option {
+	return func(c *Client) {
+		c.prefetch = prefetch
+	}
+}
+
+// WithAckMode set how the delivered messages are settled, AckModeAuto by default.
+func WithAckMode(mode AckMode) Option {
+	return func(c *Client) {
+		c.ackMode = mode
+	}
+}
+
+// WithMaxRedeliveries bound the local redeliveries of a nacked message, it is dropped afterward.
+func WithMaxRedeliveries(redeliveries int) Option {
+	return func(c *Client) {
+		c.maxRedeliveries = redeliveries
+	}
+}
+
+// Prefetch return the number of delivered messages which can be left unsettled, known once the session has begun.
+func (c *Client) Prefetch() int {
+	return int(c.window.Load())
+}
+
+// startConsumers size the credits from the session window and start the dispatcher and its workers.
+func (c *Client) startConsumers(ctx context.Context) {
+	prefetch := c.prefetch
+	if window := int(c.protocol.Session().OutgoingWindow); window > 0 && (prefetch <= 0 || prefetch > window) {
+		prefetch = window
+	}
+	if prefetch <= 0 {
+		prefetch = defaultPrefetch
+	}
+
+	// a delivery hold a credit until it is settled, the redelivered messages always fit in the queue.
+	c.credits = make(chan struct{}, prefetch)
+	c.window.Store(int64(prefetch))
+	c.deliveries = make(chan delivery, prefetch)
+
+	workers := max(c.concurrency, 1)
+	c.wg.Add(workers + 1)
+	go func() {
+		defer c.wg.Done()
+		c.dispatch(ctx)
+	}()
+	for range workers {
+		go func() {
+			defer c.wg.Done()
+			c.work(ctx)
+		}()
+	}
+}
+
+// dispatch deliver the received messages to the matching subscriptions, once a credit is available.
+func (c *Client) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.inboundQueue:
+			for _, sub := range c.matchingSubscriptions(msg.Topic) {
+				select {
+				case c.credits <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				c.counters.inFlight.Add(1)
+
+				c.deliveries <- delivery{sub: sub, msg: c.newDelivery(msg, sub, 1)}
+			}
+		}
+	}
+}
+
+// newDelivery return the copy of the message delivered to the subscription.
+func (c *Client) newDelivery(msg *Message, sub *Subscription, count int) *Message {
+	d := *msg
+	d.SubscriptionID = sub.ID
+	d.DeliveryCount = count
+	d.settlement = &settlement{
+		settle: func(m *Message, outcome outcome) {
+			c.settle(sub, m, outcome)
+		},
+	}
+
+	return &d
+}
+
+func (c *Client) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-c.deliveries:
+			c.handle(ctx, d)
+		}
+	}
+}
+
+// handle run the subscription handler, the errors and panics nack the message unless the handler settled it.
+func (c *Client) handle(ctx context.Context, d delivery) {
+	if !c.isSubscribed(d.sub) {
+		_ = d.msg.Ack()
+		return
+	}
+
+	start := time.Now()
+	err := c.invoke(ctx, d)
+	c.counters.handlerLatency.observe(time.Since(start))
+	if err != nil {
+		c.logger.Warn("message handler failed",
+			"subscription_id", d.sub.ID,
+			"topic", d.msg.Topic,
+			"delivery_count", d.msg.DeliveryCount,
+			"error", err)
+		_ = d.msg.Nack(true)
+		return
+	}
+
+	if c.ackMode == AckModeAuto {
+		_ = d.msg.Ack()
+	}
+}
+
+func (c *Client) invoke(ctx context.Context, d delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+		}
+	}()
+
+	return d.sub.handler(ctx, d.msg)
+}
+
+// settle release the credit of the message, or deliver it again when it is nacked with redeliver.
+func (c *Client) settle(sub *Subscription, msg *Message, outcome outcome) {
+	if outcome == outcomeRedeliver {
+		if msg.DeliveryCount <= c.maxRedeliveries && c.isSubscribed(sub) {
+			c.counters.redelivered.Add(1)
+			c.deliveries <- delivery{sub: sub, msg: c.newDelivery(msg, sub, msg.DeliveryCount+1)}
+			return
+		}
+		c.logger.Warn("message dropped after its last delivery",
+			"subscription_id", sub.ID,
+			"topic", msg.Topic,
+			"delivery_count", msg.DeliveryCount)
+	}
+
+	if outcome == outcomeAck {
+		c.counters.acked.Add(1)
+	} else {
+		c.counters.nacked.Add(1)
+	}
+	c.counters.inFlight.Add(-1)
+	<-c.credits
+}
+
+func (c *Client) isSubscribed(sub *Subscription) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.subscriptions[sub.ID]
+	return ok
+}
+
+func (c *Client) matchingSubscriptions(topic string) []*Subscription {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var matching []*Subscription
+	for _, sub := range c.subscriptions {
+		if domain.MatchTopic(sub.Topic, topic) {
+			matching = append(matching, sub)
+		}
+	}
+
+	return matching
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	const workers = 4
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker, WithConcurrency(workers))
+	ctx := context.Background()
+
+	var running atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, workers)
+	_, err := consumer.Subscribe(ctx, "jobs.*", func(context.Context, *Message) error {
+		running.Add(1)
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+
+	for i := range workers {
+		require.NoError(t, producer.Publish(ctx, "jobs.run", []byte(strconv.Itoa(i)), nil))
+	}
+
+	// the handlers block until released, they only all start when they run in parallel.
+	for range workers {
+		receive(t, started)
+	}
+	assert.Equal(t, int32(workers), running.Load())
+	close(release)
+}
+
+func TestClient_Prefetch(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	ctx := context.Background()
+
+	assert.Equal(t, 1000, producer.Prefetch(), "default to the Begin frame outgoing window")
+	assert.Equal(t, 1000, startClient(t, broker, WithPrefetch(5000)).Prefetch())
+
+	consumer := startClient(t, broker, WithPrefetch(2), WithAckMode(AckModeManual))
+	assert.Equal(t, 2, consumer.Prefetch())
+
+	received := make(chan *Message, 10)
+	_, err := consumer.Subscribe(ctx, "jobs.*", collect(received))
+	require.NoError(t, err)
+
+	for i := range 3 {
+		require.NoError(t, producer.Publish(ctx, "jobs.run", []byte(strconv.Itoa(i)), nil))
+	}
+
+	first := receive(t, received)
+	second := receive(t, received)
+	select {
+	case msg := <-received:
+		t.Fatalf("message %s delivered beyond the prefetch", msg.Content)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Ack())
+	assert.ErrorIs(t, first.Ack(), ErrAlreadySettled)
+	assert.Equal(t, []byte("2"), receive(t, received).Content)
+
+	require.NoError(t, second.Nack(false))
+	assert.ErrorIs(t, second.Nack(true), ErrAlreadySettled)
+}
+
+func TestClient_Nack(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker, WithMaxRedeliveries(2))
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		handler func(msg *Message) error
+		want    []int
+	}{
+		{
+			name:    "Panic",
+			handler: func(*Message) error { panic("boom") },
+			want:    []int{1, 2, 3},
+		},
+		{
+			name:    "Error",
+			handler: func(*Message) error { return errors.New("failed") },
+			want:    []int{1, 2, 3},
+		},
+		{
+			name: "Recovered",
+			handler: func(msg *Message) error {
+				if msg.DeliveryCount == 1 {
+					return errors.New("failed")
+				}
+				return nil
+			},
+			want: []int{1, 2},
+		},
+		{
+			name: "Nack_Without_Redelivery",
+			handler: func(msg *Message) error {
+				return msg.Nack(false)
+			},
+			want: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deliveries := make(chan int, 10)
+			topic := "jobs." + tt.name
+			sub, err := consumer.Subscribe(ctx, topic, func(_ context.Context, msg *Message) error {
+				deliveries <- msg.DeliveryCount
+				return tt.handler(msg)
+			})
+			require.NoError(t, err)
+			defer func() { require.NoError(t, sub.Unsubscribe()) }()
+
+			require.NoError(t, producer.Publish(ctx, topic, nil, nil))
+
+			for _, want := range tt.want {
+				assert.Equal(t, want, receive(t, deliveries))
+			}
+			select {
+			case count := <-deliveries:
+				t.Fatalf("unexpected delivery %d", count)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+
+	// the credits of the dropped and acked messages are released.
+	assert.Eventually(t, func() bool { return len(consumer.credits) == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// ProtocolError represent an Error frame sent back by the broker after rejecting an action.
+// It unwraps to the catalogued domain error so callers can use errors.Is, e.g. errors.Is(err, domain.ErrUnauthorized).
+type ProtocolError = hqp.ProtocolError
+
+// CloseError represent a Close frame sent by the broker without being requested, e.g. on shutdown.
+type CloseError = hqp.CloseError
+
+// NewProtocolError create a ProtocolError from an Error frame payload.
+func NewProtocolError(payload domain.ErrorFramePayload) *ProtocolError {
+	return hqp.NewProtocolError(payload)
+}
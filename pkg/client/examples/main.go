@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hoppermq/hopper/pkg/client"
+	"github.com/hoppermq/hopper/pkg/client/config"
 	"github.com/zixyos/glog"
 )
 
@@ -13,8 +14,14 @@ func main() {
 		return
 	}
 
+	// the environment variables override the file, e.g. HOPPER_AUTH_PASSWORD.
+	cfg, err := config.LoadConfigFile("config/config.dev.toml")
+	if err != nil {
+		return
+	}
+
 	sdk := client.NewClient(
-		client.WithConfig(nil),
+		client.WithConfig(cfg),
 		client.WithLogger(logger),
 	)
 
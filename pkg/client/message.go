@@ -1,24 +1,108 @@
 package client
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
+// protocolHQP is the protocol of the messages received from a HopperMQ broker.
+const protocolHQP = "hqp"
+
+// MessageHandler handle a message delivered to a subscription.
+type MessageHandler func(ctx context.Context, msg *Message) error
+
 type Message struct {
-	ID domain.ID
-	Topic string
+	ID      domain.ID
+	Topic   string
 	Content []byte
 	Headers map[string]string
 
 	Timestamp time.Time
-	SourceID domain.ID
+	SourceID  domain.ID
 
 	SubscriptionID domain.ID
+	// DeliveryCount count the deliveries of the message to the subscription, it is above 1 once redelivered.
+	DeliveryCount int
 
 	QoS uint8
 
-	protocol string
+	settlement *settlement
+	// confirm receive the outcome of a published message once the broker confirmed or rejected it.
+	confirm func(err error)
+
+	protocol      string
 	originalFrame any
 }
+
+type outcome int
+
+const (
+	outcomeAck outcome = iota
+	outcomeNack
+	outcomeRedeliver
+)
+
+// settlement settle a delivered message once.
+type settlement struct {
+	settled atomic.Bool
+	settle  func(msg *Message, outcome outcome)
+}
+
+// newMessage decode the payload of a Message frame.
+func newMessage(payload domain.MessageFramePayload) *Message {
+	return &Message{
+		ID:            payload.GetMessageID(),
+		Topic:         payload.GetTopic(),
+		Content:       payload.GetContent(),
+		Headers:       payload.GetHeaders(),
+		Timestamp:     time.Now(),
+		SourceID:      payload.GetSourceID(),
+		protocol:      protocolHQP,
+		originalFrame: payload,
+	}
+}
+
+// ContentType return the content type of the message content, empty when the publisher did not set it.
+func (m *Message) ContentType() string {
+	return m.Headers[HeaderContentType]
+}
+
+// SetHeader set the header, e.g. from a publish middleware.
+func (m *Message) SetHeader(key, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+}
+
+// Ack settle the message as processed, it release the prefetch credit it held.
+func (m *Message) Ack() error {
+	return m.complete(outcomeAck)
+}
+
+// Nack settle the message as failed, with redeliver the client deliver it again to its subscription
+// until the client max redeliveries, otherwise it is dropped. The redelivery is local to the client,
+// the broker does not keep the pushed messages and is not told about the nack.
+func (m *Message) Nack(redeliver bool) error {
+	if redeliver {
+		return m.complete(outcomeRedeliver)
+	}
+
+	return m.complete(outcomeNack)
+}
+
+func (m *Message) complete(outcome outcome) error {
+	if m.settlement == nil {
+		return nil
+	}
+	if !m.settlement.settled.CompareAndSwap(false, true) {
+		return ErrAlreadySettled
+	}
+
+	m.settlement.settle(m, outcome)
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// PublishFunc publish a message, it is the publish path the publish middlewares wrap.
+type PublishFunc func(ctx context.Context, msg *Message) error
+
+// PublishMiddleware wrap the publish path, e.g. to set headers or encrypt the content before the message is sent.
+type PublishMiddleware func(next PublishFunc) PublishFunc
+
+// ConsumeMiddleware wrap the message handlers of the subscriptions.
+type ConsumeMiddleware func(next MessageHandler) MessageHandler
+
+// WithPublishMiddleware append the middlewares to the publish chain, the first middleware is the outermost.
+func WithPublishMiddleware(middlewares ...PublishMiddleware) Option {
+	return func(c *Client) {
+		c.publishMiddlewares = append(c.publishMiddlewares, middlewares...)
+	}
+}
+
+// WithConsumeMiddleware append the middlewares to the chain wrapping every subscription handler,
+// the first middleware is the outermost.
+func WithConsumeMiddleware(middlewares ...ConsumeMiddleware) Option {
+	return func(c *Client) {
+		c.consumeMiddlewares = append(c.consumeMiddlewares, middlewares...)
+	}
+}
+
+func chainPublish(publish PublishFunc, middlewares []PublishMiddleware) PublishFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		publish = middlewares[i](publish)
+	}
+
+	return publish
+}
+
+func chainConsume(handler MessageHandler, middlewares []ConsumeMiddleware) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// PublishLogger log the published messages at debug level and the failed publishes at warn level.
+func PublishLogger(logger *slog.Logger) PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			attrs := []any{"message_id", msg.ID, "topic", msg.Topic, "size", len(msg.Content), "duration", time.Since(start)}
+			if err != nil {
+				logger.WarnContext(ctx, "failed to publish message", append(attrs, "error", err)...)
+				return err
+			}
+			logger.DebugContext(ctx, "message published", attrs...)
+
+			return nil
+		}
+	}
+}
+
+// ConsumeLogger log the handled messages at debug level and the handler failures at warn level.
+func ConsumeLogger(logger *slog.Logger) ConsumeMiddleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			attrs := []any{
+				"message_id", msg.ID,
+				"topic", msg.Topic,
+				"subscription_id", msg.SubscriptionID,
+				"delivery_count", msg.DeliveryCount,
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				logger.WarnContext(ctx, "message handler failed", append(attrs, "error", err)...)
+				return err
+			}
+			logger.DebugContext(ctx, "message handled", attrs...)
+
+			return nil
+		}
+	}
+}
+
+// PublishRetry retry the failed publishes up to attempts times, the delay between two attempts start
+// at initial and double up to maxDelay. The invalid topics and the stopped clients are not retried.
+func PublishRetry(attempts int, initial, maxDelay time.Duration) PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, msg *Message) error {
+			return retry(ctx, attempts, initial, maxDelay, func() error {
+				return next(ctx, msg)
+			})
+		}
+	}
+}
+
+// ConsumeRetry call the handler again on failure up to attempts times before the message is nacked,
+// the delay between two attempts start at initial and double up to maxDelay.
+func ConsumeRetry(attempts int, initial, maxDelay time.Duration) ConsumeMiddleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *Message) error {
+			return retry(ctx, attempts, initial, maxDelay, func() error {
+				return next(ctx, msg)
+			})
+		}
+	}
+}
+
+// retry call fn until it succeed, return a permanent error or the attempts are exhausted.
+func retry(ctx context.Context, attempts int, initial, maxDelay time.Duration, fn func() error) error {
+	delay := initial
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || permanent(err) || attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// permanent tell if retrying can not succeed.
+func permanent(err error) bool {
+	return errors.Is(err, ErrInvalidTopic) ||
+		errors.Is(err, ErrNotRunning) ||
+		errors.Is(err, ErrUnknownContentType) ||
+		errors.Is(err, ErrUnsupportedType) ||
+		errors.Is(err, ErrAlreadySettled) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
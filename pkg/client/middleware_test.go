@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record append the name of the middleware to the trace header on both paths.
+func record(name string) (PublishMiddleware, ConsumeMiddleware) {
+	publish := func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, msg *Message) error {
+			msg.SetHeader("trace", msg.Headers["trace"]+name)
+			return next(ctx, msg)
+		}
+	}
+	consume := func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg *Message) error {
+			msg.SetHeader("trace", msg.Headers["trace"]+name)
+			return next(ctx, msg)
+		}
+	}
+
+	return publish, consume
+}
+
+func TestClient_Middleware(t *testing.T) {
+	t.Parallel()
+
+	publishA, consumeA := record("a")
+	publishB, consumeB := record("b")
+
+	broker := startBroker(t)
+	producer := startClient(t, broker, WithPublishMiddleware(publishA, publishB))
+	consumer := startClient(t, broker, WithConsumeMiddleware(consumeA), WithConsumeMiddleware(consumeB))
+	ctx := context.Background()
+
+	received := make(chan *Message, 10)
+	_, err := consumer.Subscribe(ctx, "orders.*", collect(received))
+	require.NoError(t, err)
+
+	headers := map[string]string{"tenant": "acme"}
+	require.NoError(t, producer.Publish(ctx, "orders.created", nil, headers))
+	assert.Equal(t, map[string]string{"tenant": "acme"}, headers)
+
+	msg := receive(t, received)
+	assert.Equal(t, "abab", msg.Headers["trace"])
+	assert.Equal(t, "acme", msg.Headers["tenant"])
+
+	// the topic is validated at the end of the chain, after the middlewares.
+	rewrite := func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, msg *Message) error {
+			msg.Topic = ""
+			return next(ctx, msg)
+		}
+	}
+	rewriting := startClient(t, broker, WithPublishMiddleware(rewrite))
+	assert.ErrorIs(t, rewriting.Publish(ctx, "orders.created", nil, nil), ErrInvalidTopic)
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	transient := errors.New("transient")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		attempts  int
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "Success",
+			errs:      []error{nil},
+			attempts:  3,
+			wantCalls: 1,
+		},
+		{
+			name:      "Recovered",
+			errs:      []error{transient, ErrOutboundBufferFull, nil},
+			attempts:  3,
+			wantCalls: 3,
+		},
+		{
+			name:      "Exhausted",
+			errs:      []error{transient, transient, transient},
+			attempts:  3,
+			wantCalls: 3,
+			wantErr:   transient,
+		},
+		{
+			name:      "Permanent",
+			errs:      []error{ErrInvalidTopic},
+			attempts:  3,
+			wantCalls: 1,
+			wantErr:   ErrInvalidTopic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			}
+
+			publish := PublishRetry(tt.attempts, time.Millisecond, 2*time.Millisecond)(func(context.Context, *Message) error {
+				return next()
+			})
+			err := publish(context.Background(), &Message{})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			calls = 0
+			handler := ConsumeRetry(tt.attempts, time.Millisecond, 2*time.Millisecond)(func(context.Context, *Message) error {
+				return next()
+			})
+			err = handler(context.Background(), &Message{})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := PublishRetry(3, time.Hour, time.Hour)(func(context.Context, *Message) error { return transient })(ctx, &Message{})
+	assert.ErrorIs(t, err, transient)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoggers(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	failed := errors.New("failed")
+	msg := &Message{ID: "msg-1", Topic: "orders.created", SubscriptionID: "sub-1"}
+
+	require.NoError(t, PublishLogger(logger)(func(context.Context, *Message) error { return nil })(context.Background(), msg))
+	assert.Contains(t, buf.String(), `level=DEBUG msg="message published" message_id=msg-1 topic=orders.created`)
+
+	buf.Reset()
+	assert.ErrorIs(t, ConsumeLogger(logger)(func(context.Context, *Message) error { return failed })(context.Background(), msg), failed)
+	assert.Contains(t, buf.String(), `level=WARN msg="message handler failed" message_id=msg-1 topic=orders.created subscription_id=sub-1`)
+	assert.Contains(t, buf.String(), "error=failed")
+}
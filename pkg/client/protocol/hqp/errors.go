@@ -0,0 +1,45 @@
+package hqp
+
+import (
+	"fmt"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// ProtocolError represent an Error frame sent back by the broker after rejecting an action.
+// It unwraps to the catalogued domain error so callers can use errors.Is, e.g. errors.Is(err, domain.ErrUnauthorized).
+type ProtocolError struct {
+	Code    domain.ErrorCode
+	Message string
+	Details map[string]string
+}
+
+// NewProtocolError create a ProtocolError from an Error frame payload.
+func NewProtocolError(payload domain.ErrorFramePayload) *ProtocolError {
+	return &ProtocolError{
+		Code:    domain.ErrorCode(payload.GetErrorCode()),
+		Message: payload.GetErrorMessage(),
+		Details: payload.GetDetails(),
+	}
+}
+
+// Error return the error message.
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("hopper: %s (0x%04x): %s", e.Code, uint16(e.Code), e.Message)
+}
+
+// Unwrap return the catalogued error matching the code.
+func (e *ProtocolError) Unwrap() error {
+	return e.Code.Err()
+}
+
+// CloseError represent a Close frame sent by the broker without being requested, e.g. on shutdown.
+type CloseError struct {
+	Code   uint16
+	Reason string
+}
+
+// Error return the error message.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("hopper: connection closed by the broker (0x%04x): %s", e.Code, e.Reason)
+}
@@ -1 +1,358 @@
+// Package hqp drive the HopperMQ protocol of a client connection, from the broker Open frame to the Close frame.
 package hqp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+// State is the state of the client side of a connection.
+type State string
+
+const (
+	// StateDisconnected is the state of a handler without connection.
+	StateDisconnected State = "disconnected"
+	// StateOpening is the state of a connection waiting for the broker Open frame.
+	StateOpening State = "opening"
+	// StateConnecting is the state of a connection which sent its Connect frame and wait for the Begin frame.
+	StateConnecting State = "connecting"
+	// StateConnected is the state of a connection whose session has begun.
+	StateConnected State = "connected"
+	// StateClosing is the state of a connection which sent its Close frame and wait for the broker Close frame.
+	StateClosing State = "closing"
+	// StateClosed is the state of a connection closed by either peer.
+	StateClosed State = "closed"
+	// StateFailed is the state of a connection whose handshake was rejected by the broker.
+	StateFailed State = "failed"
+)
+
+const (
+	// ClientVersion is the client version sent in the Connect frame.
+	ClientVersion = "hopper-go/0.1"
+
+	defaultKeepAlive = 30
+	// Capabilities are the capabilities requested in the Connect frame.
+	Capabilities = domain.CapabilityLargeFrames | domain.CapabilityConfirms
+)
+
+// ErrUnexpectedFrame is returned when the broker send a frame the current state does not expect.
+var ErrUnexpectedFrame = errors.New("unexpected frame")
+
+// StateChange describe a transition of the connection state, Err hold the cause of a failed or closed connection.
+type StateChange struct {
+	From State
+	To   State
+	Err  error
+}
+
+// StateHandler is notified of the connection state transitions.
+type StateHandler func(change StateChange)
+
+// MessageHandler handle the payload of a Message frame.
+type MessageHandler func(payload domain.MessageFramePayload)
+
+// ConfirmHandler handle the payload of a Confirm frame.
+type ConfirmHandler func(payload domain.ConfirmFramePayload)
+
+// ErrorHandler handle the Error frames received once connected.
+type ErrorHandler func(payload domain.ErrorFramePayload)
+
+// Sender send a frame to the broker.
+type Sender func(frame domain.Frame) error
+
+// Session is the session announced by the broker in the Begin frame.
+type Session struct {
+	RemoteChannel  uint16
+	NextOutgoingID uint32
+	IncomingWindow uint32
+	OutgoingWindow uint32
+}
+
+// Handler is the client side of the HopperMQ protocol.
+type Handler struct {
+	send      Sender
+	handshake protocol.ClientHandshake
+
+	onStateChange []StateHandler
+	onMessage     MessageHandler
+	onConfirm     ConfirmHandler
+	onError       ErrorHandler
+
+	mu           sync.RWMutex
+	state        State
+	sourceID     domain.ID
+	containerID  domain.ID
+	capabilities domain.Capability
+	session      Session
+}
+
+// Option configure the protocol handler.
+type Option func(*Handler)
+
+// WithClientVersion set the client version sent in the Connect frame.
+func WithClientVersion(version string) Option {
+	return func(h *Handler) {
+		h.handshake.ClientVersion = version
+	}
+}
+
+// WithKeepAlive set the keep alive in seconds sent in the Connect frame.
+func WithKeepAlive(keepAlive uint16) Option {
+	return func(h *Handler) {
+		h.handshake.KeepAlive = keepAlive
+	}
+}
+
+// WithIncomingWindow set the number of messages the client accept in flight, sent in the Connect frame.
+// The broker cap the outgoing window of its Begin frame with it.
+func WithIncomingWindow(window uint32) Option {
+	return func(h *Handler) {
+		h.handshake.IncomingWindow = window
+	}
+}
+
+// WithCredentials authenticate with the PLAIN mechanism before connecting.
+func WithCredentials(username, password string) Option {
+	return func(h *Handler) {
+		h.handshake.Mechanism = domain.AuthMechanismPlain
+		h.handshake.Response = []byte("\x00" + username + "\x00" + password)
+	}
+}
+
+// WithStateHandler notify the handler of the state transitions, in the order they happen.
+func WithStateHandler(handler StateHandler) Option {
+	return func(h *Handler) {
+		if handler != nil {
+			h.onStateChange = append(h.onStateChange, handler)
+		}
+	}
+}
+
+// WithMessageHandler hand the received messages to the handler.
+func WithMessageHandler(handler MessageHandler) Option {
+	return func(h *Handler) {
+		h.onMessage = handler
+	}
+}
+
+// WithConfirmHandler hand the publish confirmations to the handler.
+func WithConfirmHandler(handler ConfirmHandler) Option {
+	return func(h *Handler) {
+		h.onConfirm = handler
+	}
+}
+
+// WithErrorHandler hand the Error frames received once connected to the handler,
+// the Error frames received during the handshake fail the connection.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(h *Handler) {
+		h.onError = handler
+	}
+}
+
+// NewHandler return a protocol handler sending its frames with send.
+func NewHandler(send Sender, opts ...Option) *Handler {
+	h := &Handler{
+		send: send,
+		handshake: protocol.ClientHandshake{
+			Acknowledge:   true,
+			ClientVersion: ClientVersion,
+			KeepAlive:     defaultKeepAlive,
+			Capabilities:  Capabilities,
+		},
+		state: StateDisconnected,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Reset start the handshake of a new connection, the broker send its Open frame once connected.
+func (h *Handler) Reset() {
+	h.transition(StateOpening, nil)
+}
+
+// Disconnected record the loss of the connection.
+func (h *Handler) Disconnected(cause error) {
+	if h.State() != StateDisconnected {
+		h.transition(StateDisconnected, cause)
+	}
+}
+
+// HandleFrame handle a frame received from the broker.
+func (h *Handler) HandleFrame(frame domain.Frame) error {
+	switch payload := frame.GetPayload().(type) {
+	case domain.OpenFramePayload:
+		return h.handleOpen(payload)
+	case domain.BeginFramePayload:
+		return h.handleBegin(payload)
+	case domain.MessageFramePayload:
+		if h.onMessage != nil {
+			h.onMessage(payload)
+		}
+	case domain.ConfirmFramePayload:
+		if h.onConfirm != nil {
+			h.onConfirm(payload)
+		}
+	case domain.ErrorFramePayload:
+		h.handleError(payload)
+	case domain.CloseFramePayload:
+		h.handleClose(payload)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnexpectedFrame, frame.GetType())
+	}
+
+	return nil
+}
+
+// handleOpen acknowledge the Open frame, authenticate when credentials are set, then connect.
+func (h *Handler) handleOpen(payload domain.OpenFramePayload) error {
+	if state := h.State(); state != StateOpening && state != StateDisconnected {
+		return fmt.Errorf("%w: Open frame in state %s", ErrUnexpectedFrame, state)
+	}
+
+	h.mu.Lock()
+	h.sourceID = payload.GetSourceID()
+	h.containerID = payload.GetAssignedContainerID()
+	h.capabilities = payload.GetCapabilities() & Capabilities
+	h.mu.Unlock()
+
+	if err := h.handshake.Answer(payload, h.send); err != nil {
+		return h.fail(err)
+	}
+
+	h.transition(StateConnecting, nil)
+
+	return nil
+}
+
+func (h *Handler) handleBegin(payload domain.BeginFramePayload) error {
+	if state := h.State(); state != StateConnecting {
+		return fmt.Errorf("%w: Begin frame in state %s", ErrUnexpectedFrame, state)
+	}
+
+	h.mu.Lock()
+	h.session = Session{
+		RemoteChannel:  payload.GetRemoteChannel(),
+		NextOutgoingID: payload.GetNextOutgoingID(),
+		IncomingWindow: payload.GetIncomingWindow(),
+		OutgoingWindow: payload.GetOutgoingWindow(),
+	}
+	if containerID := payload.GetContainerID(); containerID != "" {
+		h.containerID = containerID
+	}
+	h.mu.Unlock()
+
+	h.transition(StateConnected, nil)
+
+	return nil
+}
+
+// handleError fail the handshake, the Error frames received once connected are handed to the error handler.
+func (h *Handler) handleError(payload domain.ErrorFramePayload) {
+	switch h.State() {
+	case StateConnected, StateClosing:
+		if h.onError != nil {
+			h.onError(payload)
+		}
+	case StateConnecting:
+		if h.handshake.Tolerate(payload) {
+			return
+		}
+		h.transition(StateFailed, NewProtocolError(payload))
+	case StateOpening, StateDisconnected:
+		h.transition(StateFailed, NewProtocolError(payload))
+	}
+}
+
+func (h *Handler) handleClose(payload domain.CloseFramePayload) {
+	var err error
+	if h.State() != StateClosing {
+		err = &CloseError{Code: payload.GetCode(), Reason: payload.GetReason()}
+	}
+
+	h.transition(StateClosed, err)
+}
+
+// Close send a Close frame, the connection is closed once the broker acknowledge it.
+func (h *Handler) Close(code uint16, reason string) error {
+	if h.State() != StateConnected {
+		return nil
+	}
+
+	frame, err := frames.CreateCloseFrame(domain.DOFF4, code, reason)
+	if err != nil {
+		return err
+	}
+	if err := h.send(frame); err != nil {
+		return err
+	}
+
+	h.transition(StateClosing, nil)
+
+	return nil
+}
+
+func (h *Handler) fail(err error) error {
+	h.transition(StateFailed, err)
+	return err
+}
+
+func (h *Handler) transition(to State, err error) {
+	h.mu.Lock()
+	from := h.state
+	h.state = to
+	h.mu.Unlock()
+
+	change := StateChange{From: from, To: to, Err: err}
+	for _, handler := range h.onStateChange {
+		handler(change)
+	}
+}
+
+// State return the connection state.
+func (h *Handler) State() State {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.state
+}
+
+// SourceID return the client id assigned by the broker in the Open frame.
+func (h *Handler) SourceID() domain.ID {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.sourceID
+}
+
+// ContainerID return the container assigned by the broker.
+func (h *Handler) ContainerID() domain.ID {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.containerID
+}
+
+// Capabilities return the capabilities both peers support.
+func (h *Handler) Capabilities() domain.Capability {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.capabilities
+}
+
+// Session return the session announced by the broker in the Begin frame.
+func (h *Handler) Session() Session {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.session
+}
@@ -0,0 +1,266 @@
+package hqp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+type recorder struct {
+	frames      []domain.Frame
+	transitions []StateChange
+	err         error
+}
+
+func (r *recorder) send(frame domain.Frame) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.frames = append(r.frames, frame)
+	return nil
+}
+
+func (r *recorder) types() []domain.FrameType {
+	types := make([]domain.FrameType, 0, len(r.frames))
+	for _, frame := range r.frames {
+		types = append(types, frame.GetType())
+	}
+	return types
+}
+
+func (r *recorder) states() []State {
+	states := make([]State, 0, len(r.transitions))
+	for _, change := range r.transitions {
+		states = append(states, change.To)
+	}
+	return states
+}
+
+func newTestHandler(t *testing.T, opts ...Option) (*Handler, *recorder) {
+	t.Helper()
+
+	r := &recorder{}
+	opts = append(opts, WithStateHandler(func(change StateChange) {
+		r.transitions = append(r.transitions, change)
+	}))
+	h := NewHandler(r.send, opts...)
+	h.Reset()
+
+	return h, r
+}
+
+func openFrame(t *testing.T) domain.Frame {
+	t.Helper()
+
+	frame, err := frames.CreateOpenFrame(
+		domain.DOFF4,
+		"client-1",
+		"container-1",
+		[]domain.ProtocolVersion{domain.ProtocolVersion1},
+		domain.CapabilityLargeFrames|domain.CapabilityCompression,
+	)
+	require.NoError(t, err)
+
+	return frame
+}
+
+func beginFrame(t *testing.T) domain.Frame {
+	t.Helper()
+
+	frame, err := frames.CreateBeginFrame(domain.DOFF4, "client-1", "container-1", 1, 2, 3, 4)
+	require.NoError(t, err)
+
+	return frame
+}
+
+func errorFrame(t *testing.T, code domain.ErrorCode) domain.Frame {
+	t.Helper()
+
+	frame, err := frames.CreateErrorFrame(domain.DOFF4, uint16(code), "rejected", nil)
+	require.NoError(t, err)
+
+	return frame
+}
+
+func closeFrame(t *testing.T) domain.Frame {
+	t.Helper()
+
+	frame, err := frames.CreateCloseFrame(domain.DOFF4, domain.CloseCodeShutdown, "shutdown")
+	require.NoError(t, err)
+
+	return frame
+}
+
+func TestHandler_Handshake(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		frames     func(t *testing.T) []domain.Frame
+		wantSent   []domain.FrameType
+		wantStates []State
+		wantErr    error
+	}{
+		{
+			name:       "Open_Begin_Connected",
+			frames:     func(t *testing.T) []domain.Frame { return []domain.Frame{openFrame(t), beginFrame(t)} },
+			wantSent:   []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeConnect},
+			wantStates: []State{StateOpening, StateConnecting, StateConnected},
+		},
+		{
+			name:       "Credentials_Send_Auth",
+			opts:       []Option{WithCredentials("app", "secret")},
+			frames:     func(t *testing.T) []domain.Frame { return []domain.Frame{openFrame(t), beginFrame(t)} },
+			wantSent:   []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeAuth, domain.FrameTypeConnect},
+			wantStates: []State{StateOpening, StateConnecting, StateConnected},
+		},
+		{
+			name: "Credentials_Auth_Unsupported_Ignored",
+			opts: []Option{WithCredentials("app", "secret")},
+			frames: func(t *testing.T) []domain.Frame {
+				return []domain.Frame{openFrame(t), errorFrame(t, domain.ErrorCodeUnsupportedFrame), beginFrame(t)}
+			},
+			wantSent:   []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeAuth, domain.FrameTypeConnect},
+			wantStates: []State{StateOpening, StateConnecting, StateConnected},
+		},
+		{
+			name: "Unauthorized_Failed",
+			opts: []Option{WithCredentials("app", "wrong")},
+			frames: func(t *testing.T) []domain.Frame {
+				return []domain.Frame{openFrame(t), errorFrame(t, domain.ErrorCodeUnauthorized)}
+			},
+			wantSent:   []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeAuth, domain.FrameTypeConnect},
+			wantStates: []State{StateOpening, StateConnecting, StateFailed},
+			wantErr:    domain.ErrUnauthorized,
+		},
+		{
+			name: "Unsupported_Without_Credentials_Failed",
+			frames: func(t *testing.T) []domain.Frame {
+				return []domain.Frame{openFrame(t), errorFrame(t, domain.ErrorCodeUnsupportedFrame)}
+			},
+			wantSent:   []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeConnect},
+			wantStates: []State{StateOpening, StateConnecting, StateFailed},
+			wantErr:    domain.ErrUnsupportedFrameType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, r := newTestHandler(t, tt.opts...)
+			for _, frame := range tt.frames(t) {
+				require.NoError(t, h.HandleFrame(frame))
+			}
+
+			assert.Equal(t, tt.wantSent, r.types())
+			assert.Equal(t, tt.wantStates, r.states())
+
+			last := r.transitions[len(r.transitions)-1]
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, last.Err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, last.Err)
+			assert.Equal(t, domain.ID("client-1"), h.SourceID())
+			assert.Equal(t, domain.ID("container-1"), h.ContainerID())
+			assert.Equal(t, domain.CapabilityLargeFrames, h.Capabilities())
+			assert.Equal(t, Session{RemoteChannel: 1, NextOutgoingID: 2, IncomingWindow: 3, OutgoingWindow: 4}, h.Session())
+		})
+	}
+}
+
+func TestHandler_Unexpected_Frames(t *testing.T) {
+	t.Parallel()
+
+	h, r := newTestHandler(t)
+	assert.ErrorIs(t, h.HandleFrame(beginFrame(t)), ErrUnexpectedFrame)
+
+	require.NoError(t, h.HandleFrame(openFrame(t)))
+	require.NoError(t, h.HandleFrame(beginFrame(t)))
+	assert.ErrorIs(t, h.HandleFrame(openFrame(t)), ErrUnexpectedFrame)
+	assert.ErrorIs(t, h.HandleFrame(beginFrame(t)), ErrUnexpectedFrame)
+
+	connect, err := frames.CreateConnectFrame(domain.DOFF4, "client-1", ClientVersion, 30, domain.ProtocolVersion1, Capabilities, 0)
+	require.NoError(t, err)
+	assert.ErrorIs(t, h.HandleFrame(connect), ErrUnexpectedFrame)
+
+	assert.Equal(t, StateConnected, h.State())
+	assert.Len(t, r.frames, 2)
+}
+
+func TestHandler_Send_Failure(t *testing.T) {
+	t.Parallel()
+
+	h, r := newTestHandler(t)
+	r.err = errors.New("broken pipe")
+
+	assert.ErrorIs(t, h.HandleFrame(openFrame(t)), r.err)
+	assert.Equal(t, StateFailed, h.State())
+}
+
+func TestHandler_Connected(t *testing.T) {
+	t.Parallel()
+
+	var (
+		messages []domain.MessageFramePayload
+		confirms []domain.ConfirmFramePayload
+		errs     []domain.ErrorFramePayload
+	)
+	h, r := newTestHandler(t,
+		WithMessageHandler(func(payload domain.MessageFramePayload) { messages = append(messages, payload) }),
+		WithConfirmHandler(func(payload domain.ConfirmFramePayload) { confirms = append(confirms, payload) }),
+		WithErrorHandler(func(payload domain.ErrorFramePayload) { errs = append(errs, payload) }),
+	)
+	require.NoError(t, h.HandleFrame(openFrame(t)))
+	require.NoError(t, h.HandleFrame(beginFrame(t)))
+
+	message, err := frames.CreateMessageFrame(domain.DOFF4, "orders.created", "msg-1", []byte("1"), nil)
+	require.NoError(t, err)
+	require.NoError(t, h.HandleFrame(message))
+	confirm, err := frames.CreateConfirmFrame(domain.DOFF4, "msg-1")
+	require.NoError(t, err)
+	require.NoError(t, h.HandleFrame(confirm))
+	require.NoError(t, h.HandleFrame(errorFrame(t, domain.ErrorCodeForbidden)))
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, "orders.created", messages[0].GetTopic())
+	require.Len(t, confirms, 1)
+	assert.Equal(t, domain.ID("msg-1"), confirms[0].GetMessageID())
+	require.Len(t, errs, 1)
+	assert.Equal(t, uint16(domain.ErrorCodeForbidden), errs[0].GetErrorCode())
+	assert.Equal(t, StateConnected, h.State())
+
+	t.Run("Close_Requested", func(t *testing.T) {
+		require.NoError(t, h.Close(domain.CloseCodeNormal, "bye"))
+		assert.Equal(t, StateClosing, h.State())
+		assert.Equal(t, domain.FrameTypeClose, r.frames[len(r.frames)-1].GetType())
+
+		require.NoError(t, h.HandleFrame(closeFrame(t)))
+		assert.Equal(t, StateClosed, h.State())
+		assert.NoError(t, r.transitions[len(r.transitions)-1].Err)
+
+		require.NoError(t, h.Close(domain.CloseCodeNormal, "bye"))
+		assert.Equal(t, domain.FrameTypeClose, r.frames[len(r.frames)-1].GetType())
+	})
+
+	t.Run("Close_By_Broker", func(t *testing.T) {
+		h, r := newTestHandler(t)
+		require.NoError(t, h.HandleFrame(openFrame(t)))
+		require.NoError(t, h.HandleFrame(beginFrame(t)))
+		require.NoError(t, h.HandleFrame(closeFrame(t)))
+
+		var closeErr *CloseError
+		require.ErrorAs(t, r.transitions[len(r.transitions)-1].Err, &closeErr)
+		assert.Equal(t, domain.CloseCodeShutdown, closeErr.Code)
+
+		h.Disconnected(nil)
+		assert.Equal(t, StateDisconnected, h.State())
+	})
+}
@@ -0,0 +1,351 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 10 * time.Millisecond
+
+	// detailMessageID is the Error frame detail holding the ID of a rejected message.
+	detailMessageID = "message_id"
+)
+
+var (
+	// ErrConfirmsUnsupported is returned by PublishAndWait when the broker did not negotiate the publish confirms.
+	ErrConfirmsUnsupported = errors.New("broker does not support publish confirms")
+	// ErrConfirmLost is reported for the messages awaiting a confirmation when the connection is lost,
+	// the broker may have routed them.
+	ErrConfirmLost = errors.New("connection lost before the broker confirmed the message")
+	// ErrPublisherClosed is reported for the messages published to a closed BatchPublisher.
+	ErrPublisherClosed = errors.New("batch publisher closed")
+)
+
+// PublishAndWait publish the message through the publish middlewares and block until the broker confirm
+// it routed the message. A rejected message return the ProtocolError of the broker, e.g. errors.Is(err, domain.ErrForbidden).
+func (c *Client) PublishAndWait(ctx context.Context, topic string, content []byte, headers map[string]string) error {
+	msg := c.newOutgoing(topic, content, headers)
+	confirmed := make(chan error, 1)
+	msg.confirm = func(err error) {
+		confirmed <- err
+	}
+
+	if err := c.publish(ctx, msg); err != nil {
+		c.forgetConfirm(msg.ID)
+		return err
+	}
+
+	select {
+	case err := <-confirmed:
+		return err
+	case <-ctx.Done():
+		c.forgetConfirm(msg.ID)
+		return ctx.Err()
+	}
+}
+
+// expectConfirm register the callback receiving the outcome of the message,
+// it return false when the session did not negotiate the publish confirms.
+func (c *Client) expectConfirm(id domain.ID, confirm func(err error)) bool {
+	if !c.protocol.Capabilities().Has(domain.CapabilityConfirms) {
+		return false
+	}
+
+	c.confirmMu.Lock()
+	defer c.confirmMu.Unlock()
+
+	c.confirms[id] = &pendingConfirm{confirm: confirm}
+	return true
+}
+
+func (c *Client) forgetConfirm(id domain.ID) {
+	c.confirmMu.Lock()
+	defer c.confirmMu.Unlock()
+
+	delete(c.confirms, id)
+}
+
+// completeConfirm hand the outcome to the callback of the message, it return false when no callback await it.
+func (c *Client) completeConfirm(id domain.ID, err error) bool {
+	c.confirmMu.Lock()
+	pending, ok := c.confirms[id]
+	delete(c.confirms, id)
+	c.confirmMu.Unlock()
+
+	if ok {
+		pending.confirm(err)
+	}
+
+	return ok
+}
+
+// lostConnections return the number of connections lost so far, to tell on which one a frame is written.
+func (c *Client) lostConnections() uint64 {
+	c.confirmMu.Lock()
+	defer c.confirmMu.Unlock()
+
+	return c.lostConns
+}
+
+// confirmWritten await the confirmations of the frames written once lostConns connections were lost,
+// the frames written on a connection lost meanwhile are failed with ErrConfirmLost.
+func (c *Client) confirmWritten(lostConns uint64, frames []outgoingFrame) {
+	var lost []func(err error)
+
+	c.confirmMu.Lock()
+	for _, frame := range frames {
+		pending, ok := c.confirms[frame.id]
+		if !ok {
+			continue
+		}
+		if lostConns != c.lostConns {
+			lost = append(lost, pending.confirm)
+			delete(c.confirms, frame.id)
+			continue
+		}
+		pending.written = true
+	}
+	c.confirmMu.Unlock()
+
+	for _, confirm := range lost {
+		confirm(ErrConfirmLost)
+	}
+}
+
+// failConfirms hand the error to the callbacks of the messages written on the lost connection,
+// the messages still buffered keep awaiting their confirmation unless all is set.
+func (c *Client) failConfirms(err error, all bool) {
+	var failed []func(err error)
+
+	c.confirmMu.Lock()
+	c.lostConns++
+	for id, pending := range c.confirms {
+		if all || pending.written {
+			failed = append(failed, pending.confirm)
+			delete(c.confirms, id)
+		}
+	}
+	c.confirmMu.Unlock()
+
+	for _, confirm := range failed {
+		confirm(err)
+	}
+}
+
+// pendingConfirm is a published message awaiting its broker confirmation.
+type pendingConfirm struct {
+	confirm func(err error)
+	// written is set once the frame is written to the connection, a buffered frame can not be lost.
+	written bool
+}
+
+// PublishResult report the outcome of a message published by a BatchPublisher.
+type PublishResult struct {
+	MessageID domain.ID
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func newPublishResult(id domain.ID) *PublishResult {
+	return &PublishResult{
+		MessageID: id,
+		done:      make(chan struct{}),
+	}
+}
+
+// Done is closed once the outcome of the message is known.
+func (r *PublishResult) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err return the outcome of the message, nil until Done is closed.
+func (r *PublishResult) Err() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+		return nil
+	}
+}
+
+// Wait block until the outcome of the message is known.
+func (r *PublishResult) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *PublishResult) complete(err error) {
+	r.once.Do(func() {
+		r.err = err
+		close(r.done)
+	})
+}
+
+// BatchOption configure a BatchPublisher.
+type BatchOption func(*BatchPublisher)
+
+// WithBatchSize set the number of messages written at once, the client batch size by default.
+func WithBatchSize(size int) BatchOption {
+	return func(b *BatchPublisher) {
+		b.size = size
+	}
+}
+
+// WithFlushInterval set how long a batch not full linger before being written, the client flush interval by default.
+func WithFlushInterval(interval time.Duration) BatchOption {
+	return func(b *BatchPublisher) {
+		b.flushInterval = interval
+	}
+}
+
+// batched is a message queued in a batch.
+type batched struct {
+	id       domain.ID
+	data     []byte
+	complete func(err error)
+	// confirmed is set when the outcome is the broker confirmation rather than the write.
+	confirmed bool
+}
+
+// BatchPublisher coalesce the published messages into a single write once the batch is full,
+// or once the flush interval elapsed since the first message of the batch.
+type BatchPublisher struct {
+	client        *Client
+	publish       PublishFunc
+	size          int
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	batch  []batched
+	timer  *time.Timer
+	closed bool
+}
+
+// NewBatchPublisher return a publisher batching the messages with the client batch size and flush interval,
+// see config performance.batch_size and performance.flush_interval.
+func (c *Client) NewBatchPublisher(opts ...BatchOption) *BatchPublisher {
+	b := &BatchPublisher{
+		client:        c,
+		size:          c.batchSize,
+		flushInterval: c.flushInterval,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.size <= 0 {
+		b.size = defaultBatchSize
+	}
+	if b.flushInterval <= 0 {
+		b.flushInterval = defaultFlushInterval
+	}
+	b.publish = chainPublish(b.enqueue, c.publishMiddlewares)
+
+	return b
+}
+
+// Publish queue the message in the current batch through the publish middlewares.
+// The result is completed by the broker confirmation when the session negotiated the publish confirms,
+// once the batch is written otherwise.
+func (b *BatchPublisher) Publish(ctx context.Context, topic string, content []byte, headers map[string]string) *PublishResult {
+	msg := b.client.newOutgoing(topic, content, headers)
+	result := newPublishResult(msg.ID)
+	msg.confirm = result.complete
+
+	if err := b.publish(ctx, msg); err != nil {
+		b.client.forgetConfirm(msg.ID)
+		result.complete(err)
+	}
+
+	return result
+}
+
+// Flush write the current batch.
+func (b *BatchPublisher) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flush()
+}
+
+// Close write the current batch, the messages published afterward fail with ErrPublisherClosed.
+func (b *BatchPublisher) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flush()
+	b.closed = true
+}
+
+// enqueue add the message to the current batch, it end the publish chain.
+func (b *BatchPublisher) enqueue(ctx context.Context, msg *Message) error {
+	data, err := b.client.encode(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrPublisherClosed
+	}
+
+	entry := batched{id: msg.ID, data: data, complete: msg.confirm}
+	if entry.complete == nil {
+		// a middleware replaced the message, its outcome is not reported.
+		entry.complete = func(error) {}
+	}
+	// the confirmation is expected before the frame is written, the broker could answer first.
+	entry.confirmed = b.client.expectConfirm(msg.ID, entry.complete)
+	b.batch = append(b.batch, entry)
+
+	if len(b.batch) >= b.size {
+		b.flush()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.flushInterval, b.Flush)
+	}
+
+	return nil
+}
+
+// flush write the batch, the writes are serialized to keep the publish order.
+func (b *BatchPublisher) flush() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.batch) == 0 {
+		return
+	}
+
+	batch := b.batch
+	b.batch = nil
+
+	frames := make([]outgoingFrame, len(batch))
+	for i, entry := range batch {
+		frames[i] = outgoingFrame{id: entry.id, data: entry.data}
+	}
+
+	err := b.client.sendOrBuffer(frames...)
+	for _, entry := range batch {
+		switch {
+		case err != nil:
+			b.client.forgetConfirm(entry.id)
+			entry.complete(err)
+		case !entry.confirmed:
+			entry.complete(nil)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+	"github.com/hoppermq/hopper/pkg/embedded"
+)
+
+// countingConn count the writes on the broker connection.
+type countingConn struct {
+	net.Conn
+	writes *atomic.Int32
+}
+
+func (c countingConn) Write(b []byte) (int, error) {
+	c.writes.Add(1)
+	return c.Conn.Write(b)
+}
+
+// startGuardedBroker start a broker forbidding the publishes on the "orders.denied" topic.
+func startGuardedBroker(t *testing.T) *embedded.Broker {
+	t.Helper()
+
+	authorizer := mocks.NewMockAuthorizer(t)
+	authorizer.EXPECT().Authorize(mock.Anything, "orders.denied", domain.PermissionPublish).Return(domain.ErrForbidden).Maybe()
+	authorizer.EXPECT().Authorize(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return startBroker(t, embedded.WithAuthorizer(authorizer))
+}
+
+func TestClient_PublishAndWait(t *testing.T) {
+	t.Parallel()
+
+	broker := startGuardedBroker(t)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker)
+	ctx := context.Background()
+
+	received := make(chan *Message, 10)
+	_, err := consumer.Subscribe(ctx, "orders.*", collect(received))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		topic   string
+		wantErr error
+	}{
+		{
+			name:  "Confirmed",
+			topic: "orders.created",
+		},
+		{
+			name:    "Rejected",
+			topic:   "orders.denied",
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:    "Invalid_Topic",
+			topic:   "orders.*",
+			wantErr: ErrInvalidTopic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := producer.PublishAndWait(ctx, tt.topic, []byte(tt.name), nil)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte(tt.name), receive(t, received).Content)
+		})
+	}
+
+	var protocolErr *ProtocolError
+	require.ErrorAs(t, producer.PublishAndWait(ctx, "orders.denied", nil, nil), &protocolErr)
+	assert.NotEmpty(t, protocolErr.Details[detailMessageID])
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, producer.PublishAndWait(canceled, "orders.created", nil, nil), context.Canceled)
+	assert.Zero(t, producer.Stats().PendingConfirms)
+}
+
+func TestBatchPublisher(t *testing.T) {
+	t.Parallel()
+
+	broker := startGuardedBroker(t)
+	var writes atomic.Int32
+	producer := startClient(t, broker, WithDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := broker.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return countingConn{Conn: conn, writes: &writes}, nil
+	}))
+	consumer := startClient(t, broker)
+	ctx := context.Background()
+
+	received := make(chan *Message, 10)
+	_, err := consumer.Subscribe(ctx, "orders.*", collect(received))
+	require.NoError(t, err)
+
+	t.Run("Full_Batch", func(t *testing.T) {
+		publisher := producer.NewBatchPublisher(WithBatchSize(3), WithFlushInterval(time.Hour))
+		before := writes.Load()
+
+		topics := []string{"orders.created", "orders.denied", "orders.paid"}
+		results := make([]*PublishResult, 0, len(topics))
+		for _, topic := range topics {
+			results = append(results, publisher.Publish(ctx, topic, []byte(topic), nil))
+		}
+
+		assert.Equal(t, before+1, writes.Load(), "the batch is written at once")
+		require.NoError(t, results[0].Wait(ctx))
+		assert.ErrorIs(t, results[1].Wait(ctx), domain.ErrForbidden)
+		require.NoError(t, results[2].Wait(ctx))
+
+		assert.Equal(t, "orders.created", receive(t, received).Topic)
+		assert.Equal(t, "orders.paid", receive(t, received).Topic)
+	})
+
+	t.Run("Flush_Interval", func(t *testing.T) {
+		publisher := producer.NewBatchPublisher(WithBatchSize(10), WithFlushInterval(20*time.Millisecond))
+
+		results := make([]*PublishResult, 0, 2)
+		for i := range 2 {
+			results = append(results, publisher.Publish(ctx, "orders.created", []byte(strconv.Itoa(i)), nil))
+		}
+
+		for i, result := range results {
+			require.NoError(t, result.Wait(ctx))
+			assert.Equal(t, []byte(strconv.Itoa(i)), receive(t, received).Content)
+		}
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		publisher := producer.NewBatchPublisher(WithFlushInterval(time.Hour))
+		pending := publisher.Publish(ctx, "orders.created", nil, nil)
+		publisher.Close()
+
+		require.NoError(t, pending.Wait(ctx))
+		receive(t, received)
+		assert.ErrorIs(t, publisher.Publish(ctx, "orders.created", nil, nil).Wait(ctx), ErrPublisherClosed)
+		assert.ErrorIs(t, publisher.Publish(ctx, "orders.*", nil, nil).Wait(ctx), ErrInvalidTopic)
+	})
+}
+
+func TestClient_PublishAndWait_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	ctx := context.Background()
+
+	dialer := newFlakyDialer(broker.DialContext)
+	c := startClient(t, broker,
+		WithDialer(dialer.DialContext),
+		WithReconnectInterval(20*time.Millisecond),
+	)
+
+	dialer.disconnect()
+	require.Eventually(t, func() bool { return c.State() == hqp.StateDisconnected }, 2*time.Second, 10*time.Millisecond)
+
+	confirmed := make(chan error, 1)
+	go func() {
+		confirmed <- c.PublishAndWait(ctx, "orders.created", nil, nil)
+	}()
+	require.Eventually(t, func() bool { return c.Stats().OutboundBuffered == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Zero(t, c.Stats().MessagesPublished, "A buffered message should not be counted as published")
+
+	// the buffered message is not lost with the connection, it is confirmed once sent on the next one.
+	dialer.reconnect()
+	require.NoError(t, receive(t, confirmed))
+	assert.Equal(t, uint64(1), c.Stats().MessagesPublished)
+}
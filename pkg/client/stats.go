@@ -0,0 +1,291 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+)
+
+// openMetricsContentType is the content type of the metrics served by MetricsHandler.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// handlerLatencyBounds are the upper bounds of the handler latency buckets.
+var handlerLatencyBounds = [...]time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Stats is a snapshot of the client activity, the counters grow from the creation of the client.
+type Stats struct {
+	State   hqp.State
+	Health  tcp.HealthStatus
+	Circuit tcp.CircuitState
+
+	// MessagesPublished count the messages written to the broker, the buffered ones once flushed.
+	MessagesPublished uint64
+	// MessagesConfirmed and MessagesRejected count the publish outcomes reported by the broker.
+	MessagesConfirmed uint64
+	MessagesRejected  uint64
+	MessagesReceived  uint64
+	// MessagesAcked and MessagesNacked count the settled deliveries, a dropped redelivered message is nacked.
+	// MessagesRedelivered count the messages the client delivered again to their subscription.
+	MessagesAcked       uint64
+	MessagesNacked      uint64
+	MessagesRedelivered uint64
+
+	BytesSent     uint64
+	BytesReceived uint64
+
+	// Reconnects count the connections to the broker following the first one.
+	Reconnects       uint64
+	ConnectionErrors uint64
+
+	// InFlightAcks count the delivered messages not yet settled, bounded by the prefetch.
+	InFlightAcks int
+	// PendingConfirms count the published messages awaiting the broker confirmation.
+	PendingConfirms int
+	// OutboundBuffered count the messages published while disconnected, waiting for the session to be restored.
+	OutboundBuffered int
+
+	HandlerLatency LatencyStats
+}
+
+// LatencyStats is a histogram of durations.
+type LatencyStats struct {
+	Count uint64
+	Sum   time.Duration
+	// Buckets count the durations up to each bound, they are cumulative and sorted by bound.
+	Buckets []LatencyBucket
+}
+
+// LatencyBucket count the durations up to its upper bound.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// StatsHandler receive the client stats, see WithStatsHandler.
+type StatsHandler func(stats Stats)
+
+// WithStatsHandler report the client stats to the handler every interval while the client is running.
+func WithStatsHandler(interval time.Duration, handler StatsHandler) Option {
+	return func(c *Client) {
+		c.statsInterval = interval
+		c.statsHandler = handler
+	}
+}
+
+// counters hold the activity of the client.
+type counters struct {
+	published   atomic.Uint64
+	confirmed   atomic.Uint64
+	rejected    atomic.Uint64
+	received    atomic.Uint64
+	acked       atomic.Uint64
+	nacked      atomic.Uint64
+	redelivered atomic.Uint64
+	// inFlight mirror the credits held by the deliveries, the credits channel is replaced when the consumers start.
+	inFlight atomic.Int64
+
+	handlerLatency histogram
+}
+
+// histogram count the durations in the handler latency buckets, the last bucket is unbounded.
+type histogram struct {
+	buckets [len(handlerLatencyBounds) + 1]atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(handlerLatencyBounds) && d > handlerLatencyBounds[i] {
+		i++
+	}
+	h.buckets[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() LatencyStats {
+	stats := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Buckets: make([]LatencyBucket, len(handlerLatencyBounds)),
+	}
+
+	var cumulative uint64
+	for i, bound := range handlerLatencyBounds {
+		cumulative += h.buckets[i].Load()
+		stats.Buckets[i] = LatencyBucket{UpperBound: bound, Count: cumulative}
+	}
+
+	return stats
+}
+
+// Stats return a snapshot of the client activity.
+func (c *Client) Stats() Stats {
+	health := c.transport.GetHealthMetrics()
+
+	c.confirmMu.Lock()
+	pendingConfirms := len(c.confirms)
+	c.confirmMu.Unlock()
+
+	c.outMu.Lock()
+	buffered := len(c.outbound)
+	c.outMu.Unlock()
+
+	return Stats{
+		State:   c.protocol.State(),
+		Health:  health.Status,
+		Circuit: health.CircuitState,
+
+		MessagesPublished:   c.counters.published.Load(),
+		MessagesConfirmed:   c.counters.confirmed.Load(),
+		MessagesRejected:    c.counters.rejected.Load(),
+		MessagesReceived:    c.counters.received.Load(),
+		MessagesAcked:       c.counters.acked.Load(),
+		MessagesNacked:      c.counters.nacked.Load(),
+		MessagesRedelivered: c.counters.redelivered.Load(),
+
+		BytesSent:     uint64(health.BytesSent),
+		BytesReceived: uint64(health.BytesReceived),
+
+		Reconnects:       uint64(max(health.TotalConnections-1, 0)),
+		ConnectionErrors: uint64(health.TotalErrors),
+
+		InFlightAcks:     int(c.counters.inFlight.Load()),
+		PendingConfirms:  pendingConfirms,
+		OutboundBuffered: buffered,
+
+		HandlerLatency: c.counters.handlerLatency.snapshot(),
+	}
+}
+
+// reportStats report the stats to the stats handler every interval until the client is stopped.
+func (c *Client) reportStats(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.statsHandler(c.Stats())
+		}
+	}
+}
+
+// WriteMetrics write the client stats in the OpenMetrics text format, without the final "# EOF" line
+// to let the caller append the metrics of the service.
+func (c *Client) WriteMetrics(w io.Writer) error {
+	stats := c.Stats()
+	m := &metricsWriter{w: w}
+
+	m.family("hopper_client_connected", "gauge", "Whether the session with the broker is established.")
+	m.sample("hopper_client_connected", "", boolMetric(stats.State == hqp.StateConnected))
+	m.family("hopper_client_circuit_state", "gauge", "State of the connection circuit breaker.")
+	for _, state := range []tcp.CircuitState{tcp.CircuitClosed, tcp.CircuitOpen, tcp.CircuitHalfOpen} {
+		m.sample("hopper_client_circuit_state", `state="`+state.String()+`"`, boolMetric(stats.Circuit == state))
+	}
+
+	m.counter("hopper_client_messages_published", "Messages published to the broker.", stats.MessagesPublished)
+	m.counter("hopper_client_messages_confirmed", "Published messages confirmed by the broker.", stats.MessagesConfirmed)
+	m.counter("hopper_client_messages_rejected", "Published messages rejected by the broker.", stats.MessagesRejected)
+	m.counter("hopper_client_messages_received", "Messages received from the broker.", stats.MessagesReceived)
+	m.family("hopper_client_messages_settled", "counter", "Delivered messages settled by outcome.")
+	m.sample("hopper_client_messages_settled_total", `outcome="ack"`, strconv.FormatUint(stats.MessagesAcked, 10))
+	m.sample("hopper_client_messages_settled_total", `outcome="nack"`, strconv.FormatUint(stats.MessagesNacked, 10))
+	m.sample("hopper_client_messages_settled_total", `outcome="redeliver"`, strconv.FormatUint(stats.MessagesRedelivered, 10))
+	m.counter("hopper_client_sent_bytes", "Bytes of the frames written to the broker.", stats.BytesSent)
+	m.counter("hopper_client_received_bytes", "Bytes of the frames read from the broker.", stats.BytesReceived)
+	m.counter("hopper_client_reconnects", "Connections to the broker following the first one.", stats.Reconnects)
+	m.counter("hopper_client_connection_errors", "Errors of the broker connection.", stats.ConnectionErrors)
+
+	m.gauge("hopper_client_in_flight_acks", "Delivered messages not yet settled.", stats.InFlightAcks)
+	m.gauge("hopper_client_pending_confirms", "Published messages awaiting the broker confirmation.", stats.PendingConfirms)
+	m.gauge("hopper_client_outbound_buffered", "Messages published while disconnected.", stats.OutboundBuffered)
+
+	m.family("hopper_client_handler_duration_seconds", "histogram", "Duration of the message handlers.")
+	for _, bucket := range stats.HandlerLatency.Buckets {
+		le := strconv.FormatFloat(bucket.UpperBound.Seconds(), 'g', -1, 64)
+		m.sample("hopper_client_handler_duration_seconds_bucket", `le="`+le+`"`, strconv.FormatUint(bucket.Count, 10))
+	}
+	count := strconv.FormatUint(stats.HandlerLatency.Count, 10)
+	m.sample("hopper_client_handler_duration_seconds_bucket", `le="+Inf"`, count)
+	m.sample("hopper_client_handler_duration_seconds_sum", "", strconv.FormatFloat(stats.HandlerLatency.Sum.Seconds(), 'g', -1, 64))
+	m.sample("hopper_client_handler_duration_seconds_count", "", count)
+
+	return m.err
+}
+
+// MetricsHandler serve the client stats in the OpenMetrics text format.
+func (c *Client) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", openMetricsContentType)
+		if err := c.WriteMetrics(w); err != nil {
+			return
+		}
+		_, _ = io.WriteString(w, "# EOF\n")
+	})
+}
+
+// metricsWriter write the OpenMetrics lines, it keep the first write error.
+type metricsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *metricsWriter) family(name, metricType, help string) {
+	m.printf("# TYPE %s %s\n# HELP %s %s\n", name, metricType, name, help)
+}
+
+func (m *metricsWriter) sample(name, labels, value string) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	m.printf("%s%s %s\n", name, labels, value)
+}
+
+func (m *metricsWriter) counter(name, help string, value uint64) {
+	m.family(name, "counter", help)
+	m.sample(name+"_total", "", strconv.FormatUint(value, 10))
+}
+
+func (m *metricsWriter) gauge(name, help string, value int) {
+	m.family(name, "gauge", help)
+	m.sample(name, "", strconv.Itoa(value))
+}
+
+func (m *metricsWriter) printf(format string, args ...any) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, format, args...)
+}
+
+func boolMetric(value bool) string {
+	if value {
+		return "1"
+	}
+
+	return "0"
+}
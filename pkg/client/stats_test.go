@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/client/protocol/hqp"
+	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+func TestClient_Stats(t *testing.T) {
+	t.Parallel()
+
+	broker := startGuardedBroker(t)
+	reports := make(chan Stats, 1)
+	producer := startClient(t, broker)
+	consumer := startClient(t, broker, WithStatsHandler(10*time.Millisecond, func(stats Stats) {
+		select {
+		case reports <- stats:
+		default:
+		}
+	}))
+	ctx := context.Background()
+
+	handled := make(chan *Message, 10)
+	_, err := consumer.Subscribe(ctx, "orders.*", func(_ context.Context, msg *Message) error {
+		handled <- msg
+		if string(msg.Content) == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, producer.PublishAndWait(ctx, "orders.created", []byte("ok"), nil))
+		select {
+		case <-handled:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, producer.PublishAndWait(ctx, "orders.created", []byte("fail"), nil))
+	for range 1 + defaultMaxRedeliveries {
+		receive(t, handled)
+	}
+	assert.ErrorIs(t, producer.PublishAndWait(ctx, "orders.denied", nil, nil), domain.ErrForbidden)
+
+	published := producer.Stats()
+	assert.Equal(t, hqp.StateConnected, published.State)
+	assert.Equal(t, tcp.StatusConnected, published.Health)
+	assert.Equal(t, tcp.CircuitClosed, published.Circuit)
+	assert.Equal(t, published.MessagesPublished, published.MessagesConfirmed+published.MessagesRejected)
+	assert.Equal(t, uint64(1), published.MessagesRejected)
+	assert.Zero(t, published.PendingConfirms)
+	assert.Positive(t, published.BytesSent)
+	assert.Positive(t, published.BytesReceived)
+
+	require.Eventually(t, func() bool {
+		return consumer.Stats().MessagesNacked == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	consumed := consumer.Stats()
+	assert.Equal(t, published.MessagesConfirmed, consumed.MessagesReceived)
+	assert.Equal(t, consumed.MessagesReceived-1, consumed.MessagesAcked)
+	assert.Equal(t, uint64(defaultMaxRedeliveries), consumed.MessagesRedelivered)
+	assert.Zero(t, consumed.InFlightAcks)
+	assert.Zero(t, consumed.Reconnects)
+	assert.Equal(t, consumed.MessagesAcked+consumed.MessagesNacked+consumed.MessagesRedelivered, consumed.HandlerLatency.Count)
+	require.Len(t, consumed.HandlerLatency.Buckets, len(handlerLatencyBounds))
+	last := consumed.HandlerLatency.Buckets[len(handlerLatencyBounds)-1]
+	assert.Equal(t, 5*time.Second, last.UpperBound)
+	assert.Equal(t, consumed.HandlerLatency.Count, last.Count)
+
+	report := receive(t, reports)
+	assert.Equal(t, hqp.StateConnected, report.State)
+}
+
+func TestClient_Stats_While_Starting(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	c := newClient(broker, WithPrefetch(8))
+
+	// the stats are read while Run size the credits, the race detector report an unguarded read.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for c.Prefetch() == 0 {
+			_ = c.Stats()
+		}
+	}()
+
+	require.NoError(t, c.Run(context.Background()))
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+	receive(t, done)
+
+	assert.Equal(t, 8, c.Prefetch())
+	assert.Zero(t, c.Stats().InFlightAcks)
+}
+
+func TestHistogram(t *testing.T) {
+	t.Parallel()
+
+	var h histogram
+	for _, d := range []time.Duration{time.Millisecond, 2 * time.Millisecond, 30 * time.Millisecond, time.Minute} {
+		h.observe(d)
+	}
+
+	stats := h.snapshot()
+	assert.Equal(t, uint64(4), stats.Count)
+	assert.Equal(t, time.Minute+33*time.Millisecond, stats.Sum)
+	assert.Equal(t, LatencyBucket{UpperBound: time.Millisecond, Count: 1}, stats.Buckets[0])
+	assert.Equal(t, LatencyBucket{UpperBound: 5 * time.Millisecond, Count: 2}, stats.Buckets[1])
+	assert.Equal(t, LatencyBucket{UpperBound: 50 * time.Millisecond, Count: 3}, stats.Buckets[4])
+	assert.Equal(t, LatencyBucket{UpperBound: 5 * time.Second, Count: 3}, stats.Buckets[len(stats.Buckets)-1])
+}
+
+func TestClient_MetricsHandler(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	c := startClient(t, broker)
+	require.NoError(t, c.PublishAndWait(context.Background(), "orders.created", nil, nil))
+
+	rec := httptest.NewRecorder()
+	c.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, openMetricsContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE hopper_client_connected gauge\n",
+		"hopper_client_connected 1\n",
+		`hopper_client_circuit_state{state="closed"} 1` + "\n",
+		`hopper_client_circuit_state{state="open"} 0` + "\n",
+		"# TYPE hopper_client_messages_published counter\n",
+		"hopper_client_messages_published_total 1\n",
+		"hopper_client_messages_confirmed_total 1\n",
+		`hopper_client_messages_settled_total{outcome="ack"} 0` + "\n",
+		"hopper_client_in_flight_acks 0\n",
+		"# TYPE hopper_client_handler_duration_seconds histogram\n",
+		`hopper_client_handler_duration_seconds_bucket{le="0.001"} 0` + "\n",
+		`hopper_client_handler_duration_seconds_bucket{le="+Inf"} 0` + "\n",
+		"hopper_client_handler_duration_seconds_count 0\n",
+	} {
+		assert.Contains(t, body, line)
+	}
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"), "the exposition end with # EOF")
+}
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// Subscription is the subscription of a handler to a topic pattern.
+type Subscription struct {
+	ID    domain.ID
+	Topic string
+
+	client  *Client
+	handler MessageHandler
+}
+
+// Unsubscribe stop the delivery of the messages to the handler, it can be called more than once.
+func (s *Subscription) Unsubscribe() error {
+	return s.client.unsubscribe(s)
+}
@@ -1,7 +1,10 @@
 package tcp
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -11,6 +14,7 @@ import (
 	"time"
 
 	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
 )
 
 type HealthStatus int32
@@ -23,6 +27,24 @@ const (
 	StatusFailed
 )
 
+// String return the name of the status.
+func (s HealthStatus) String() string {
+	switch s {
+	case StatusDisconnected:
+		return "disconnected"
+	case StatusConnecting:
+		return "connecting"
+	case StatusConnected:
+		return "connected"
+	case StatusDegraded:
+		return "degraded"
+	case StatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
 type CircuitState int32
 
 const (
@@ -31,6 +53,34 @@ const (
 	CircuitHalfOpen
 )
 
+// String return the name of the circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// HealthMetrics is a snapshot of the health of the broker connection.
+type HealthMetrics struct {
+	Status            HealthStatus
+	LastConnected     time.Time
+	ConsecutiveErrors int32
+	TotalConnections  int64
+	TotalErrors       int64
+	CircuitState      CircuitState
+	CircuitFailures   int32
+	// BytesSent and BytesReceived count the encoded frames written to and read from the broker.
+	BytesSent     int64
+	BytesReceived int64
+}
+
 // Client  provides a production-grade TCP client service with health monitoring and auto-reconnection
 type Client struct {
 	conn   net.Conn
@@ -40,6 +90,7 @@ type Client struct {
 	config struct {
 		port         int
 		address      string
+		addresses    []string
 		maxRetries   int
 		maxRetryWait time.Duration
 		retryDelay   time.Duration
@@ -49,8 +100,14 @@ type Client struct {
 		circuitFailures     int
 		circuitTimeout      time.Duration
 		reconnectInterval   time.Duration
+
+		tlsConfig *tls.Config
+		dialer    Dialer
 	}
 
+	// broker is the index of the address to dial first, the last broker reached.
+	broker int
+
 	health struct {
 		status            int32
 		lastConnected     time.Time
@@ -58,6 +115,8 @@ type Client struct {
 		consecutiveErrors int32
 		totalConnections  int64
 		totalErrors       int64
+		bytesSent         int64
+		bytesReceived     int64
 	}
 
 	circuit struct {
@@ -67,6 +126,12 @@ type Client struct {
 		nextRetry   time.Time
 	}
 
+	frameHandler      FrameHandler
+	connectHandler    func()
+	disconnectHandler func(err error)
+	writeMu           sync.Mutex
+	stopOnce          sync.Once
+
 	cancel  context.CancelFunc
 	done    chan struct{}
 	errChan chan error
@@ -76,6 +141,15 @@ type Client struct {
 
 type Option func(*Client)
 
+// FrameHandler handle an encoded frame read from the broker.
+type FrameHandler func(frame []byte)
+
+// ErrNotConnected is returned when sending a frame while the client is not connected.
+var ErrNotConnected = errors.New("not connected to the broker")
+
+// Dialer open a connection to the broker, net.Dialer.DialContext is one.
+type Dialer func(ctx context.Context, network, address string) (net.Conn, error)
+
 // WithLogger sets the logger for the TCP client
 func WithLogger(logger *slog.Logger) Option {
 	return func(c *Client) {
@@ -91,6 +165,13 @@ func WithAddress(address string, port int) Option {
 	}
 }
 
+// WithAddresses sets the "host:port" broker addresses, they are dialed in order until one is reached
+func WithAddresses(addresses ...string) Option {
+	return func(c *Client) {
+		c.config.addresses = addresses
+	}
+}
+
 // WithRetryConfig sets the retry configuration
 func WithRetryConfig(maxRetries int, delay time.Duration, backoff float64, maxWait time.Duration) Option {
 	return func(c *Client) {
@@ -110,6 +191,45 @@ func WithHealthConfig(interval time.Duration, circuitFailures int, circuitTimeou
 	}
 }
 
+// WithReconnectInterval sets the interval between two reconnection attempts
+func WithReconnectInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.config.reconnectInterval = interval
+	}
+}
+
+// WithTLS enables TLS on the connection to the broker.
+// The server name default to the broker address when not set in the configuration.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		c.config.tlsConfig = tlsConfig
+	}
+}
+
+// WithDialer open the connections with the dialer instead of a TCP dialer,
+// e.g. to reach a broker embedded in the same process.
+func WithDialer(dialer Dialer) Option {
+	return func(c *Client) {
+		c.config.dialer = dialer
+	}
+}
+
+// WithFrameHandler hand the frames read from the broker to the handler, the frames are handled one at a time.
+func WithFrameHandler(handler FrameHandler) Option {
+	return func(c *Client) {
+		c.frameHandler = handler
+	}
+}
+
+// WithConnectionHandlers notify onConnect of every new connection, before its first frame is read,
+// and onDisconnect of the loss of the connection.
+func WithConnectionHandlers(onConnect func(), onDisconnect func(err error)) Option {
+	return func(c *Client) {
+		c.connectHandler = onConnect
+		c.disconnectHandler = onDisconnect
+	}
+}
+
 func (t *Client) Run(ctx context.Context) error {
 	ctx, t.cancel = context.WithCancel(ctx)
 
@@ -119,23 +239,33 @@ func (t *Client) Run(ctx context.Context) error {
 	go func() {
 		err := t.HandleConnection(ctx)
 		if err != nil {
-			t.logger.Warn("Error handling connection: ", err)
+			t.logger.Warn("error handling connection", "error", err)
 			<-ctx.Done()
 		}
 	}()
 	go t.healthMonitor(ctx)
 	go t.messageHandler(ctx)
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-t.errChan:
-		return err
-	case <-time.After(10 * time.Second):
-		if t.IsHealthy() {
-			return nil
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(10 * time.Second)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-t.errChan:
+			return err
+		case <-ticker.C:
+			if t.IsHealthy() {
+				return nil
+			}
+		case <-timeout:
+			if t.IsHealthy() {
+				return nil
+			}
+			return fmt.Errorf("failed to establish initial connection")
 		}
-		return fmt.Errorf("failed to establish initial connection")
 	}
 }
 
@@ -144,6 +274,7 @@ func (t *Client) Stop(ctx context.Context) error {
 	if t.cancel != nil {
 		t.cancel()
 	}
+	t.closeConnection()
 
 	done := make(chan struct{})
 	go func() {
@@ -153,8 +284,10 @@ func (t *Client) Stop(ctx context.Context) error {
 
 	select {
 	case <-done:
-		t.closeConnection()
-		close(t.done)
+		t.stopOnce.Do(func() { close(t.done) })
+		if t.disconnectHandler != nil {
+			t.disconnectHandler(nil)
+		}
 		return nil
 	case <-ctx.Done():
 		t.closeConnection()
@@ -165,6 +298,8 @@ func (t *Client) Stop(ctx context.Context) error {
 func (t *Client) HandleConnection(ctx context.Context) error {
 	defer t.wg.Done()
 
+	t.attemptConnection(ctx)
+
 	ticker := time.NewTicker(t.config.reconnectInterval)
 	defer ticker.Stop()
 
@@ -207,19 +342,21 @@ func (t *Client) GetHealthStatus() HealthStatus {
 	return HealthStatus(atomic.LoadInt32(&t.health.status))
 }
 
-// GetHealthMetrics returns health metrics
-func (t *Client) GetHealthMetrics() map[string]interface{} {
+// GetHealthMetrics returns a snapshot of the health metrics
+func (t *Client) GetHealthMetrics() HealthMetrics {
 	t.connMu.RLock()
 	defer t.connMu.RUnlock()
 
-	return map[string]interface{}{
-		"status":             t.GetHealthStatus(),
-		"last_connected":     t.health.lastConnected,
-		"consecutive_errors": atomic.LoadInt32(&t.health.consecutiveErrors),
-		"total_connections":  atomic.LoadInt64(&t.health.totalConnections),
-		"total_errors":       atomic.LoadInt64(&t.health.totalErrors),
-		"circuit_state":      CircuitState(atomic.LoadInt32(&t.circuit.state)),
-		"circuit_failures":   atomic.LoadInt32(&t.circuit.failures),
+	return HealthMetrics{
+		Status:            t.GetHealthStatus(),
+		LastConnected:     t.health.lastConnected,
+		ConsecutiveErrors: atomic.LoadInt32(&t.health.consecutiveErrors),
+		TotalConnections:  atomic.LoadInt64(&t.health.totalConnections),
+		TotalErrors:       atomic.LoadInt64(&t.health.totalErrors),
+		CircuitState:      CircuitState(atomic.LoadInt32(&t.circuit.state)),
+		CircuitFailures:   atomic.LoadInt32(&t.circuit.failures),
+		BytesSent:         atomic.LoadInt64(&t.health.bytesSent),
+		BytesReceived:     atomic.LoadInt64(&t.health.bytesReceived),
 	}
 }
 
@@ -242,7 +379,10 @@ func (t *Client) healthMonitor(ctx context.Context) {
 func (t *Client) messageHandler(ctx context.Context) {
 	defer t.wg.Done()
 
-	buffer := make([]byte, 4096)
+	var (
+		current net.Conn
+		reader  *bufio.Reader
+	)
 
 	for {
 		select {
@@ -256,25 +396,52 @@ func (t *Client) messageHandler(ctx context.Context) {
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
-
-		if err := conn.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
-			t.logger.Warn("error while setting read deadline", "error", err)
+		if conn != current {
+			current = conn
+			reader = bufio.NewReader(conn)
 		}
 
-		n, err := conn.Read(buffer)
+		// no read deadline, a deadline hit in the middle of a frame would corrupt the stream.
+		// Stop close the connection to unblock the read.
+		frame, err := protocol.ReadFrame(reader, 0)
 		if err != nil {
-			t.HandleConnectionError(err)
+			if ctx.Err() != nil {
+				return
+			}
+			if t.getConnection() == conn {
+				t.HandleConnectionError(err)
+			}
 			continue
 		}
 
-		if n > 0 {
-			t.resetConsecutiveErrors()
-			t.logger.Info("received", "bytes", n, "data", string(buffer[:n]))
-			continue
+		t.resetConsecutiveErrors()
+		atomic.AddInt64(&t.health.bytesReceived, int64(len(frame)))
+		if t.frameHandler != nil {
+			t.frameHandler(frame)
 		}
 	}
 }
 
+// Send write the encoded frame to the broker.
+func (t *Client) Send(frame []byte) error {
+	conn := t.getConnection()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(frame); err != nil {
+		t.HandleConnectionError(err)
+		return err
+	}
+	atomic.AddInt64(&t.health.bytesSent, int64(len(frame)))
+
+	return nil
+}
+
 func (t *Client) attemptConnection(ctx context.Context) {
 	if !t.shouldAttemptConnection() {
 		return
@@ -282,14 +449,15 @@ func (t *Client) attemptConnection(ctx context.Context) {
 
 	t.setHealthStatus(StatusConnecting)
 
-	addr := net.JoinHostPort(t.config.address, strconv.Itoa(t.config.port))
-
-	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	conn, addr, err := t.dialBroker(ctx)
 	if err != nil {
 		t.HandleConnectionError(err)
 		return
 	}
 
+	if t.connectHandler != nil {
+		t.connectHandler()
+	}
 	t.setConnection(conn)
 	t.setHealthStatus(StatusConnected)
 	t.resetCircuit()
@@ -300,8 +468,73 @@ func (t *Client) attemptConnection(ctx context.Context) {
 	t.logger.Info("connected successfully", "address", addr)
 }
 
+// dialBroker dial the broker addresses in order, starting from the last broker reached.
+func (t *Client) dialBroker(ctx context.Context) (net.Conn, string, error) {
+	addresses := t.config.addresses
+	if len(addresses) == 0 {
+		addresses = []string{net.JoinHostPort(t.config.address, strconv.Itoa(t.config.port))}
+	}
+
+	var errs []error
+	for i := range addresses {
+		index := (t.broker + i) % len(addresses)
+		conn, err := t.dial(ctx, addresses[index])
+		if err == nil {
+			t.broker = index
+			return conn, addresses[index], nil
+		}
+
+		if len(addresses) > 1 {
+			t.logger.Warn("broker unreachable, failing over", "address", addresses[index], "error", err)
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+func (t *Client) dial(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if t.config.dialer == nil {
+		if t.config.tlsConfig == nil {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}
+
+		return tls.DialWithDialer(dialer, "tcp", addr, t.clientTLSConfig(addr))
+	}
+
+	conn, err := t.config.dialer(ctx, "tcp", addr)
+	if err != nil || t.config.tlsConfig == nil {
+		return conn, err
+	}
+
+	tlsConn := tls.Client(conn, t.clientTLSConfig(addr))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// clientTLSConfig return the TLS configuration, the server name default to the dialed broker host.
+func (t *Client) clientTLSConfig(addr string) *tls.Config {
+	tlsConfig := t.config.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = t.config.address
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			tlsConfig.ServerName = host
+		}
+	}
+
+	return tlsConfig
+}
+
 func (t *Client) HandleConnectionError(err error) {
 	t.closeConnection()
+	if t.disconnectHandler != nil {
+		t.disconnectHandler(err)
+	}
 
 	consecutive := atomic.AddInt32(&t.health.consecutiveErrors, 1)
 	atomic.AddInt64(&t.health.totalErrors, 1)
@@ -329,9 +562,6 @@ func (t *Client) performHealthCheck() {
 		}
 		return
 	}
-
-	conn.SetReadDeadline(time.Now().Add(1 * time.Second))
-	conn.SetWriteDeadline(time.Now().Add(1 * time.Second))
 }
 
 func (t *Client) shouldAttemptConnection() bool {
@@ -412,6 +642,7 @@ func NewTCPClient(opts ...Option) *Client {
 		config: struct {
 			port                int
 			address             string
+			addresses           []string
 			maxRetries          int
 			maxRetryWait        time.Duration
 			retryDelay          time.Duration
@@ -420,6 +651,8 @@ func NewTCPClient(opts ...Option) *Client {
 			circuitFailures     int
 			circuitTimeout      time.Duration
 			reconnectInterval   time.Duration
+			tlsConfig           *tls.Config
+			dialer              Dialer
 		}{
 			port:                5672,
 			address:             "127.0.0.1",
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// TypedHandler handle a message delivered to a typed subscription with its decoded content.
+type TypedHandler[T any] func(ctx context.Context, msg *Message, v T) error
+
+// PublishTyped encode the value with the codec of the content type and publish it to the topic,
+// the content type is set in the HeaderContentType header.
+func PublishTyped[T any](
+	ctx context.Context,
+	c *Client,
+	topic string,
+	v T,
+	contentType string,
+	headers map[string]string,
+) error {
+	codec, err := c.Codec(contentType)
+	if err != nil {
+		return err
+	}
+
+	content, err := codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	// the caller headers are not modified.
+	encoded := make(map[string]string, len(headers)+1)
+	maps.Copy(encoded, headers)
+	encoded[HeaderContentType] = codec.ContentType()
+
+	return c.Publish(ctx, topic, content, encoded)
+}
+
+// PublishJSON encode the value as JSON and publish it to the topic.
+func PublishJSON[T any](ctx context.Context, c *Client, topic string, v T, headers map[string]string) error {
+	return PublishTyped(ctx, c, topic, v, ContentTypeJSON, headers)
+}
+
+// SubscribeTyped deliver the messages published on the topics matching the pattern to the handler,
+// their content is decoded with the codec of their content type. The handler is not called for the
+// messages which can not be decoded, the decoding error is reported as a handler error.
+func SubscribeTyped[T any](ctx context.Context, c *Client, topic string, handler TypedHandler[T]) (*Subscription, error) {
+	if handler == nil {
+		return nil, ErrNilHandler
+	}
+
+	return c.Subscribe(ctx, topic, func(ctx context.Context, msg *Message) error {
+		var v T
+		if err := c.Decode(msg, &v); err != nil {
+			return fmt.Errorf("failed to decode message: %w", err)
+		}
+
+		return handler(ctx, msg, v)
+	})
+}
@@ -0,0 +1,36 @@
+package domain
+
+// TopicInfo describe a topic pattern the containers are subscribed to.
+type TopicInfo struct {
+	Topic string `json:"topic"`
+	// Subscribers count the containers subscribed to the topic pattern.
+	Subscribers int `json:"subscribers"`
+}
+
+// ClientInfo describe a client connected to the broker.
+type ClientInfo struct {
+	ID          ID            `json:"id"`
+	ContainerID ID            `json:"container_id"`
+	Identity    string        `json:"identity,omitempty"`
+	Transport   TransportType `json:"transport"`
+}
+
+// ContainerInfo describe a container of the broker.
+type ContainerInfo struct {
+	ID              ID              `json:"id"`
+	ClientID        ID              `json:"client_id"`
+	State           ContainerState  `json:"state"`
+	ProtocolVersion ProtocolVersion `json:"protocol_version"`
+	Capabilities    string          `json:"capabilities"`
+	Topics          []string        `json:"topics"`
+}
+
+// BrokerInspector expose the state of the broker to the management API.
+type BrokerInspector interface {
+	// Topics return the subscribed topic patterns, sorted by topic.
+	Topics() []TopicInfo
+	// Clients return the connected clients, sorted by id.
+	Clients() []ClientInfo
+	// Containers return the containers, sorted by id.
+	Containers() []ContainerInfo
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// AuthMechanism represent the SASL mechanism carried by an Auth frame.
+type AuthMechanism string
+
+const (
+	// AuthMechanismPlain authenticate with a username and a password (RFC 4616).
+	// The response is encoded as authzid NUL username NUL password.
+	AuthMechanismPlain AuthMechanism = "PLAIN"
+
+	// AuthMechanismToken authenticate with an opaque bearer token carried as the response.
+	AuthMechanismToken AuthMechanism = "TOKEN"
+)
+
+// Authenticator verify the credentials sent by a client during the handshake.
+type Authenticator interface {
+	// Authenticate return the identity matching the credentials, ErrUnauthorized when they are rejected.
+	Authenticate(ctx context.Context, mechanism AuthMechanism, response []byte) (string, error)
+	// Mechanisms return the mechanisms supported by the authenticator.
+	Mechanisms() []AuthMechanism
+}
+
+// Permission represent a set of rights on a topic.
+type Permission uint8
+
+const (
+	// PermissionSubscribe allow to consume messages from a topic.
+	PermissionSubscribe Permission = 1 << iota
+
+	// PermissionPublish allow to publish messages to a topic.
+	PermissionPublish
+
+	// PermissionManage allow to create, purge and delete a topic.
+	PermissionManage
+)
+
+var permissionNames = []struct {
+	permission Permission
+	name       string
+}{
+	{PermissionSubscribe, "subscribe"},
+	{PermissionPublish, "publish"},
+	{PermissionManage, "manage"},
+}
+
+// Has return true when every permission of other is part of the set.
+func (p Permission) Has(other Permission) bool {
+	return p&other == other
+}
+
+// String return the names of the permissions in the set.
+func (p Permission) String() string {
+	names := make([]string, 0, len(permissionNames))
+	for _, entry := range permissionNames {
+		if p.Has(entry.permission) {
+			names = append(names, entry.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Authorizer decide whether an identity is allowed to perform an operation on a topic.
+type Authorizer interface {
+	// Authorize return ErrForbidden when the identity does not hold the permission on the topic.
+	Authorize(identity string, topic string, permission Permission) error
+}
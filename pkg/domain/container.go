@@ -24,6 +24,10 @@ const (
 	// Idle State represent the state when no clients have been CONNECTED
 	// for a while so could be stolen by a new one (overriding topics and all).
 	ContainerIdle ContainerState = "IDLE"
+
+	// ContainerClosing State represent a container waiting for its pending deliveries
+	// to be flushed before being torn down.
+	ContainerClosing ContainerState = "CLOSING"
 )
 
 // Container represent an hopper container.
@@ -0,0 +1,143 @@
+package domain
+
+import "errors"
+
+// ErrorCode represent the code carried by an Error frame.
+//
+// Codes are stable across releases and grouped by range:
+//
+//	0x01xx frame errors      the frame could not be decoded or is not allowed.
+//	0x02xx session errors    the peer is not allowed to perform the action.
+//	0x03xx routing errors    the action targets an unknown resource.
+//	0x04xx flow errors       the peer exceeded the negotiated limits.
+//	0x05xx broker errors     the broker failed to process a valid action.
+type ErrorCode uint16
+
+const (
+	// ErrorCodeInvalidFrame is sent when a frame can not be decoded.
+	ErrorCodeInvalidFrame ErrorCode = 0x0100
+
+	// ErrorCodeUnsupportedFrame is sent when the frame type is unknown or not handled by the broker.
+	ErrorCodeUnsupportedFrame ErrorCode = 0x0101
+
+	// ErrorCodeInvalidPayload is sent when the payload does not match the frame type.
+	ErrorCodeInvalidPayload ErrorCode = 0x0102
+
+	// ErrorCodeFrameTooLarge is sent when the frame exceed the maximum frame size accepted by the broker.
+	ErrorCodeFrameTooLarge ErrorCode = 0x0103
+
+	// ErrorCodeInvalidState is sent when the frame is not allowed in the current container state.
+	ErrorCodeInvalidState ErrorCode = 0x0104
+
+	// ErrorCodeUnsupportedVersion is sent when the protocol version requested by the client is not spoken by the broker.
+	ErrorCodeUnsupportedVersion ErrorCode = 0x0105
+
+	// ErrorCodeUnauthorized is sent when the client is not authenticated.
+	ErrorCodeUnauthorized ErrorCode = 0x0200
+
+	// ErrorCodeForbidden is sent when the client is authenticated but not allowed to perform the action.
+	ErrorCodeForbidden ErrorCode = 0x0201
+
+	// ErrorCodeUnknownContainer is sent when no container is attached to the client.
+	ErrorCodeUnknownContainer ErrorCode = 0x0202
+
+	// ErrorCodeUnknownTopic is sent when the action targets a topic unknown to the container.
+	ErrorCodeUnknownTopic ErrorCode = 0x0300
+
+	// ErrorCodeFlowControl is sent when the client exceed the negotiated flow control windows.
+	ErrorCodeFlowControl ErrorCode = 0x0400
+
+	// ErrorCodeRateLimited is sent when the client publish faster than its rate limits allow.
+	ErrorCodeRateLimited ErrorCode = 0x0401
+
+	// ErrorCodeInternal is sent when the broker failed to process the action.
+	ErrorCodeInternal ErrorCode = 0x0500
+)
+
+var (
+	// ErrInvalidFrame represent a frame that can not be decoded.
+	ErrInvalidFrame = errors.New("invalid frame")
+
+	// ErrFrameTooLarge represent a frame exceeding the maximum frame size.
+	ErrFrameTooLarge = errors.New("frame too large")
+
+	// ErrInvalidContainerState represent a frame received in a state that does not allow it.
+	ErrInvalidContainerState = errors.New("invalid container state")
+
+	// ErrUnsupportedVersion represent a protocol version not spoken by the broker.
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+	// ErrUnauthorized represent an unauthenticated client.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden represent a client not allowed to perform an action.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrContainerNotFound represent a client without container.
+	ErrContainerNotFound = errors.New("container not found")
+
+	// ErrUnknownTopic represent an action on a topic unknown to the container.
+	ErrUnknownTopic = errors.New("unknown topic")
+
+	// ErrFlowControl represent a flow control violation.
+	ErrFlowControl = errors.New("flow control violation")
+
+	// ErrRateLimited represent a message exceeding the rate limits of the client.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrInternal represent a broker failure.
+	ErrInternal = errors.New("internal broker error")
+)
+
+var errorCatalogue = []struct {
+	code ErrorCode
+	name string
+	err  error
+}{
+	{ErrorCodeInvalidFrame, "INVALID_FRAME", ErrInvalidFrame},
+	{ErrorCodeUnsupportedFrame, "UNSUPPORTED_FRAME", ErrUnsupportedFrameType},
+	{ErrorCodeInvalidPayload, "INVALID_PAYLOAD", ErrInvalidPayload},
+	{ErrorCodeFrameTooLarge, "FRAME_TOO_LARGE", ErrFrameTooLarge},
+	{ErrorCodeInvalidState, "INVALID_STATE", ErrInvalidContainerState},
+	{ErrorCodeUnsupportedVersion, "UNSUPPORTED_VERSION", ErrUnsupportedVersion},
+	{ErrorCodeUnauthorized, "UNAUTHORIZED", ErrUnauthorized},
+	{ErrorCodeForbidden, "FORBIDDEN", ErrForbidden},
+	{ErrorCodeUnknownContainer, "UNKNOWN_CONTAINER", ErrContainerNotFound},
+	{ErrorCodeUnknownTopic, "UNKNOWN_TOPIC", ErrUnknownTopic},
+	{ErrorCodeFlowControl, "FLOW_CONTROL", ErrFlowControl},
+	{ErrorCodeRateLimited, "RATE_LIMITED", ErrRateLimited},
+	{ErrorCodeInternal, "INTERNAL", ErrInternal},
+}
+
+// String return the catalogue name of the code.
+func (c ErrorCode) String() string {
+	for _, entry := range errorCatalogue {
+		if entry.code == c {
+			return entry.name
+		}
+	}
+
+	return "UNKNOWN"
+}
+
+// Err return the sentinel error matching the code, ErrInternal for unknown codes.
+func (c ErrorCode) Err() error {
+	for _, entry := range errorCatalogue {
+		if entry.code == c {
+			return entry.err
+		}
+	}
+
+	return ErrInternal
+}
+
+// CodeOf return the error code matching the given error, ErrorCodeInternal when the error is not catalogued.
+func CodeOf(err error) ErrorCode {
+	for _, entry := range errorCatalogue {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+
+	return ErrorCodeInternal
+}
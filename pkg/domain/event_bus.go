@@ -23,11 +23,29 @@ const (
 
 	// EventTypeReceiveMessage is the type for a received msg event.
 	EventTypeReceiveMessage EventType = "receive_message"
+
+	// EventTypeInvalidFrame is the type for a frame rejected by a transporter.
+	EventTypeInvalidFrame EventType = "invalid_frame"
+
+	// EventTypeThrottleConnection is the type for pausing the reads of a connection.
+	EventTypeThrottleConnection EventType = "throttle_connection"
 )
 
 const (
 	// TransportTypeTCP is the type for TCP tranpoter.
 	TransportTypeTCP TransportType = "tcp"
+
+	// TransportTypeWebSocket is the type for WebSocket transporter.
+	TransportTypeWebSocket TransportType = "websocket"
+
+	// TransportTypeMQTT is the type for the MQTT adapter.
+	TransportTypeMQTT TransportType = "mqtt"
+
+	// TransportTypeAMQP is the type for the AMQP 1.0 adapter.
+	TransportTypeAMQP TransportType = "amqp"
+
+	// TransportTypeInProc is the type for the in-process transport.
+	TransportTypeInProc TransportType = "inproc"
 )
 
 // Event represent the event happening.
@@ -44,6 +44,9 @@ const (
 	// FrameTypeStart represent the frame type for starting the message flow.
 	FrameTypeStart FrameType = 0x0A
 
+	// FrameTypeConfirm represent the frame type confirming a published message to its publisher.
+	FrameTypeConfirm FrameType = 0x1E
+
 	// FrameTypeMessage represent the frame type for a message.
 	FrameTypeMessage FrameType = 0x1F
 
@@ -51,6 +54,17 @@ const (
 	FrameTypeError FrameType = 0xF0
 )
 
+const (
+	// CloseCodeNormal is the close code for a regular close requested by a peer.
+	CloseCodeNormal uint16 = 0x0000
+
+	// CloseCodeShutdown is the close code sent when the broker is shutting down.
+	CloseCodeShutdown uint16 = 0x0001
+
+	// CloseCodeAuthFailed is the close code sent when the client exhausted its authentication attempts.
+	CloseCodeAuthFailed uint16 = 0x0002
+)
+
 // Frame represent a frame used by the protocol.
 type Frame interface {
 	GetType() FrameType
@@ -84,6 +98,8 @@ type OpenFramePayload interface {
 	Payload
 	GetSourceID() ID
 	GetAssignedContainerID() ID
+	GetSupportedVersions() []ProtocolVersion
+	GetCapabilities() Capability
 }
 
 type OpenRcvdFramePayload interface {
@@ -91,6 +107,13 @@ type OpenRcvdFramePayload interface {
 	GetSourceID() ID
 }
 
+// AuthFramePayload is the interface for auth frame payloads in the HopperMQ protocol.
+type AuthFramePayload interface {
+	Payload
+	GetMechanism() AuthMechanism
+	GetResponse() []byte
+}
+
 // MessageFramePayload is the interface for message frame payloads in the HopperMQ protocol.
 type MessageFramePayload interface {
 	Payload
@@ -101,12 +124,21 @@ type MessageFramePayload interface {
 	GetHeaders() map[string]string
 }
 
+// ConfirmFramePayload is the interface for confirm frame payloads in the HopperMQ protocol.
+type ConfirmFramePayload interface {
+	Payload
+	GetMessageID() ID
+}
+
 // ConnectFramePayload is the interface for connect frame payloads in the HopperMQ protocol.
 type ConnectFramePayload interface {
 	Payload
 	GetSourceID() ID
 	GetClientVersion() string
 	GetKeepAlive() uint16
+	GetProtocolVersion() ProtocolVersion
+	GetCapabilities() Capability
+	GetIncomingWindow() uint32
 }
 
 // SubscribeFramePayload is the interface for subscribe frame payloads in the HopperMQ protocol.
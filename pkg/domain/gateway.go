@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSubscriptionNotFound represent an action on a named subscription that does not exist.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	// ErrSubscriptionConflict represent a named subscription already bound to another topic.
+	ErrSubscriptionConflict = errors.New("subscription already exists")
+
+	// ErrUnknownDelivery represent an ack of a delivery that is not in flight.
+	ErrUnknownDelivery = errors.New("unknown delivery")
+)
+
+// Delivery represent a message handed to a pulling consumer, it stays in flight until acked.
+type Delivery struct {
+	ID        ID                `json:"delivery_id"`
+	MessageID ID                `json:"message_id"`
+	Topic     string            `json:"topic"`
+	Content   []byte            `json:"content"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// Attempt count the deliveries of the message, starting at 1.
+	Attempt int `json:"attempt"`
+	// Offset is the position of the message in the message log, 0 when no log is registered.
+	Offset uint64 `json:"offset,omitempty"`
+}
+
+// MessageLog retain the routed messages so stream consumers can resume after a disconnect.
+type MessageLog interface {
+	// Append record the message and return its offset, offsets start at 1 and increase with every message.
+	Append(msg Delivery) uint64
+	// ReadAfter return the retained messages with an offset above the given one, oldest first.
+	ReadAfter(offset uint64) []Delivery
+}
+
+// MessageGateway let clients without a protocol connection publish and consume messages through the broker.
+// The identity is the authenticated caller, empty for anonymous callers.
+type MessageGateway interface {
+	// Publish route a message to the subscribers of the topic and return its id.
+	Publish(ctx context.Context, identity string, topic string, content []byte, headers map[string]string) (ID, error)
+	// CreateSubscription bind a named subscription to a topic pattern, creating an existing one is a no-op.
+	CreateSubscription(identity string, name string, topic string) error
+	// DeleteSubscription remove a named subscription and drop its pending messages.
+	DeleteSubscription(identity string, name string) error
+	// Pull return up to max messages of the subscription, waiting up to wait for the first one.
+	Pull(ctx context.Context, identity string, name string, max int, wait time.Duration) ([]Delivery, error)
+	// Ack settle the deliveries, unacked deliveries are redelivered once their ack timeout expire.
+	Ack(identity string, name string, deliveryIDs []ID) error
+	// Stream push the messages published on the topic patterns until the context end or the consumer fall behind,
+	// after replaying the retained messages above lastOffset when a message log is registered and lastOffset is not 0.
+	Stream(ctx context.Context, identity string, topics []string, lastOffset uint64) (<-chan Delivery, error)
+}
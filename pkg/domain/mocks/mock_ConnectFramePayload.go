@@ -36,6 +36,50 @@ func (_m *MockConnectFramePayload) EXPECT() *MockConnectFramePayload_Expecter {
 	return &MockConnectFramePayload_Expecter{mock: &_m.Mock}
 }
 
+// GetCapabilities provides a mock function for the type MockConnectFramePayload
+func (_mock *MockConnectFramePayload) GetCapabilities() domain.Capability {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCapabilities")
+	}
+
+	var r0 domain.Capability
+	if returnFunc, ok := ret.Get(0).(func() domain.Capability); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(domain.Capability)
+	}
+	return r0
+}
+
+// MockConnectFramePayload_GetCapabilities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCapabilities'
+type MockConnectFramePayload_GetCapabilities_Call struct {
+	*mock.Call
+}
+
+// GetCapabilities is a helper method to define mock.On call
+func (_e *MockConnectFramePayload_Expecter) GetCapabilities() *MockConnectFramePayload_GetCapabilities_Call {
+	return &MockConnectFramePayload_GetCapabilities_Call{Call: _e.mock.On("GetCapabilities")}
+}
+
+func (_c *MockConnectFramePayload_GetCapabilities_Call) Run(run func()) *MockConnectFramePayload_GetCapabilities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetCapabilities_Call) Return(capability domain.Capability) *MockConnectFramePayload_GetCapabilities_Call {
+	_c.Call.Return(capability)
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetCapabilities_Call) RunAndReturn(run func() domain.Capability) *MockConnectFramePayload_GetCapabilities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetClientVersion provides a mock function for the type MockConnectFramePayload
 func (_mock *MockConnectFramePayload) GetClientVersion() string {
 	ret := _mock.Called()
@@ -126,6 +170,50 @@ func (_c *MockConnectFramePayload_GetHeader_Call) RunAndReturn(run func() domain
 	return _c
 }
 
+// GetIncomingWindow provides a mock function for the type MockConnectFramePayload
+func (_mock *MockConnectFramePayload) GetIncomingWindow() uint32 {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIncomingWindow")
+	}
+
+	var r0 uint32
+	if returnFunc, ok := ret.Get(0).(func() uint32); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+	return r0
+}
+
+// MockConnectFramePayload_GetIncomingWindow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIncomingWindow'
+type MockConnectFramePayload_GetIncomingWindow_Call struct {
+	*mock.Call
+}
+
+// GetIncomingWindow is a helper method to define mock.On call
+func (_e *MockConnectFramePayload_Expecter) GetIncomingWindow() *MockConnectFramePayload_GetIncomingWindow_Call {
+	return &MockConnectFramePayload_GetIncomingWindow_Call{Call: _e.mock.On("GetIncomingWindow")}
+}
+
+func (_c *MockConnectFramePayload_GetIncomingWindow_Call) Run(run func()) *MockConnectFramePayload_GetIncomingWindow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetIncomingWindow_Call) Return(v uint32) *MockConnectFramePayload_GetIncomingWindow_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetIncomingWindow_Call) RunAndReturn(run func() uint32) *MockConnectFramePayload_GetIncomingWindow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetKeepAlive provides a mock function for the type MockConnectFramePayload
 func (_mock *MockConnectFramePayload) GetKeepAlive() uint16 {
 	ret := _mock.Called()
@@ -170,6 +258,50 @@ func (_c *MockConnectFramePayload_GetKeepAlive_Call) RunAndReturn(run func() uin
 	return _c
 }
 
+// GetProtocolVersion provides a mock function for the type MockConnectFramePayload
+func (_mock *MockConnectFramePayload) GetProtocolVersion() domain.ProtocolVersion {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProtocolVersion")
+	}
+
+	var r0 domain.ProtocolVersion
+	if returnFunc, ok := ret.Get(0).(func() domain.ProtocolVersion); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(domain.ProtocolVersion)
+	}
+	return r0
+}
+
+// MockConnectFramePayload_GetProtocolVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProtocolVersion'
+type MockConnectFramePayload_GetProtocolVersion_Call struct {
+	*mock.Call
+}
+
+// GetProtocolVersion is a helper method to define mock.On call
+func (_e *MockConnectFramePayload_Expecter) GetProtocolVersion() *MockConnectFramePayload_GetProtocolVersion_Call {
+	return &MockConnectFramePayload_GetProtocolVersion_Call{Call: _e.mock.On("GetProtocolVersion")}
+}
+
+func (_c *MockConnectFramePayload_GetProtocolVersion_Call) Run(run func()) *MockConnectFramePayload_GetProtocolVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetProtocolVersion_Call) Return(protocolVersion domain.ProtocolVersion) *MockConnectFramePayload_GetProtocolVersion_Call {
+	_c.Call.Return(protocolVersion)
+	return _c
+}
+
+func (_c *MockConnectFramePayload_GetProtocolVersion_Call) RunAndReturn(run func() domain.ProtocolVersion) *MockConnectFramePayload_GetProtocolVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetSourceID provides a mock function for the type MockConnectFramePayload
 func (_mock *MockConnectFramePayload) GetSourceID() domain.ID {
 	ret := _mock.Called()
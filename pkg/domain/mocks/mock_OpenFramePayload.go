@@ -80,6 +80,50 @@ func (_c *MockOpenFramePayload_GetAssignedContainerID_Call) RunAndReturn(run fun
 	return _c
 }
 
+// GetCapabilities provides a mock function for the type MockOpenFramePayload
+func (_mock *MockOpenFramePayload) GetCapabilities() domain.Capability {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCapabilities")
+	}
+
+	var r0 domain.Capability
+	if returnFunc, ok := ret.Get(0).(func() domain.Capability); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(domain.Capability)
+	}
+	return r0
+}
+
+// MockOpenFramePayload_GetCapabilities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCapabilities'
+type MockOpenFramePayload_GetCapabilities_Call struct {
+	*mock.Call
+}
+
+// GetCapabilities is a helper method to define mock.On call
+func (_e *MockOpenFramePayload_Expecter) GetCapabilities() *MockOpenFramePayload_GetCapabilities_Call {
+	return &MockOpenFramePayload_GetCapabilities_Call{Call: _e.mock.On("GetCapabilities")}
+}
+
+func (_c *MockOpenFramePayload_GetCapabilities_Call) Run(run func()) *MockOpenFramePayload_GetCapabilities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockOpenFramePayload_GetCapabilities_Call) Return(capability domain.Capability) *MockOpenFramePayload_GetCapabilities_Call {
+	_c.Call.Return(capability)
+	return _c
+}
+
+func (_c *MockOpenFramePayload_GetCapabilities_Call) RunAndReturn(run func() domain.Capability) *MockOpenFramePayload_GetCapabilities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetHeader provides a mock function for the type MockOpenFramePayload
 func (_mock *MockOpenFramePayload) GetHeader() domain.HeaderPayload {
 	ret := _mock.Called()
@@ -170,6 +214,52 @@ func (_c *MockOpenFramePayload_GetSourceID_Call) RunAndReturn(run func() domain.
 	return _c
 }
 
+// GetSupportedVersions provides a mock function for the type MockOpenFramePayload
+func (_mock *MockOpenFramePayload) GetSupportedVersions() []domain.ProtocolVersion {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSupportedVersions")
+	}
+
+	var r0 []domain.ProtocolVersion
+	if returnFunc, ok := ret.Get(0).(func() []domain.ProtocolVersion); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ProtocolVersion)
+		}
+	}
+	return r0
+}
+
+// MockOpenFramePayload_GetSupportedVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSupportedVersions'
+type MockOpenFramePayload_GetSupportedVersions_Call struct {
+	*mock.Call
+}
+
+// GetSupportedVersions is a helper method to define mock.On call
+func (_e *MockOpenFramePayload_Expecter) GetSupportedVersions() *MockOpenFramePayload_GetSupportedVersions_Call {
+	return &MockOpenFramePayload_GetSupportedVersions_Call{Call: _e.mock.On("GetSupportedVersions")}
+}
+
+func (_c *MockOpenFramePayload_GetSupportedVersions_Call) Run(run func()) *MockOpenFramePayload_GetSupportedVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockOpenFramePayload_GetSupportedVersions_Call) Return(protocolVersions []domain.ProtocolVersion) *MockOpenFramePayload_GetSupportedVersions_Call {
+	_c.Call.Return(protocolVersions)
+	return _c
+}
+
+func (_c *MockOpenFramePayload_GetSupportedVersions_Call) RunAndReturn(run func() []domain.ProtocolVersion) *MockOpenFramePayload_GetSupportedVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Sizer provides a mock function for the type MockOpenFramePayload
 func (_mock *MockOpenFramePayload) Sizer() uint16 {
 	ret := _mock.Called()
@@ -0,0 +1,52 @@
+package domain
+
+import "strings"
+
+// ProtocolVersion represent a version of the HopperMQ wire protocol.
+type ProtocolVersion uint16
+
+const (
+	// ProtocolVersion1 is the first version of the HopperMQ protocol.
+	ProtocolVersion1 ProtocolVersion = 1
+)
+
+// Capability represent an optional protocol feature negotiated during the handshake.
+// Capabilities are bit flags so a set of them can be carried as a single value.
+type Capability uint32
+
+const (
+	// CapabilityCompression allow peers to exchange compressed message content.
+	CapabilityCompression Capability = 1 << iota
+
+	// CapabilityConfirms allow the client to receive publish confirmations from the broker.
+	CapabilityConfirms
+
+	// CapabilityLargeFrames allow peers to exchange frames larger than the standard frame size.
+	CapabilityLargeFrames
+)
+
+var capabilityNames = []struct {
+	capability Capability
+	name       string
+}{
+	{CapabilityCompression, "compression"},
+	{CapabilityConfirms, "confirms"},
+	{CapabilityLargeFrames, "large_frames"},
+}
+
+// Has return true when every capability of other is part of the set.
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+// String return the names of the capabilities in the set.
+func (c Capability) String() string {
+	names := make([]string, 0, len(capabilityNames))
+	for _, entry := range capabilityNames {
+		if c.Has(entry.capability) {
+			names = append(names, entry.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// RateLimiter meter the messages published by clients.
+type RateLimiter interface {
+	// Reserve account a message of size bytes published by the client on the topic.
+	// It return how long the client must pause before publishing again,
+	// or ErrRateLimited when the message must be rejected.
+	Reserve(clientID ID, identity string, topic string, size int) (time.Duration, error)
+	// Release forget the state held for the client.
+	Release(clientID ID)
+}
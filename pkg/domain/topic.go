@@ -0,0 +1,80 @@
+package domain
+
+import "strings"
+
+const (
+	topicSeparator   = "."
+	wildcardSegment  = "*"
+	wildcardSegments = "#"
+)
+
+// MatchTopic report whether the topic match the pattern.
+// Patterns are dot separated, * match exactly one segment and # match any number of segments.
+func MatchTopic(pattern, topic string) bool {
+	return matchSegments(strings.Split(pattern, topicSeparator), strings.Split(topic, topicSeparator))
+}
+
+func matchSegments(pattern, topic []string) bool {
+	for i, segment := range pattern {
+		if segment == wildcardSegments {
+			rest := pattern[i+1:]
+			for j := i; j <= len(topic); j++ {
+				if matchSegments(rest, topic[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if i >= len(topic) || (segment != wildcardSegment && segment != topic[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(topic)
+}
+
+// HasWildcards report whether the topic is a pattern holding * or # segments.
+func HasWildcards(topic string) bool {
+	return strings.ContainsAny(topic, wildcardSegment+wildcardSegments)
+}
+
+// OverlapTopics report whether at least one topic match both patterns.
+func OverlapTopics(a, b string) bool {
+	return overlapSegments(strings.Split(a, topicSeparator), strings.Split(b, topicSeparator))
+}
+
+func overlapSegments(a, b []string) bool {
+	switch {
+	case len(a) > 0 && a[0] == wildcardSegments:
+		return overlapSegments(a[1:], b) || (len(b) > 0 && overlapSegments(a, b[1:]))
+	case len(b) > 0 && b[0] == wildcardSegments:
+		return overlapSegments(a, b[1:]) || (len(a) > 0 && overlapSegments(a[1:], b))
+	case len(a) == 0 || len(b) == 0:
+		return len(a) == len(b)
+	case a[0] != wildcardSegment && b[0] != wildcardSegment && a[0] != b[0]:
+		return false
+	}
+
+	return overlapSegments(a[1:], b[1:])
+}
+
+// CoverTopic report whether every topic matching the sub pattern also match the pattern.
+func CoverTopic(pattern, sub string) bool {
+	return coverSegments(strings.Split(pattern, topicSeparator), strings.Split(sub, topicSeparator))
+}
+
+func coverSegments(pattern, sub []string) bool {
+	switch {
+	case len(pattern) > 0 && pattern[0] == wildcardSegments:
+		return coverSegments(pattern[1:], sub) || (len(sub) > 0 && coverSegments(pattern, sub[1:]))
+	case len(pattern) == 0 || len(sub) == 0:
+		return len(pattern) == len(sub)
+	case sub[0] == wildcardSegments:
+		return false
+	case pattern[0] != wildcardSegment && pattern[0] != sub[0]:
+		return false
+	}
+
+	return coverSegments(pattern[1:], sub[1:])
+}
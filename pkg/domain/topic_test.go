@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTopic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{pattern: "orders", topic: "orders", want: true},
+		{pattern: "orders", topic: "orders.eu", want: false},
+		{pattern: "orders.*", topic: "orders.eu", want: true},
+		{pattern: "orders.*", topic: "orders.eu.paris", want: false},
+		{pattern: "orders.#", topic: "orders", want: true},
+		{pattern: "orders.#", topic: "orders.eu.paris", want: true},
+		{pattern: "#.paris", topic: "orders.eu.paris", want: true},
+		{pattern: "*.eu.*", topic: "orders.eu.paris", want: true},
+		{pattern: "#", topic: "anything.at.all", want: true},
+		{pattern: "orders.*", topic: "invoices.eu", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.topic, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, MatchTopic(tt.pattern, tt.topic))
+		})
+	}
+}
+
+func TestOverlapTopics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{a: "orders.#", b: "#", want: true},
+		{a: "orders.#", b: "*.eu", want: true},
+		{a: "orders.eu", b: "orders.*", want: true},
+		{a: "orders.*", b: "*.eu.paris", want: false},
+		{a: "orders.#", b: "invoices.*", want: false},
+		{a: "#.paris", b: "orders.#", want: true},
+		{a: "orders.eu", b: "orders.us", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, OverlapTopics(tt.a, tt.b))
+			assert.Equal(t, tt.want, OverlapTopics(tt.b, tt.a))
+		})
+	}
+}
+
+func TestCoverTopic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		sub     string
+		want    bool
+	}{
+		{pattern: "orders.#", sub: "orders.*", want: true},
+		{pattern: "orders.#", sub: "orders.eu.#", want: true},
+		{pattern: "orders.*", sub: "orders.#", want: false},
+		{pattern: "#", sub: "*.eu.#", want: true},
+		{pattern: "orders.*", sub: "*.eu", want: false},
+		{pattern: "*.eu", sub: "orders.eu", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.sub, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, CoverTopic(tt.pattern, tt.sub))
+		})
+	}
+}
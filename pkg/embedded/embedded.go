@@ -0,0 +1,208 @@
+// Package embedded run a HopperMQ broker inside a Go program.
+// The clients connect through in-memory pipes, no port is bound, which makes it a good fit for tests.
+//
+//	broker, err := embedded.New()
+//	if err != nil {
+//		return err
+//	}
+//	if err := broker.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer broker.Stop(ctx)
+//
+//	sdk := client.NewClient(client.WithDialer(broker.DialContext))
+package embedded
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/hoppermq/hopper/internal/events"
+	"github.com/hoppermq/hopper/internal/mq/core"
+	"github.com/hoppermq/hopper/internal/mq/transport/inproc"
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+const defaultEventBufferSize = 1024
+
+// ErrAlreadyStarted is returned when starting a broker twice.
+var ErrAlreadyStarted = errors.New("embedded broker already started")
+
+// Broker is an in-process HopperMQ broker.
+type Broker struct {
+	logger    *slog.Logger
+	broker    *core.Broker
+	transport *inproc.InProc
+
+	mu      sync.Mutex
+	started bool
+}
+
+type config struct {
+	logger          *slog.Logger
+	maxFrameSize    int
+	eventBufferSize uint16
+
+	authenticator   domain.Authenticator
+	maxAuthAttempts int
+	authorizer      domain.Authorizer
+	rateLimiter     domain.RateLimiter
+}
+
+// Option configure the embedded broker.
+type Option func(*config) error
+
+// WithLogger inject the logger, the broker log nothing by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+
+		return nil
+	}
+}
+
+// WithMaxFrameSize set the maximum size in bytes of a frame read from a client.
+func WithMaxFrameSize(size int) Option {
+	return func(c *config) error {
+		c.maxFrameSize = size
+
+		return nil
+	}
+}
+
+// WithEventBufferSize set the buffer size of the internal event bus.
+func WithEventBufferSize(size uint16) Option {
+	return func(c *config) error {
+		if size > 0 {
+			c.eventBufferSize = size
+		}
+
+		return nil
+	}
+}
+
+// WithAuthenticator require the clients to authenticate with an Auth frame before being connected.
+// Clients are closed after maxAttempts failed attempts, 0 allow unlimited attempts.
+func WithAuthenticator(authenticator domain.Authenticator, maxAttempts int) Option {
+	return func(c *config) error {
+		c.authenticator = authenticator
+		c.maxAuthAttempts = maxAttempts
+
+		return nil
+	}
+}
+
+// WithAuthorizer check the topic permissions of the clients before subscribing and publishing.
+func WithAuthorizer(authorizer domain.Authorizer) Option {
+	return func(c *config) error {
+		c.authorizer = authorizer
+
+		return nil
+	}
+}
+
+// WithRateLimiter meter the messages published by the clients.
+func WithRateLimiter(rateLimiter domain.RateLimiter) Option {
+	return func(c *config) error {
+		c.rateLimiter = rateLimiter
+
+		return nil
+	}
+}
+
+// New return a new embedded broker, it must be started before dialing it.
+func New(opts ...Option) (*Broker, error) {
+	brokerConfig := &config{
+		logger:          slog.New(slog.DiscardHandler),
+		eventBufferSize: defaultEventBufferSize,
+	}
+	for _, opt := range opts {
+		if err := opt(brokerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	transportOpts := []inproc.Option{inproc.WithLogger(brokerConfig.logger)}
+	if brokerConfig.maxFrameSize > 0 {
+		transportOpts = append(transportOpts, inproc.WithMaxFrameSize(brokerConfig.maxFrameSize))
+	}
+
+	transport, err := inproc.NewInProc(transportOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	eb := events.NewEventBus(brokerConfig.eventBufferSize)
+	transport.RegisterEventBus(eb)
+
+	// the transport is not handed to the broker, Start run it once the broker is subscribed.
+	broker := core.NewBroker(brokerConfig.logger, eb)
+	if brokerConfig.authenticator != nil {
+		broker.RegisterAuthenticator(brokerConfig.authenticator, brokerConfig.maxAuthAttempts)
+	}
+	if brokerConfig.authorizer != nil {
+		broker.RegisterAuthorizer(brokerConfig.authorizer)
+	}
+	if brokerConfig.rateLimiter != nil {
+		broker.RegisterRateLimiter(brokerConfig.rateLimiter)
+	}
+
+	return &Broker{
+		logger:    brokerConfig.logger,
+		broker:    broker,
+		transport: transport,
+	}, nil
+}
+
+// Start start the broker and its in-process transport.
+func (b *Broker) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.started {
+		return ErrAlreadyStarted
+	}
+
+	// the broker subscribe to the events before the transport accept the first client.
+	if err := b.broker.Run(ctx); err != nil {
+		return err
+	}
+	if err := b.transport.Run(ctx); err != nil {
+		return err
+	}
+	b.started = true
+
+	return nil
+}
+
+// Stop close the clients and shut down the broker.
+func (b *Broker) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.started {
+		return nil
+	}
+	b.started = false
+
+	// the broker is stopped first so close frames can still flow through the transport.
+	if err := b.broker.Stop(ctx); err != nil {
+		b.logger.Warn("failed to stop embedded broker", "error", err)
+	}
+
+	return b.transport.Stop(ctx)
+}
+
+// Dial open a connection to the broker.
+func (b *Broker) Dial(ctx context.Context) (net.Conn, error) {
+	return b.transport.Dial(ctx)
+}
+
+// DialContext open a connection to the broker, the network and address are ignored.
+// It matches net.Dialer.DialContext so it can be given to client.WithDialer.
+func (b *Broker) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	return b.transport.Dial(ctx)
+}
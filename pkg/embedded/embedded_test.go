@@ -0,0 +1,141 @@
+package embedded
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/internal/mq/transport/inproc"
+	"github.com/hoppermq/hopper/pkg/client/transport/tcp"
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/domain/mocks"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+	"github.com/hoppermq/hopper/pkg/protocol/serializer"
+)
+
+func startBroker(t *testing.T, opts ...Option) *Broker {
+	t.Helper()
+
+	broker, err := New(opts...)
+	require.NoError(t, err)
+	require.NoError(t, broker.Start(context.Background()))
+	t.Cleanup(func() { _ = broker.Stop(context.Background()) })
+
+	return broker
+}
+
+func TestBroker_Dial(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+	assert.ErrorIs(t, broker.Start(context.Background()), ErrAlreadyStarted)
+
+	conn, err := broker.Dial(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	data, err := protocol.ReadFrame(bufio.NewReader(conn), 0)
+	require.NoError(t, err)
+
+	s := serializer.NewSerializer(common.NewPool(func() *bytes.Buffer {
+		return &bytes.Buffer{}
+	}))
+	frame, err := s.DeserializeFrame(data)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FrameTypeOpen, frame.GetType())
+
+	require.NoError(t, conn.Close())
+	require.NoError(t, broker.Stop(context.Background()))
+	_, err = broker.Dial(context.Background())
+	assert.ErrorIs(t, err, inproc.ErrNotRunning)
+}
+
+func TestBroker_SDK_Client(t *testing.T) {
+	t.Parallel()
+
+	broker := startBroker(t)
+
+	sdk := tcp.NewTCPClient(
+		tcp.WithLogger(slog.New(slog.DiscardHandler)),
+		tcp.WithDialer(broker.DialContext),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, sdk.Run(ctx))
+	assert.True(t, sdk.IsHealthy())
+	require.NoError(t, sdk.Stop(ctx))
+}
+
+func TestBroker_Publish_Before_Connect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     func(t *testing.T) []Option
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "Not_Connected",
+			opts:     func(*testing.T) []Option { return nil },
+			wantCode: domain.ErrorCodeInvalidState,
+		},
+		{
+			name: "Not_Authenticated",
+			opts: func(t *testing.T) []Option {
+				authenticator := mocks.NewMockAuthenticator(t)
+				authenticator.EXPECT().Mechanisms().Return([]domain.AuthMechanism{domain.AuthMechanismPlain}).Maybe()
+				return []Option{WithAuthenticator(authenticator, 3)}
+			},
+			wantCode: domain.ErrorCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			broker := startBroker(t, tt.opts(t)...)
+			conn, err := broker.Dial(context.Background())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			s := serializer.NewSerializer(common.NewPool(func() *bytes.Buffer {
+				return &bytes.Buffer{}
+			}))
+			reader := bufio.NewReader(conn)
+			readFrame := func() domain.Frame {
+				_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				data, err := protocol.ReadFrame(reader, 0)
+				require.NoError(t, err)
+				frame, err := s.DeserializeFrame(data)
+				require.NoError(t, err)
+				return frame
+			}
+			require.Equal(t, domain.FrameTypeOpen, readFrame().GetType())
+
+			message, err := frames.CreateMessageFrame(domain.DOFF4, "orders.created", "message-1", []byte("early"), nil)
+			require.NoError(t, err)
+			data, err := s.SerializeFrame(message)
+			require.NoError(t, err)
+			_, err = conn.Write(data)
+			require.NoError(t, err)
+
+			frame := readFrame()
+			require.Equal(t, domain.FrameTypeError, frame.GetType())
+			payload, ok := frame.GetPayload().(domain.ErrorFramePayload)
+			require.True(t, ok)
+			assert.Equal(t, uint16(tt.wantCode), payload.GetErrorCode())
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package frames
+
+import "github.com/hoppermq/hopper/pkg/domain"
+
+// AuthFramePayload represent the Auth Frame Payload.
+type AuthFramePayload struct {
+	BasePayload
+	Mechanism domain.AuthMechanism
+	Response  []byte
+}
+
+// GetMechanism return the SASL mechanism.
+func (f *AuthFramePayload) GetMechanism() domain.AuthMechanism {
+	return f.Mechanism
+}
+
+// GetResponse return the mechanism response holding the credentials.
+func (f *AuthFramePayload) GetResponse() []byte {
+	return f.Response
+}
+
+// Sizer return the payload size.
+func (f *AuthFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	dataSize := uint16(len(f.Mechanism) + len(f.Response))
+
+	return headerSize + dataSize
+}
+
+// CreateAuthFramePayload creates a new AuthFramePayload instance.
+func CreateAuthFramePayload(
+	header domain.HeaderPayload,
+	mechanism domain.AuthMechanism,
+	response []byte,
+) *AuthFramePayload {
+	return &AuthFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		Mechanism: mechanism,
+		Response:  response,
+	}
+}
@@ -0,0 +1,47 @@
+package frames
+
+import "github.com/hoppermq/hopper/pkg/domain"
+
+// CloseFramePayload represent the Close Frame Payload.
+type CloseFramePayload struct {
+	BasePayload
+	Reason string
+	Code   uint16
+}
+
+// GetReason return the reason of the close.
+func (f *CloseFramePayload) GetReason() string {
+	return f.Reason
+}
+
+// GetCode return the close code.
+func (f *CloseFramePayload) GetCode() uint16 {
+	return f.Code
+}
+
+// Sizer return the payload size.
+func (f *CloseFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	dataSize := uint16(2 + len(f.Reason))
+
+	return headerSize + dataSize
+}
+
+// CreateCloseFramePayload creates a new CloseFramePayload instance.
+func CreateCloseFramePayload(
+	header domain.HeaderPayload,
+	code uint16,
+	reason string,
+) *CloseFramePayload {
+	return &CloseFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		Reason: reason,
+		Code:   code,
+	}
+}
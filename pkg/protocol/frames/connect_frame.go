@@ -0,0 +1,79 @@
+package frames
+
+import "github.com/hoppermq/hopper/pkg/domain"
+
+// ConnectFramePayload represent the Connect Frame Payload.
+type ConnectFramePayload struct {
+	BasePayload
+	SourceID        domain.ID
+	clientVersion   string
+	keepAlive       uint16
+	protocolVersion domain.ProtocolVersion
+	capabilities    domain.Capability
+	incomingWindow  uint32
+}
+
+// CreateConnectFramePayload creates a new ConnectFramePayload instance.
+func CreateConnectFramePayload(
+	header domain.HeaderPayload,
+	sourceID domain.ID,
+	clientVersion string,
+	keepAlive uint16,
+	protocolVersion domain.ProtocolVersion,
+	capabilities domain.Capability,
+	incomingWindow uint32,
+) *ConnectFramePayload {
+	return &ConnectFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		SourceID:        sourceID,
+		clientVersion:   clientVersion,
+		keepAlive:       keepAlive,
+		protocolVersion: protocolVersion,
+		capabilities:    capabilities,
+		incomingWindow:  incomingWindow,
+	}
+}
+
+// Sizer return the payload size.
+func (f *ConnectFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	dataSize := uint16(2 + 2 + 4 + 4 + len(f.SourceID) + len(f.clientVersion))
+
+	return headerSize + dataSize
+}
+
+// GetSourceID return the source ID.
+func (f *ConnectFramePayload) GetSourceID() domain.ID {
+	return f.SourceID
+}
+
+// GetClientVersion return the client version.
+func (f *ConnectFramePayload) GetClientVersion() string {
+	return f.clientVersion
+}
+
+// GetKeepAlive return the channel lt set by the client.
+func (f *ConnectFramePayload) GetKeepAlive() uint16 {
+	return f.keepAlive
+}
+
+// GetProtocolVersion return the protocol version requested by the client.
+func (f *ConnectFramePayload) GetProtocolVersion() domain.ProtocolVersion {
+	return f.protocolVersion
+}
+
+// GetCapabilities return the capabilities requested by the client.
+func (f *ConnectFramePayload) GetCapabilities() domain.Capability {
+	return f.capabilities
+}
+
+// GetIncomingWindow return the number of messages the client accept in flight, zero leave it to the broker.
+func (f *ConnectFramePayload) GetIncomingWindow() uint32 {
+	return f.incomingWindow
+}
@@ -0,0 +1,62 @@
+package frames
+
+import "github.com/hoppermq/hopper/pkg/domain"
+
+// ErrorFramePayload represent the Error Frame Payload.
+type ErrorFramePayload struct {
+	BasePayload
+	Code    uint16
+	Message string
+	Details map[string]string
+}
+
+// GetErrorCode return the error code.
+func (f *ErrorFramePayload) GetErrorCode() uint16 {
+	return f.Code
+}
+
+// GetErrorMessage return the human readable error message.
+func (f *ErrorFramePayload) GetErrorMessage() string {
+	return f.Message
+}
+
+// GetDetails return the error details.
+func (f *ErrorFramePayload) GetDetails() map[string]string {
+	return f.Details
+}
+
+// Sizer return the payload size.
+func (f *ErrorFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	dataSize := uint16(2 + len(f.Message))
+	for k, v := range f.Details {
+		dataSize += uint16(len(k) + len(v))
+	}
+
+	return headerSize + dataSize
+}
+
+// CreateErrorFramePayload creates a new ErrorFramePayload instance.
+func CreateErrorFramePayload(
+	header domain.HeaderPayload,
+	code uint16,
+	message string,
+	details map[string]string,
+) *ErrorFramePayload {
+	if details == nil {
+		details = make(map[string]string)
+	}
+
+	return &ErrorFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
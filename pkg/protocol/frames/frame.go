@@ -2,7 +2,7 @@
 package frames
 
 import (
-	"github.com/hoppermq/hopper/internal/common"
+	"github.com/hoppermq/hopper/pkg/common"
 	"github.com/hoppermq/hopper/pkg/domain"
 )
 
@@ -47,6 +47,14 @@ func validateFrame(header domain.HeaderFrame, payload domain.Payload) error {
 		if _, ok := payload.(domain.OpenFramePayload); !ok {
 			return domain.ErrInvalidPayload
 		}
+	case domain.FrameTypeOpenRcvd:
+		if _, ok := payload.(domain.OpenRcvdFramePayload); !ok {
+			return domain.ErrInvalidPayload
+		}
+	case domain.FrameTypeAuth:
+		if _, ok := payload.(domain.AuthFramePayload); !ok {
+			return domain.ErrInvalidPayload
+		}
 	case domain.FrameTypeMessage:
 		if _, ok := payload.(domain.MessageFramePayload); !ok {
 			return domain.ErrInvalidPayload
@@ -63,6 +71,10 @@ func validateFrame(header domain.HeaderFrame, payload domain.Payload) error {
 		if _, ok := payload.(domain.UnsubscribeFramePayload); !ok {
 			return domain.ErrInvalidPayload
 		}
+	case domain.FrameTypeConfirm:
+		if _, ok := payload.(domain.ConfirmFramePayload); !ok {
+			return domain.ErrInvalidPayload
+		}
 	case domain.FrameTypeClose:
 		if _, ok := payload.(domain.CloseFramePayload); !ok {
 			return domain.ErrInvalidPayload
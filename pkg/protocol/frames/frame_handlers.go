@@ -0,0 +1,255 @@
+package frames
+
+import (
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+// CreateOpenFrame create a new open frame.
+func CreateOpenFrame(
+	doff domain.DOFF,
+	sourceID domain.ID,
+	assignedContainerID domain.ID,
+	supportedVersions []domain.ProtocolVersion,
+	capabilities domain.Capability,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeOpen,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateOpenFramePayload(payloadHeader, sourceID, assignedContainerID, supportedVersions, capabilities)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateOpenRcvdFrame create a new open received frame.
+func CreateOpenRcvdFrame(doff domain.DOFF, sourceID domain.ID) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeOpenRcvd,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateOpenRcvdFramePayload(payloadHeader, sourceID)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateConnectFrame create a new connect frame.
+func CreateConnectFrame(
+	doff domain.DOFF,
+	sourceID domain.ID,
+	clientVersion string,
+	keepAlive uint16,
+	protocolVersion domain.ProtocolVersion,
+	capabilities domain.Capability,
+	incomingWindow uint32,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeConnect,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateConnectFramePayload(
+		payloadHeader,
+		sourceID,
+		clientVersion,
+		keepAlive,
+		protocolVersion,
+		capabilities,
+		incomingWindow,
+	)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateAuthFrame create a new auth frame.
+func CreateAuthFrame(doff domain.DOFF, mechanism domain.AuthMechanism, response []byte) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeAuth,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateAuthFramePayload(payloadHeader, mechanism, response)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateMessageFrame create a new MessageFrame.
+func CreateMessageFrame(
+	doff domain.DOFF,
+	topic string,
+	messageID domain.ID,
+	content []byte,
+	headers map[string]string,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeMessage,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateMessageFramePayload(payloadHeader, topic, messageID, content, headers)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateBeginFrame create a new begin frame.
+func CreateBeginFrame(
+	doff domain.DOFF,
+	sourceID domain.ID,
+	containerID domain.ID,
+	remoteChannel uint16,
+	nextOutgoingID uint32,
+	incomingWindow uint32,
+	outgoingWindow uint32,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeBegin,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateBeginFramePayload(
+		payloadHeader,
+		sourceID,
+		containerID,
+		remoteChannel,
+		nextOutgoingID,
+		incomingWindow,
+		outgoingWindow,
+	)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateCloseFrame create a new close frame.
+func CreateCloseFrame(
+	doff domain.DOFF,
+	code uint16,
+	reason string,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeClose,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateCloseFramePayload(payloadHeader, code, reason)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateSubscribeFrame create a new subscribe frame.
+func CreateSubscribeFrame(
+	doff domain.DOFF,
+	topic string,
+	qos uint8,
+	routingKey string,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeSubscribe,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateSubscribeFramePayload(payloadHeader, topic, qos, routingKey)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateUnsubscribeFrame create a new unsubscribe frame.
+func CreateUnsubscribeFrame(doff domain.DOFF, topic string) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeUnsubscribe,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateUnsubscribeFramePayload(payloadHeader, topic)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateConfirmFrame create a new confirm frame.
+func CreateConfirmFrame(doff domain.DOFF, messageID domain.ID) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeConfirm,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateConfirmFramePayload(payloadHeader, messageID)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CreateErrorFrame create a new error frame.
+func CreateErrorFrame(
+	doff domain.DOFF,
+	code uint16,
+	message string,
+	details map[string]string,
+) (*Frame, error) {
+	headerFrame := Header{
+		Size: 0,
+		DOFF: doff,
+		Type: domain.FrameTypeError,
+	}
+
+	payloadHeader := &PayloadHeader{
+		Size: 0,
+	}
+
+	payload := CreateErrorFramePayload(payloadHeader, code, message, details)
+
+	return CreateFrame(&headerFrame, nil, payload)
+}
+
+// CanHandle return if frame match the frame type ?.
+func (f *Frame) CanHandle(frameType domain.FrameType) bool {
+	return f.GetType() == frameType
+}
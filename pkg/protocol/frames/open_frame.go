@@ -9,8 +9,11 @@ type OpenFramePayload struct {
 	BasePayload
 	SourceID            domain.ID
 	AssignedContainerID domain.ID
+	SupportedVersions   []domain.ProtocolVersion
+	Capabilities        domain.Capability
 }
 
+// OpenRcvdPayload represents the payload acknowledging the reception of an open frame.
 type OpenRcvdPayload struct {
 	BasePayload
 	SourceID domain.ID
@@ -21,6 +24,8 @@ func CreateOpenFramePayload(
 	header domain.HeaderPayload,
 	sourceID domain.ID,
 	assignedContainerID domain.ID,
+	supportedVersions []domain.ProtocolVersion,
+	capabilities domain.Capability,
 ) *OpenFramePayload {
 	return &OpenFramePayload{
 		BasePayload: BasePayload{
@@ -28,6 +33,18 @@ func CreateOpenFramePayload(
 		},
 		SourceID:            sourceID,
 		AssignedContainerID: assignedContainerID,
+		SupportedVersions:   supportedVersions,
+		Capabilities:        capabilities,
+	}
+}
+
+// CreateOpenRcvdFramePayload creates a new OpenRcvdPayload instance.
+func CreateOpenRcvdFramePayload(header domain.HeaderPayload, sourceID domain.ID) *OpenRcvdPayload {
+	return &OpenRcvdPayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		SourceID: sourceID,
 	}
 }
 
@@ -41,6 +58,16 @@ func (ofp *OpenFramePayload) GetAssignedContainerID() domain.ID {
 	return ofp.AssignedContainerID
 }
 
+// GetSupportedVersions returns the protocol versions advertised by the broker.
+func (ofp *OpenFramePayload) GetSupportedVersions() []domain.ProtocolVersion {
+	return ofp.SupportedVersions
+}
+
+// GetCapabilities returns the capabilities advertised by the broker.
+func (ofp *OpenFramePayload) GetCapabilities() domain.Capability {
+	return ofp.Capabilities
+}
+
 // Sizer calculates the total size of the open frame payload.
 func (ofp *OpenFramePayload) Sizer() uint16 {
 	headerSize := uint16(0)
@@ -48,7 +75,7 @@ func (ofp *OpenFramePayload) Sizer() uint16 {
 		headerSize = ofp.Header.Sizer()
 	}
 
-	dataSize := uint16(len(ofp.SourceID) + len(ofp.AssignedContainerID))
+	dataSize := uint16(len(ofp.SourceID) + len(ofp.AssignedContainerID) + 2*len(ofp.SupportedVersions) + 4)
 	return headerSize + dataSize
 }
 
@@ -0,0 +1,117 @@
+package frames
+
+import "github.com/hoppermq/hopper/pkg/domain"
+
+// SubscribeFramePayload represent the Subscribe Frame Payload.
+type SubscribeFramePayload struct {
+	BasePayload
+	Topic      string
+	QoS        uint8
+	RoutingKey string
+}
+
+// GetTopic return the topic pattern to subscribe to.
+func (f *SubscribeFramePayload) GetTopic() string {
+	return f.Topic
+}
+
+// GetQoS return the quality of service requested for the deliveries.
+func (f *SubscribeFramePayload) GetQoS() uint8 {
+	return f.QoS
+}
+
+// GetRoutingKey return the routing key of the subscription.
+func (f *SubscribeFramePayload) GetRoutingKey() string {
+	return f.RoutingKey
+}
+
+// Sizer return the payload size.
+func (f *SubscribeFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	dataSize := uint16(len(f.Topic) + 1 + len(f.RoutingKey))
+
+	return headerSize + dataSize
+}
+
+// CreateSubscribeFramePayload creates a new SubscribeFramePayload instance.
+func CreateSubscribeFramePayload(
+	header domain.HeaderPayload,
+	topic string,
+	qos uint8,
+	routingKey string,
+) *SubscribeFramePayload {
+	return &SubscribeFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		Topic:      topic,
+		QoS:        qos,
+		RoutingKey: routingKey,
+	}
+}
+
+// UnsubscribeFramePayload represent the Unsubscribe Frame Payload.
+type UnsubscribeFramePayload struct {
+	BasePayload
+	Topic string
+}
+
+// GetTopic return the topic pattern to unsubscribe from.
+func (f *UnsubscribeFramePayload) GetTopic() string {
+	return f.Topic
+}
+
+// Sizer return the payload size.
+func (f *UnsubscribeFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	return headerSize + uint16(len(f.Topic))
+}
+
+// CreateUnsubscribeFramePayload creates a new UnsubscribeFramePayload instance.
+func CreateUnsubscribeFramePayload(header domain.HeaderPayload, topic string) *UnsubscribeFramePayload {
+	return &UnsubscribeFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		Topic: topic,
+	}
+}
+
+// ConfirmFramePayload represent the Confirm Frame Payload, sent to the publisher once its message is routed.
+type ConfirmFramePayload struct {
+	BasePayload
+	MessageID domain.ID
+}
+
+// GetMessageID return the ID of the confirmed message.
+func (f *ConfirmFramePayload) GetMessageID() domain.ID {
+	return f.MessageID
+}
+
+// Sizer return the payload size.
+func (f *ConfirmFramePayload) Sizer() uint16 {
+	headerSize := uint16(0)
+	if f.Header != nil {
+		headerSize = f.Header.Sizer()
+	}
+
+	return headerSize + uint16(len(f.MessageID))
+}
+
+// CreateConfirmFramePayload creates a new ConfirmFramePayload instance.
+func CreateConfirmFramePayload(header domain.HeaderPayload, messageID domain.ID) *ConfirmFramePayload {
+	return &ConfirmFramePayload{
+		BasePayload: BasePayload{
+			Header: header,
+		},
+		MessageID: messageID,
+	}
+}
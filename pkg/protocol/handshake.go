@@ -0,0 +1,69 @@
+package protocol
+
+import (
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+// ClientHandshake is the client side of the handshake, the frames answering the broker Open frame
+// until the Begin frame accept the client.
+type ClientHandshake struct {
+	// Acknowledge answer the Open frame with an OpenRcvd frame before the other frames.
+	Acknowledge bool
+	// Mechanism and Response are the credentials of the Auth frame, no Auth frame is sent without mechanism.
+	Mechanism domain.AuthMechanism
+	Response  []byte
+
+	ClientVersion string
+	KeepAlive     uint16
+	Capabilities  domain.Capability
+	// IncomingWindow is the number of messages the client accept in flight, zero leave it to the broker.
+	IncomingWindow uint32
+}
+
+// Answer send the frames answering the Open frame: the OpenRcvd frame when acknowledging,
+// the Auth frame when a mechanism is set, then the Connect frame.
+func (h *ClientHandshake) Answer(open domain.OpenFramePayload, send func(frame domain.Frame) error) error {
+	sourceID := open.GetSourceID()
+
+	if h.Acknowledge {
+		openRcvd, err := frames.CreateOpenRcvdFrame(domain.DOFF4, sourceID)
+		if err != nil {
+			return err
+		}
+		if err := send(openRcvd); err != nil {
+			return err
+		}
+	}
+
+	if h.Mechanism != "" {
+		auth, err := frames.CreateAuthFrame(domain.DOFF4, h.Mechanism, h.Response)
+		if err != nil {
+			return err
+		}
+		if err := send(auth); err != nil {
+			return err
+		}
+	}
+
+	connect, err := frames.CreateConnectFrame(
+		domain.DOFF4,
+		sourceID,
+		h.ClientVersion,
+		h.KeepAlive,
+		domain.ProtocolVersion1,
+		h.Capabilities,
+		h.IncomingWindow,
+	)
+	if err != nil {
+		return err
+	}
+
+	return send(connect)
+}
+
+// Tolerate report whether an Error frame received before the Begin frame leave the handshake going.
+// A broker without authentication refuse the Auth frame and still accept the Connect frame.
+func (h *ClientHandshake) Tolerate(payload domain.ErrorFramePayload) bool {
+	return h.Mechanism != "" && domain.ErrorCode(payload.GetErrorCode()) == domain.ErrorCodeUnsupportedFrame
+}
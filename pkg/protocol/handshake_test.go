@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+func TestClientHandshake(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		handshake     ClientHandshake
+		wantFrames    []domain.FrameType
+		wantTolerated bool
+	}{
+		{
+			name:       "Connect",
+			handshake:  ClientHandshake{ClientVersion: "test/1"},
+			wantFrames: []domain.FrameType{domain.FrameTypeConnect},
+		},
+		{
+			name: "Acknowledge_And_Authenticate",
+			handshake: ClientHandshake{
+				Acknowledge:    true,
+				Mechanism:      domain.AuthMechanismPlain,
+				Response:       []byte("\x00alice\x00secret"),
+				ClientVersion:  "test/1",
+				IncomingWindow: 64,
+			},
+			wantFrames:    []domain.FrameType{domain.FrameTypeOpenRcvd, domain.FrameTypeAuth, domain.FrameTypeConnect},
+			wantTolerated: true,
+		},
+	}
+
+	open, err := frames.CreateOpenFrame(domain.DOFF4, "client-1", "container-1", []domain.ProtocolVersion{domain.ProtocolVersion1}, 0)
+	require.NoError(t, err)
+	unsupported, err := frames.CreateErrorFrame(domain.DOFF4, uint16(domain.ErrorCodeUnsupportedFrame), "auth disabled", nil)
+	require.NoError(t, err)
+	unauthorized, err := frames.CreateErrorFrame(domain.DOFF4, uint16(domain.ErrorCodeUnauthorized), "rejected", nil)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent []domain.Frame
+			err := tt.handshake.Answer(open.GetPayload().(domain.OpenFramePayload), func(frame domain.Frame) error {
+				sent = append(sent, frame)
+				return nil
+			})
+			require.NoError(t, err)
+
+			types := make([]domain.FrameType, 0, len(sent))
+			for _, frame := range sent {
+				types = append(types, frame.GetType())
+			}
+			assert.Equal(t, tt.wantFrames, types)
+
+			connect, ok := sent[len(sent)-1].GetPayload().(domain.ConnectFramePayload)
+			require.True(t, ok)
+			assert.Equal(t, domain.ID("client-1"), connect.GetSourceID())
+			assert.Equal(t, tt.handshake.IncomingWindow, connect.GetIncomingWindow())
+
+			assert.Equal(t, tt.wantTolerated, tt.handshake.Tolerate(unsupported.GetPayload().(domain.ErrorFramePayload)))
+			assert.False(t, tt.handshake.Tolerate(unauthorized.GetPayload().(domain.ErrorFramePayload)))
+		})
+	}
+}
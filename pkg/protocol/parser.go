@@ -0,0 +1,68 @@
+// Package protocol implement the framing of the HopperMQ wire protocol, shared by the broker and the client SDK.
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+)
+
+const (
+	// FrameHeaderSize is the size in bytes of an encoded frame header (size, doff, type).
+	FrameHeaderSize = 6
+
+	// ExtendedSizeLength is the size in bytes of the extended size following the header of a large frame.
+	ExtendedSizeLength = 4
+
+	// LargeFrameMarker is the header size value announcing an extended size, see domain.CapabilityLargeFrames.
+	LargeFrameMarker = math.MaxUint16
+
+	// MaxStandardFrameSize is the largest frame that can be sent without the large frames capability.
+	MaxStandardFrameSize = FrameHeaderSize + LargeFrameMarker - 1
+
+	// DefaultMaxFrameSize is the default upper bound of a frame read by a transport, large frames included.
+	DefaultMaxFrameSize = 1 << 20
+)
+
+// ReadFrame read a single encoded frame from the stream.
+// The header size field hold the number of bytes following the frame header,
+// or LargeFrameMarker when the size is carried by the extended size field.
+// Frames larger than maxSize are discarded from the stream and domain.ErrFrameTooLarge is returned,
+// leaving the reader positioned on the next frame.
+func ReadFrame(r io.Reader, maxSize int) ([]byte, error) {
+	header := make([]byte, FrameHeaderSize, FrameHeaderSize+ExtendedSizeLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint16(header[0:2]))
+	if size == LargeFrameMarker {
+		header = header[:FrameHeaderSize+ExtendedSizeLength]
+		if _, err := io.ReadFull(r, header[FrameHeaderSize:]); err != nil {
+			return nil, err
+		}
+		size = int(binary.BigEndian.Uint32(header[FrameHeaderSize:]))
+	}
+
+	if maxSize > 0 && len(header)+size > maxSize {
+		if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrFrameTooLarge
+	}
+
+	frame := make([]byte, len(header)+size)
+	copy(frame, header)
+	if _, err := io.ReadFull(r, frame[len(header):]); err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// IsLargeFrame return true when the encoded frame use the extended size field.
+func IsLargeFrame(frame []byte) bool {
+	return len(frame) >= FrameHeaderSize && binary.BigEndian.Uint16(frame[0:2]) == LargeFrameMarker
+}
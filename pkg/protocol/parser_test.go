@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeFrame(payload string) []byte {
+	frame := make([]byte, FrameHeaderSize, FrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint16(frame[0:2], uint16(len(payload)))
+	binary.BigEndian.PutUint16(frame[2:4], uint16(domain.DOFF4))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(domain.FrameTypeMessage))
+	return append(frame, payload...)
+}
+
+func encodeLargeFrame(payload string) []byte {
+	frame := make([]byte, FrameHeaderSize+ExtendedSizeLength, FrameHeaderSize+ExtendedSizeLength+len(payload))
+	binary.BigEndian.PutUint16(frame[0:2], LargeFrameMarker)
+	binary.BigEndian.PutUint16(frame[2:4], uint16(domain.DOFF4))
+	binary.BigEndian.PutUint16(frame[4:6], uint16(domain.FrameTypeMessage))
+	binary.BigEndian.PutUint32(frame[6:10], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func TestReadFrame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		stream  []byte
+		maxSize int
+		want    [][]byte
+		wantErr []error
+	}{
+		{
+			name:    "ReadFrame_Consecutive_Frames",
+			stream:  append(encodeFrame("first"), encodeFrame("second")...),
+			maxSize: DefaultMaxFrameSize,
+			want:    [][]byte{encodeFrame("first"), encodeFrame("second")},
+			wantErr: []error{nil, nil},
+		},
+		{
+			name:    "ReadFrame_Too_Large_Frame_Is_Skipped",
+			stream:  append(encodeFrame("way too large"), encodeFrame("ok")...),
+			maxSize: FrameHeaderSize + 4,
+			want:    [][]byte{nil, encodeFrame("ok")},
+			wantErr: []error{domain.ErrFrameTooLarge, nil},
+		},
+		{
+			name:    "ReadFrame_Large_Frame",
+			stream:  encodeLargeFrame(strings.Repeat("x", LargeFrameMarker+1)),
+			maxSize: DefaultMaxFrameSize,
+			want:    [][]byte{encodeLargeFrame(strings.Repeat("x", LargeFrameMarker+1))},
+			wantErr: []error{nil},
+		},
+		{
+			name:    "ReadFrame_Truncated_Frame",
+			stream:  encodeFrame("truncated")[:FrameHeaderSize+3],
+			maxSize: DefaultMaxFrameSize,
+			want:    [][]byte{nil},
+			wantErr: []error{io.ErrUnexpectedEOF},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.stream)
+			for i := range tt.want {
+				got, err := ReadFrame(r, tt.maxSize)
+				if tt.wantErr[i] != nil {
+					assert.ErrorIs(t, err, tt.wantErr[i])
+					continue
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want[i], got)
+			}
+		})
+	}
+}
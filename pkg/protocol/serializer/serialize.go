@@ -0,0 +1,690 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+// Serializer represent the protocol serializer.
+type Serializer struct {
+	bufferPool domain.Pool[*bytes.Buffer] // since it's a global Serializer we will keep it as any atm.
+	mu         sync.RWMutex
+}
+
+func (ps *Serializer) writeUint16(b *bytes.Buffer, u16 uint16) error {
+	return binary.Write(b, binary.BigEndian, u16)
+}
+
+func (ps *Serializer) writeUint32(b *bytes.Buffer, u32 uint32) error {
+	return binary.Write(b, binary.BigEndian, u32)
+}
+
+func (ps *Serializer) writeByteArray(b *bytes.Buffer, d []byte) error {
+	if err := ps.writeUint32(b, uint32(len(d))); err != nil {
+		return err
+	}
+	_, err := b.Write(d)
+	return err
+}
+
+func (ps *Serializer) writeString(b *bytes.Buffer, str string) error {
+	if err := ps.writeUint32(b, uint32(len(str))); err != nil {
+		return err
+	}
+
+	_, err := b.WriteString(str)
+	return err
+}
+
+func (ps *Serializer) writeID(b *bytes.Buffer, ID domain.ID) error {
+	if err := ps.writeUint32(b, uint32(len(ID))); err != nil {
+		return err
+	}
+
+	_, err := b.WriteString(string(ID))
+	return err
+}
+
+func (ps *Serializer) writeFrameHeader(buff *bytes.Buffer, fh domain.HeaderFrame) error {
+	if err := ps.writeUint16(buff, fh.GetSize()); err != nil {
+		return err
+	}
+	if err := ps.writeUint16(buff, uint16(fh.GetDOFF())); err != nil {
+		return err
+	}
+	return ps.writeUint16(buff, uint16(fh.GetFrameType()))
+}
+
+func (ps *Serializer) writePayloadHeader(buff *bytes.Buffer, ph domain.HeaderPayload) error {
+	return ps.writeUint16(buff, ph.Sizer())
+}
+
+func (ps *Serializer) writePayload(buff *bytes.Buffer, frame domain.Frame) error {
+	if err := ps.writePayloadHeader(buff, frame.GetPayload().GetHeader()); err != nil {
+		return err
+	}
+
+	switch frame.GetType() {
+	case domain.FrameTypeOpen:
+		if openPayload, ok := frame.GetPayload().(domain.OpenFramePayload); ok {
+			return ps.writeOpenPayload(buff, openPayload)
+		}
+	case domain.FrameTypeMessage:
+		if msgPayload, ok := frame.GetPayload().(domain.MessageFramePayload); ok {
+			return ps.writeMessagePayload(buff, msgPayload)
+		}
+	case domain.FrameTypeClose:
+		if closePayload, ok := frame.GetPayload().(domain.CloseFramePayload); ok {
+			return ps.writeClosePayload(buff, closePayload)
+		}
+	case domain.FrameTypeError:
+		if errPayload, ok := frame.GetPayload().(domain.ErrorFramePayload); ok {
+			return ps.writeErrorPayload(buff, errPayload)
+		}
+	case domain.FrameTypeOpenRcvd:
+		if openRcvdPayload, ok := frame.GetPayload().(domain.OpenRcvdFramePayload); ok {
+			return ps.writeID(buff, openRcvdPayload.GetSourceID())
+		}
+	case domain.FrameTypeConnect:
+		if connectPayload, ok := frame.GetPayload().(domain.ConnectFramePayload); ok {
+			return ps.writeConnectPayload(buff, connectPayload)
+		}
+	case domain.FrameTypeAuth:
+		if authPayload, ok := frame.GetPayload().(domain.AuthFramePayload); ok {
+			return ps.writeAuthPayload(buff, authPayload)
+		}
+	case domain.FrameTypeBegin:
+		if beginPayload, ok := frame.GetPayload().(domain.BeginFramePayload); ok {
+			return ps.writeBeginPayload(buff, beginPayload)
+		}
+	case domain.FrameTypeSubscribe:
+		if subscribePayload, ok := frame.GetPayload().(domain.SubscribeFramePayload); ok {
+			return ps.writeSubscribePayload(buff, subscribePayload)
+		}
+	case domain.FrameTypeUnsubscribe:
+		if unsubscribePayload, ok := frame.GetPayload().(domain.UnsubscribeFramePayload); ok {
+			return ps.writeString(buff, unsubscribePayload.GetTopic())
+		}
+	case domain.FrameTypeConfirm:
+		if confirmPayload, ok := frame.GetPayload().(domain.ConfirmFramePayload); ok {
+			return ps.writeID(buff, confirmPayload.GetMessageID())
+		}
+	default:
+		return domain.ErrUnsupportedFrameType
+	}
+	return domain.ErrInvalidPayload
+}
+
+func (ps *Serializer) writeOpenPayload(buff *bytes.Buffer, payload domain.OpenFramePayload) error {
+	if err := ps.writeID(buff, payload.GetSourceID()); err != nil {
+		return err
+	}
+	if err := ps.writeID(buff, payload.GetAssignedContainerID()); err != nil {
+		return err
+	}
+
+	versions := payload.GetSupportedVersions()
+	if err := ps.writeUint16(buff, uint16(len(versions))); err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := ps.writeUint16(buff, uint16(version)); err != nil {
+			return err
+		}
+	}
+	return ps.writeUint32(buff, uint32(payload.GetCapabilities()))
+}
+
+func (ps *Serializer) writeMessagePayload(buff *bytes.Buffer, payload domain.MessageFramePayload) error {
+	if err := ps.writeString(buff, payload.GetTopic()); err != nil {
+		return err
+	}
+	if err := ps.writeID(buff, payload.GetMessageID()); err != nil {
+		return err
+	}
+	if err := ps.writeByteArray(buff, payload.GetContent()); err != nil {
+		return err
+	}
+
+	if err := ps.writeUint32(buff, uint32(len(payload.GetHeaders()))); err != nil {
+		return err
+	}
+	for k, v := range payload.GetHeaders() {
+		if err := ps.writeString(buff, k); err != nil {
+			return err
+		}
+		if err := ps.writeString(buff, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *Serializer) writeClosePayload(buff *bytes.Buffer, payload domain.CloseFramePayload) error {
+	if err := ps.writeUint16(buff, payload.GetCode()); err != nil {
+		return err
+	}
+	return ps.writeString(buff, payload.GetReason())
+}
+
+func (ps *Serializer) writeErrorPayload(buff *bytes.Buffer, payload domain.ErrorFramePayload) error {
+	if err := ps.writeUint16(buff, payload.GetErrorCode()); err != nil {
+		return err
+	}
+	if err := ps.writeString(buff, payload.GetErrorMessage()); err != nil {
+		return err
+	}
+	return ps.writeStringMap(buff, payload.GetDetails())
+}
+
+func (ps *Serializer) writeStringMap(buff *bytes.Buffer, m map[string]string) error {
+	if err := ps.writeUint32(buff, uint32(len(m))); err != nil {
+		return err
+	}
+	for k, v := range m {
+		if err := ps.writeString(buff, k); err != nil {
+			return err
+		}
+		if err := ps.writeString(buff, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *Serializer) writeConnectPayload(buff *bytes.Buffer, payload domain.ConnectFramePayload) error {
+	if err := ps.writeID(buff, payload.GetSourceID()); err != nil {
+		return err
+	}
+	if err := ps.writeString(buff, payload.GetClientVersion()); err != nil {
+		return err
+	}
+	if err := ps.writeUint16(buff, payload.GetKeepAlive()); err != nil {
+		return err
+	}
+	if err := ps.writeUint16(buff, uint16(payload.GetProtocolVersion())); err != nil {
+		return err
+	}
+	if err := ps.writeUint32(buff, uint32(payload.GetCapabilities())); err != nil {
+		return err
+	}
+	return ps.writeUint32(buff, payload.GetIncomingWindow())
+}
+
+func (ps *Serializer) writeAuthPayload(buff *bytes.Buffer, payload domain.AuthFramePayload) error {
+	if err := ps.writeString(buff, string(payload.GetMechanism())); err != nil {
+		return err
+	}
+	return ps.writeByteArray(buff, payload.GetResponse())
+}
+
+func (ps *Serializer) writeBeginPayload(buff *bytes.Buffer, payload domain.BeginFramePayload) error {
+	if err := ps.writeID(buff, payload.GetSourceID()); err != nil {
+		return err
+	}
+	if err := ps.writeID(buff, payload.GetContainerID()); err != nil {
+		return err
+	}
+	if err := ps.writeUint16(buff, payload.GetRemoteChannel()); err != nil {
+		return err
+	}
+	if err := ps.writeUint32(buff, payload.GetNextOutgoingID()); err != nil {
+		return err
+	}
+	if err := ps.writeUint32(buff, payload.GetIncomingWindow()); err != nil {
+		return err
+	}
+	return ps.writeUint32(buff, payload.GetOutgoingWindow())
+}
+
+func (ps *Serializer) writeSubscribePayload(buff *bytes.Buffer, payload domain.SubscribeFramePayload) error {
+	if err := ps.writeString(buff, payload.GetTopic()); err != nil {
+		return err
+	}
+	if err := buff.WriteByte(payload.GetQoS()); err != nil {
+		return err
+	}
+	return ps.writeString(buff, payload.GetRoutingKey())
+}
+
+// SerializeFrame serialize the given frame.
+// Frames whose size does not fit the header size field are encoded as large frames,
+// peers must have negotiated domain.CapabilityLargeFrames to exchange them.
+func (ps *Serializer) SerializeFrame(frame domain.Frame) ([]byte, error) {
+	buff := ps.bufferPool.Get()
+	defer ps.bufferPool.Put(buff)
+	buff.Reset()
+
+	if err := ps.writeFrameHeader(buff, frame.GetHeader()); err != nil {
+		return nil, err
+	}
+
+	if err := ps.writePayload(buff, frame); err != nil {
+		return nil, err
+	}
+
+	size := buff.Len() - protocol.FrameHeaderSize
+	if size < protocol.LargeFrameMarker {
+		res := make([]byte, buff.Len())
+		copy(res, buff.Bytes())
+		binary.BigEndian.PutUint16(res[0:2], uint16(size))
+
+		return res, nil
+	}
+
+	if size > math.MaxUint32 {
+		return nil, domain.ErrFrameTooLarge
+	}
+
+	res := make([]byte, buff.Len()+protocol.ExtendedSizeLength)
+	copy(res, buff.Bytes()[:protocol.FrameHeaderSize])
+	binary.BigEndian.PutUint16(res[0:2], protocol.LargeFrameMarker)
+	binary.BigEndian.PutUint32(res[protocol.FrameHeaderSize:], uint32(size))
+	copy(res[protocol.FrameHeaderSize+protocol.ExtendedSizeLength:], buff.Bytes()[protocol.FrameHeaderSize:])
+
+	return res, nil
+}
+
+// DeserializeFrame deserialize the given bytes to frame.
+func (ps *Serializer) DeserializeFrame(d []byte) (domain.Frame, error) {
+	r := bytes.NewReader(d)
+
+	var size, doff, frameType uint16
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &doff); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &frameType); err != nil {
+		return nil, err
+	}
+
+	if size == protocol.LargeFrameMarker {
+		if _, err := ps.readUint32(r); err != nil {
+			return nil, err
+		}
+	}
+
+	header := &frames.Header{
+		Size: size,
+		DOFF: domain.DOFF(doff),
+		Type: domain.FrameType(frameType),
+	}
+
+	var payloadHeaderSize uint16
+	if err := binary.Read(r, binary.BigEndian, &payloadHeaderSize); err != nil {
+		return nil, err
+	}
+
+	payloadHeader := &frames.PayloadHeader{Size: payloadHeaderSize}
+
+	var payload domain.Payload
+	var err error
+
+	switch domain.FrameType(frameType) {
+	case domain.FrameTypeOpen:
+		payload, err = ps.deserializeOpenPayload(r, payloadHeader)
+	case domain.FrameTypeMessage:
+		payload, err = ps.deserializeMessagePayload(r, payloadHeader)
+	case domain.FrameTypeClose:
+		payload, err = ps.deserializeClosePayload(r, payloadHeader)
+	case domain.FrameTypeError:
+		payload, err = ps.deserializeErrorPayload(r, payloadHeader)
+	case domain.FrameTypeOpenRcvd:
+		payload, err = ps.deserializeOpenRcvdPayload(r, payloadHeader)
+	case domain.FrameTypeConnect:
+		payload, err = ps.deserializeConnectPayload(r, payloadHeader)
+	case domain.FrameTypeAuth:
+		payload, err = ps.deserializeAuthPayload(r, payloadHeader)
+	case domain.FrameTypeBegin:
+		payload, err = ps.deserializeBeginPayload(r, payloadHeader)
+	case domain.FrameTypeSubscribe:
+		payload, err = ps.deserializeSubscribePayload(r, payloadHeader)
+	case domain.FrameTypeUnsubscribe:
+		payload, err = ps.deserializeUnsubscribePayload(r, payloadHeader)
+	case domain.FrameTypeConfirm:
+		payload, err = ps.deserializeConfirmPayload(r, payloadHeader)
+	default:
+		return nil, domain.ErrUnsupportedFrameType
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateFrame(header, nil, payload)
+}
+
+func (ps *Serializer) deserializeOpenPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.OpenFramePayload, error) {
+	sourceID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	assignedContainerID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	versionCount, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]domain.ProtocolVersion, 0, versionCount)
+	for i := uint16(0); i < versionCount; i++ {
+		version, err := ps.readUint16(r)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, domain.ProtocolVersion(version))
+	}
+
+	capabilities, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateOpenFramePayload(
+		header,
+		sourceID,
+		assignedContainerID,
+		versions,
+		domain.Capability(capabilities),
+	), nil
+}
+
+func (ps *Serializer) deserializeOpenRcvdPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.OpenRcvdPayload, error) {
+	sourceID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateOpenRcvdFramePayload(header, sourceID), nil
+}
+
+func (ps *Serializer) deserializeMessagePayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.MessageFramePayload, error) {
+	topic, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ps.readByteArray(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read headers map
+	var headerCount uint32
+	if err := binary.Read(r, binary.BigEndian, &headerCount); err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, headerCount)
+	for i := uint32(0); i < headerCount; i++ {
+		key, err := ps.readString(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := ps.readString(r)
+		if err != nil {
+			return nil, err
+		}
+		headers[key] = value
+	}
+
+	return frames.CreateMessageFramePayload(header, topic, messageID, content, headers), nil
+}
+
+func (ps *Serializer) deserializeClosePayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.CloseFramePayload, error) {
+	code, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reason, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateCloseFramePayload(header, code, reason), nil
+}
+
+func (ps *Serializer) deserializeErrorPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.ErrorFramePayload, error) {
+	code, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := ps.readStringMap(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateErrorFramePayload(header, code, message, details), nil
+}
+
+func (ps *Serializer) readStringMap(r *bytes.Reader) (map[string]string, error) {
+	count, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		key, err := ps.readString(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := ps.readString(r)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+
+	return m, nil
+}
+
+func (ps *Serializer) deserializeConnectPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.ConnectFramePayload, error) {
+	sourceID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	clientVersion, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keepAlive, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingWindow, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateConnectFramePayload(
+		header,
+		sourceID,
+		clientVersion,
+		keepAlive,
+		domain.ProtocolVersion(version),
+		domain.Capability(capabilities),
+		incomingWindow,
+	), nil
+}
+
+func (ps *Serializer) deserializeAuthPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.AuthFramePayload, error) {
+	mechanism, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := ps.readByteArray(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateAuthFramePayload(header, domain.AuthMechanism(mechanism), response), nil
+}
+
+func (ps *Serializer) deserializeBeginPayload(r *bytes.Reader, header *frames.PayloadHeader) (*frames.BeginFramePayload, error) {
+	sourceID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	containerID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteChannel, err := ps.readUint16(r)
+	if err != nil {
+		return nil, err
+	}
+
+	nextOutgoingID, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingWindow, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	outgoingWindow, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateBeginFramePayload(
+		header,
+		sourceID,
+		containerID,
+		remoteChannel,
+		nextOutgoingID,
+		incomingWindow,
+		outgoingWindow,
+	), nil
+}
+
+func (ps *Serializer) deserializeSubscribePayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.SubscribeFramePayload, error) {
+	topic, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	qos, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	routingKey, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateSubscribeFramePayload(header, topic, qos, routingKey), nil
+}
+
+func (ps *Serializer) deserializeUnsubscribePayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.UnsubscribeFramePayload, error) {
+	topic, err := ps.readString(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateUnsubscribeFramePayload(header, topic), nil
+}
+
+func (ps *Serializer) deserializeConfirmPayload(r *bytes.Reader, header domain.HeaderPayload) (*frames.ConfirmFramePayload, error) {
+	messageID, err := ps.readID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames.CreateConfirmFramePayload(header, messageID), nil
+}
+
+func (ps *Serializer) readUint16(r *bytes.Reader) (uint16, error) {
+	var val uint16
+	err := binary.Read(r, binary.BigEndian, &val)
+	return val, err
+}
+
+func (ps *Serializer) readUint32(r *bytes.Reader) (uint32, error) {
+	var val uint32
+	err := binary.Read(r, binary.BigEndian, &val)
+	return val, err
+}
+
+func (ps *Serializer) readByteArray(r *bytes.Reader) ([]byte, error) {
+	length, err := ps.readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(length) > int64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	return data, err
+}
+
+func (ps *Serializer) readString(r *bytes.Reader) (string, error) {
+	data, err := ps.readByteArray(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (ps *Serializer) readID(r *bytes.Reader) (domain.ID, error) {
+	str, err := ps.readString(r)
+	if err != nil {
+		return "", err
+	}
+	return domain.ID(str), nil
+}
+
+// NewSerializer return a new instance of serializer.
+func NewSerializer(pool domain.Pool[*bytes.Buffer]) *Serializer {
+	return &Serializer{
+		bufferPool: pool,
+	}
+}
@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hoppermq/hopper/pkg/common"
+	"github.com/hoppermq/hopper/pkg/domain"
+	"github.com/hoppermq/hopper/pkg/protocol/frames"
+)
+
+func TestSerializer_SubscribeFrames(t *testing.T) {
+	t.Parallel()
+
+	ps := NewSerializer(common.NewPool(func() *bytes.Buffer {
+		return &bytes.Buffer{}
+	}))
+
+	subscribe, err := frames.CreateSubscribeFrame(domain.DOFF4, "sensors.*.temperature", 1, "key")
+	require.NoError(t, err)
+	unsubscribe, err := frames.CreateUnsubscribeFrame(domain.DOFF4, "sensors.#")
+	require.NoError(t, err)
+
+	data, err := ps.SerializeFrame(subscribe)
+	require.NoError(t, err)
+	frame, err := ps.DeserializeFrame(data)
+	require.NoError(t, err)
+
+	subscribePayload, ok := frame.GetPayload().(domain.SubscribeFramePayload)
+	require.True(t, ok)
+	assert.Equal(t, "sensors.*.temperature", subscribePayload.GetTopic())
+	assert.Equal(t, uint8(1), subscribePayload.GetQoS())
+	assert.Equal(t, "key", subscribePayload.GetRoutingKey())
+
+	// an empty string ending the payload must not be read as a truncated frame.
+	subscribe, err = frames.CreateSubscribeFrame(domain.DOFF4, "sensors.#", 0, "")
+	require.NoError(t, err)
+	data, err = ps.SerializeFrame(subscribe)
+	require.NoError(t, err)
+	frame, err = ps.DeserializeFrame(data)
+	require.NoError(t, err)
+	assert.Equal(t, "", frame.GetPayload().(domain.SubscribeFramePayload).GetRoutingKey())
+
+	data, err = ps.SerializeFrame(unsubscribe)
+	require.NoError(t, err)
+	frame, err = ps.DeserializeFrame(data)
+	require.NoError(t, err)
+
+	unsubscribePayload, ok := frame.GetPayload().(domain.UnsubscribeFramePayload)
+	require.True(t, ok)
+	assert.Equal(t, "sensors.#", unsubscribePayload.GetTopic())
+}
+
+func TestSerializer_ConfirmFrame(t *testing.T) {
+	t.Parallel()
+
+	ps := NewSerializer(common.NewPool(func() *bytes.Buffer {
+		return &bytes.Buffer{}
+	}))
+
+	confirm, err := frames.CreateConfirmFrame(domain.DOFF4, "msg-1")
+	require.NoError(t, err)
+
+	data, err := ps.SerializeFrame(confirm)
+	require.NoError(t, err)
+	frame, err := ps.DeserializeFrame(data)
+	require.NoError(t, err)
+
+	confirmPayload, ok := frame.GetPayload().(domain.ConfirmFramePayload)
+	require.True(t, ok)
+	assert.Equal(t, domain.FrameTypeConfirm, frame.GetType())
+	assert.Equal(t, domain.ID("msg-1"), confirmPayload.GetMessageID())
+}